@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
@@ -121,11 +123,7 @@ func parseStyleOption(styleOption string) (*chroma.Style, error) {
 
 func parseColorsOption(colorsOption string) (twin.ColorCount, error) {
 	if strings.ToLower(colorsOption) == "auto" {
-		colorsOption = "16M"
-		if os.Getenv("COLORTERM") != "truecolor" && strings.Contains(os.Getenv("TERM"), "256") {
-			// Covers "xterm-256color" as used by the macOS Terminal
-			colorsOption = "256"
-		}
+		return twin.DetectColorCount(), nil
 	}
 
 	switch strings.ToUpper(colorsOption) {
@@ -164,8 +162,99 @@ func parseUnprintableStyle(styleOption string) (textstyles.UnprintableStyleT, er
 	if styleOption == "whitespace" {
 		return textstyles.UnprintableStyleWhitespace, nil
 	}
+	if styleOption == "caret" {
+		return textstyles.UnprintableStyleCaret, nil
+	}
+	if styleOption == "symbols" {
+		return textstyles.UnprintableStyleSymbols, nil
+	}
+
+	return 0, fmt.Errorf("Good ones are highlight, whitespace, caret or symbols")
+}
+
+func parseCarriageReturnStyle(styleOption string) (textstyles.CarriageReturnStyleT, error) {
+	if styleOption == "overwrite" {
+		return textstyles.CarriageReturnStyleOverwrite, nil
+	}
+	if styleOption == "raw" {
+		return textstyles.CarriageReturnStyleRaw, nil
+	}
+
+	return 0, fmt.Errorf("Good ones are overwrite or raw")
+}
+
+func parseSearchFeedback(feedbackOption string) (internal.SearchFeedback, error) {
+	switch feedbackOption {
+	case "none":
+		return internal.SearchFeedbackNone, nil
+	case "bell":
+		return internal.SearchFeedbackBell, nil
+	case "flash":
+		return internal.SearchFeedbackFlash, nil
+	}
+
+	return 0, fmt.Errorf("Good ones are none, bell or flash")
+}
+
+func parseAtEnd(atEndOption string) (internal.AtEnd, error) {
+	switch atEndOption {
+	case "stay":
+		return internal.AtEndStay, nil
+	case "exit":
+		return internal.AtEndExit, nil
+	case "prompt":
+		return internal.AtEndPrompt, nil
+	}
+
+	return 0, fmt.Errorf("Good ones are stay, exit or prompt")
+}
+
+func parseColumnDelimiter(delimiter string) (string, error) {
+	switch delimiter {
+	case "", "whitespace":
+		return "", nil
+	case "tab":
+		return "\t", nil
+	}
+
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return "", fmt.Errorf("Expected whitespace, tab or a single character like ',', got <%s>", delimiter)
+	}
+	return delimiter, nil
+}
+
+func parseSearchWrap(wrapOption string) (internal.SearchWrap, error) {
+	switch wrapOption {
+	case "auto-wrap":
+		return internal.SearchWrapAuto, nil
+	case "no-wrap":
+		return internal.SearchWrapNever, nil
+	case "confirm-wrap":
+		return internal.SearchWrapConfirm, nil
+	}
+
+	return 0, fmt.Errorf("Good ones are auto-wrap, no-wrap or confirm-wrap")
+}
+
+func parseExitCode(exitCode string) (int, error) {
+	value, err := strconv.ParseUint(exitCode, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("Must be a number between 0 and 255")
+	}
 
-	return 0, fmt.Errorf("Good ones are highlight or whitespace")
+	return int(value), nil
+}
+
+func parseNotFoundTimeout(timeout string) (time.Duration, error) {
+	value, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("Must not be negative")
+	}
+
+	return value, nil
 }
 
 func parseScrollHint(scrollHint string) (textstyles.CellWithMetadata, error) {
@@ -179,6 +268,47 @@ func parseScrollHint(scrollHint string) (textstyles.CellWithMetadata, error) {
 	return textstyles.CellWithMetadata{}, fmt.Errorf("Expected exactly one (optionally highlighted) character. For example: 'ESC[2m…'")
 }
 
+func parseSearchHitStyle(styleOption string) (*twin.Style, error) {
+	styleOption = strings.ReplaceAll(styleOption, "ESC", "\x1b")
+
+	style, err := internal.TermcapToStyle(styleOption)
+	if err != nil {
+		return nil, fmt.Errorf("Expected an ANSI style with no text, for example 'ESC[44m': %w", err)
+	}
+
+	return &style, nil
+}
+
+func parseSearchJumpOffset(offset string) (*int, error) {
+	value, err := strconv.ParseUint(offset, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := int(value)
+	return &rows, nil
+}
+
+// Parse a percentage between 0 and 100, with or without a trailing %
+func parseAutoWrapPercent(percent string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimSuffix(percent, "%"))
+	if err != nil || value < 0 || value > 100 {
+		return 0, fmt.Errorf("Must be a percentage between 0 and 100")
+	}
+
+	return value, nil
+}
+
+// Parse a non-negative number of rows
+func parseRowCount(rows string) (uint, error) {
+	value, err := strconv.ParseUint(rows, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(value), nil
+}
+
 func parseShiftAmount(shiftAmount string) (uint, error) {
 	value, err := strconv.ParseUint(shiftAmount, 10, 32)
 	if err != nil {
@@ -209,6 +339,32 @@ func parseTabAmount(tabAmount string) (uint, error) {
 	return uint(value), nil
 }
 
+func parseScrollStep(scrollStep string) (uint, error) {
+	value, err := strconv.ParseUint(scrollStep, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < 1 {
+		return 0, fmt.Errorf("Scroll step must be at least 1")
+	}
+
+	return uint(value), nil
+}
+
+func parseWheelLines(wheelLines string) (uint, error) {
+	value, err := strconv.ParseUint(wheelLines, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < 1 {
+		return 0, fmt.Errorf("Wheel lines must be at least 1")
+	}
+
+	return uint(value), nil
+}
+
 func parseMouseMode(mouseMode string) (twin.MouseMode, error) {
 	switch mouseMode {
 	case "auto":
@@ -245,7 +401,13 @@ func pumpToStdout(inputFilenames ...string) error {
 				continue
 			}
 
-			inputFile, _, err := reader.ZOpen(inputFilename)
+			var inputFile io.ReadCloser
+			var err error
+			if reader.IsUrl(inputFilename) {
+				inputFile, err = reader.OpenUrl(context.Background(), inputFilename)
+			} else {
+				inputFile, _, err = reader.ZOpen(inputFilename)
+			}
 			if err != nil {
 				return fmt.Errorf("Failed to open %s: %w", inputFilename, err)
 			}
@@ -267,6 +429,30 @@ func pumpToStdout(inputFilenames ...string) error {
 	return nil
 }
 
+// Parses an argument like "+G" or "+/pattern" anywhere on the command line,
+// and returns the remaining args.
+//
+// Returns false and "" if there is no such argument.
+func getStartAtEndOrSearch(args []string) (bool, string, []string) {
+	for i, arg := range args {
+		atEnd := arg == "+G"
+		search := ""
+		if strings.HasPrefix(arg, "+/") {
+			search = arg[2:]
+		}
+		if !atEnd && search == "" {
+			continue
+		}
+
+		remainingArgs := make([]string, 0)
+		remainingArgs = append(remainingArgs, args[:i]...)
+		remainingArgs = append(remainingArgs, args[i+1:]...)
+		return atEnd, search, remainingArgs
+	}
+
+	return false, "", args
+}
+
 // Parses an argument like "+123" anywhere on the command line into a one-based
 // line number, and returns the remaining args.
 //
@@ -393,7 +579,19 @@ func pagerFromArgs(
 	trace := flagSet.Bool("trace", false, "Print trace logs after exiting")
 
 	wrap := flagSet.Bool("wrap", false, "Wrap long lines")
+	autoWrap := flagSetFunc(flagSet, "auto-wrap", 0,
+		"Wrap long lines if at least this many `percent` of them are wider than the screen", parseAutoWrapPercent)
+	squeezeBlankLines := flagSet.Bool("squeeze-blank-lines", false, "Show runs of blank lines as one blank line, toggle with 's'")
+	scrollbar := flagSet.Bool("scrollbar", false, "Show where in the input you are, and where the search hits are, in the rightmost column")
+	ruler := flagSet.Bool("ruler", false, "Show a column ruler at the top of the screen, toggle with 'r'")
+	showEscapes := flagSet.Bool("show-escapes", false, "Show escape codes as text like 'cat -v', toggle with 'E'")
+	showWhitespace := flagSet.Bool("show-whitespace", false, "Show tabs as '→' and trailing spaces as '·'")
+	colorDiffs := flagSet.Bool("color-diffs", false, "Color added and removed lines in uncolored diffs")
+	highlightUrls := flagSet.Bool("highlight-urls", false, "Underline http:// and https:// URLs. Open them with 'o' either way.")
+	highlightCurrentLine := flagSet.Bool("highlight-current-line", false, "Highlight the topmost line on screen")
 	follow := flagSet.Bool("follow", false, "Follow piped input just like \"tail -f\"")
+	maxLines := flagSet.Uint("lines", 0, "Read at most `count` lines. With --follow, keep the last ones like \"tail -n\".")
+	flagSet.UintVar(maxLines, "n", 0, "Same as --lines `count`")
 	styleOption := flagSetFunc(flagSet,
 		"style", nil,
 		"Highlighting `style` from https://xyproto.github.io/splash/docs/longer/all.html", parseStyleOption)
@@ -401,6 +599,10 @@ func pagerFromArgs(
 		"lang", nil,
 		"File contents, used for highlighting. Mime type or file extension (\"html\"). Default is to guess by filename.", parseLexerOption)
 	terminalFg := flagSet.Bool("terminal-fg", false, "Use terminal foreground color rather than style foreground for plain text")
+	noHighlight := flagSet.Bool("no-highlight", false, "Do not syntax highlight the input, even if its file type is known")
+	columnDelimiter := flagSetFunc(flagSet, "column-delimiter", "",
+		"What separates the columns highlighted with '[' and ']': whitespace, tab or a character like ','", parseColumnDelimiter)
+	dimNonMatching := flagSet.Bool("dim-non-matching", false, "While searching, dim lines without search hits, toggle with 'D'")
 	noSearchLineHighlight := flagSet.Bool("no-search-line-highlight", false, "Do not highlight the background of lines with search hits")
 
 	defaultFormatter, err := parseColorsOption("auto")
@@ -411,25 +613,70 @@ func pagerFromArgs(
 		"colors", defaultFormatter, "Highlighting palette size: 8, 16, 256, 16M, auto", parseColorsOption)
 
 	noLineNumbers := flagSet.Bool("no-linenumbers", noLineNumbersDefault(), "Hide line numbers on startup, press left arrow key to show")
+	relativeLineNumbers := flagSet.Bool("relative-line-numbers", false, "Show line numbers relative to the top line, switch with '#'")
+	timeDeltas := flagSet.Bool("time-deltas", false, "For lines starting with a timestamp, show the time since the previous one")
+	timestampFormat := flagSet.String("timestamp-format", "",
+		"Go time `layout` of the timestamps for --time-deltas, like '2006-01-02 15:04:05'. Defaults to a few common ones.")
 	noStatusBar := flagSet.Bool("no-statusbar", false, "Hide the status bar, toggle with '='")
 	reFormat := flagSet.Bool("reformat", false, "Reformat some input files (JSON)")
 	flagSet.Bool("no-reformat", true, "No effect, kept for compatibility. See --reformat")
 	quitIfOneScreen := flagSet.Bool("quit-if-one-screen", false, "Don't page if contents fits on one screen. Affected by --no-clear-on-exit-margin.")
+	atEnd := flagSetFunc(flagSet, "at-end", internal.AtEndStay,
+		"Scrolling down at the end: stay, exit or prompt", parseAtEnd)
+	pattern := flagSet.String("pattern", "", "Start at the first line matching `pattern`, then press 'n' for the next match. Same as +/pattern.")
+	flagSet.StringVar(pattern, "p", "", "Same as --pattern `pattern`")
+	searchPreview := flagSet.Bool("search-preview", false, "Show the current search hit in context while searching, toggle with CTRL-p")
+	concat := flagSet.Bool("concat", false, "Show all files as one, like \"cat file1 file2 | moor\"")
+	concatSeparators := flagSet.Bool("concat-separators", false, "With --concat, show each file's name on a line before its contents")
+	rememberPositions := flagSet.Bool("remember-positions", false, "Reopen files where you were when you last quit")
+	confirmQuit := flagSet.Bool("confirm-quit", false, "Require pressing 'q' twice to quit")
 	noClearOnExit := flagSet.Bool("no-clear-on-exit", false, "Retain screen contents when exiting moor")
 	noClearOnExitMargin := flagSet.Int("no-clear-on-exit-margin", 1,
 		"Number of lines to leave for your shell prompt, defaults to 1")
 	statusBarStyle := flagSetFunc(flagSet, "statusbar", internal.STATUSBAR_STYLE_INVERSE,
 		"Status bar `style`: inverse, plain or bold", parseStatusBarStyle)
+	statusBarFormat := flagSet.String("statusbar-format", "",
+		"Status bar `format`: %f file name, %l first line, %L line count, %p percent, %e END, TOP or FOLLOWING, %m mode, %c column, %w wrap or chop")
 	unprintableStyle := flagSetFunc(flagSet, "render-unprintable", textstyles.UnprintableStyleHighlight,
-		"How unprintable characters are rendered: highlight or whitespace", parseUnprintableStyle)
+		"How unprintable characters are rendered: highlight, whitespace, caret or symbols", parseUnprintableStyle)
+	carriageReturnStyle := flagSetFunc(flagSet, "carriage-returns", textstyles.CarriageReturnStyleOverwrite,
+		"How carriage returns inside lines are rendered: overwrite or raw", parseCarriageReturnStyle)
+	searchFeedback := flagSetFunc(flagSet, "search-feedback", internal.SearchFeedbackNone,
+		"What to do when a search finds nothing or wraps: none, bell or flash", parseSearchFeedback)
+	searchWrap := flagSetFunc(flagSet, "search-wrap", internal.SearchWrapAuto,
+		"Searching again at the end: auto-wrap, no-wrap or confirm-wrap", parseSearchWrap)
+	notFoundTimeout := flagSetFunc(flagSet, "not-found-timeout", time.Duration(0),
+		"How long to show \"Not found\" after a failed search, like '2s'. Default is until the next key press.", parseNotFoundTimeout)
+	notFoundExitCode := flagSetFunc(flagSet, "not-found-exit-code", 0,
+		"Exit with this `code` if the +/pattern or --pattern search found nothing", parseExitCode)
 	scrollLeftHint := flagSetFunc(flagSet, "scroll-left-hint",
 		textstyles.CellWithMetadata{Rune: '<', Style: twin.StyleDefault.WithAttr(twin.AttrReverse)},
 		"Shown when view can scroll left. One character with optional ANSI highlighting.", parseScrollHint)
 	scrollRightHint := flagSetFunc(flagSet, "scroll-right-hint",
 		textstyles.CellWithMetadata{Rune: '>', Style: twin.StyleDefault.WithAttr(twin.AttrReverse)},
 		"Shown when view can scroll right. One character with optional ANSI highlighting.", parseScrollHint)
+	searchHitStyle := flagSetFunc(flagSet, "search-hit-style", nil,
+		"Search hit `style` as an ANSI sequence, like 'ESC[44m'. Only setting a background color keeps the hit colors.", parseSearchHitStyle)
+	searchJumpOffset := flagSetFunc(flagSet, "search-jump-offset", nil,
+		"Put search hits this many `rows` from the top of the screen. Default is to center them.", parseSearchJumpOffset)
+	scrollOff := flagSetFunc(flagSet, "scroll-off", 0,
+		"Keep this many `rows` of context around search hits and lines jumped to", parseRowCount)
+	header := flagSetFunc(flagSet, "header", 0,
+		"Keep this many `lines` from the start of the input at the top of the screen", parseRowCount)
+	pageOverlap := flagSetFunc(flagSet, "page-overlap", 1,
+		"Keep this many `rows` of the previous page when scrolling a full page, defaults to 1", parseRowCount)
+	smoothScroll := flagSet.Bool("smooth-scroll", false, "Animate page scrolls rather than jumping")
+	formFeedPages := flagSet.Bool("form-feed-pages", false, "Stop page scrolls at form feeds, like a printer would")
 	shift := flagSetFunc(flagSet, "shift", 16, "Horizontal scroll `amount` >=1, defaults to 16", parseShiftAmount)
+	scrollStep := flagSetFunc(flagSet, "scroll-step", 1, "Up / down arrow keys scroll `amount` >=1, defaults to 1", parseScrollStep)
+	wheelLines := flagSetFunc(flagSet, "wheel-lines", 1, "Mouse wheel scroll `amount` >=1, defaults to 1", parseWheelLines)
 	tabSize := flagSetFunc(flagSet, "tab-size", 8, "Number of spaces per tab stop, defaults to 8", parseTabAmount)
+	statusFile := flagSet.String("status-file", "",
+		"Keep writing the current position to this `file` or named pipe, for tmux status bars and the like")
+	keyBindingsFile := flagSet.String("keybindings", "",
+		"Key bindings `file`, defaults to moor/keys in your XDG config directory")
+	themeFile := flagSet.String("theme", "",
+		"UI colors `file`, defaults to moor/theme in your XDG config directory")
 	mouseMode := flagSetFunc(
 		flagSet,
 		"mousemode",
@@ -448,6 +695,7 @@ func pagerFromArgs(
 	}
 
 	targetLine, remainingArgs := getTargetLine(flags)
+	startAtEnd, initialSearch, remainingArgs := getStartAtEndOrSearch(remainingArgs)
 
 	err = flagSet.Parse(remainingArgs)
 
@@ -457,6 +705,16 @@ func pagerFromArgs(
 		}
 	}
 
+	var keyBindings internal.KeyBindings
+	if err == nil {
+		keyBindings, err = internal.LoadKeyBindings(*keyBindingsFile)
+	}
+
+	var theme internal.Theme
+	if err == nil {
+		theme, err = internal.LoadTheme(*themeFile)
+	}
+
 	if err != nil {
 		if err == flag.ErrHelp {
 			printUsage(flagSet, *terminalColorsCount)
@@ -503,6 +761,16 @@ func pagerFromArgs(
 		flagSetArgs = []string{"-"}
 	}
 
+	concatenate := *concat && len(flagSetArgs) > 1
+	if concatenate {
+		for _, inputFilename := range flagSetArgs {
+			if inputFilename == "-" || reader.IsUrl(inputFilename) {
+				err := fmt.Errorf("--concat only works with files, not with %s", inputFilename)
+				return nil, nil, chroma.Style{}, nil, logsRequested, err
+			}
+		}
+	}
+
 	// Check that any input files can be opened
 	for _, inputFilename := range flagSetArgs {
 		if stdinIsRedirected && inputFilename == "-" {
@@ -510,6 +778,11 @@ func pagerFromArgs(
 			continue
 		}
 
+		if reader.IsUrl(inputFilename) {
+			// Download problems will be shown in the pager
+			continue
+		}
+
 		// Need to check before newScreen() below, otherwise the screen
 		// will be cleared before we print the "No such file" error.
 		err := reader.TryOpen(inputFilename)
@@ -553,7 +826,13 @@ func pagerFromArgs(
 
 	var readerImpls []*reader.ReaderImpl
 	shouldFormat := *reFormat
-	readerOptions := reader.ReaderOptions{Lexer: *lexer, ShouldFormat: shouldFormat}
+	readerOptions := reader.ReaderOptions{
+		Lexer:          *lexer,
+		ShouldFormat:   shouldFormat,
+		NoHighlighting: *noHighlight,
+		MaxLines:       int(*maxLines),
+		KeepLastLines:  *follow,
+	}
 
 	stdinName := ""
 	if os.Getenv("PAGER_LABEL") != "" {
@@ -563,6 +842,17 @@ func pagerFromArgs(
 		stdinName = os.Getenv("MAN_PN")
 	}
 
+	if concatenate {
+		readerImpl, err := reader.NewFromFilenames(flagSetArgs, *concatSeparators, formatter, readerOptions)
+		if err != nil {
+			return nil, nil, chroma.Style{}, nil, logsRequested, err
+		}
+		readerImpls = append(readerImpls, readerImpl)
+
+		// All files are in that one reader
+		flagSetArgs = nil
+	}
+
 	// Display the input file(s) contents
 	stdinDone := false
 	for _, inputFilename := range flagSetArgs {
@@ -585,6 +875,8 @@ func pagerFromArgs(
 			readerImpl.AwaitFirstByte()
 
 			stdinDone = true
+		} else if reader.IsUrl(inputFilename) {
+			readerImpl = reader.NewFromUrl(inputFilename, formatter, readerOptions)
 		} else {
 			readerImpl, err = reader.NewFromFilename(inputFilename, formatter, readerOptions)
 		}
@@ -622,21 +914,64 @@ func pagerFromArgs(
 
 	pager := internal.NewPager(readerImpls...)
 	pager.WrapLongLines = *wrap
+	pager.AutoWrapPercent = *autoWrap
+	pager.SqueezeBlankLines = *squeezeBlankLines
+	pager.HighlightCurrentLine = *highlightCurrentLine
+	pager.ShowWhitespace = *showWhitespace
+	pager.ShowEscapes = *showEscapes
+	pager.ShowRuler = *ruler
+	pager.ShowScrollbar = *scrollbar
+	pager.ColorDiffs = *colorDiffs
+	pager.HighlightUrls = *highlightUrls
 	pager.ShowLineNumbers = !*noLineNumbers
+	pager.RelativeLineNumbers = *relativeLineNumbers
+	pager.ShowTimeDeltas = *timeDeltas
+	if *timestampFormat != "" {
+		pager.TimestampFormats = []string{*timestampFormat}
+	}
 	pager.ShowStatusBar = !*noStatusBar
 	pager.DeInit = !*noClearOnExit
 	pager.DeInitFalseMargin = *noClearOnExitMargin
 	pager.QuitIfOneScreen = *quitIfOneScreen
+	pager.AtEnd = *atEnd
+	pager.ConfirmQuit = *confirmQuit
+	pager.RememberPositions = *rememberPositions
 	pager.StatusBarStyle = *statusBarStyle
+	pager.StatusBarFormat = *statusBarFormat
 	pager.UnprintableStyle = *unprintableStyle
+	pager.CarriageReturnStyle = *carriageReturnStyle
+	pager.SearchFeedback = *searchFeedback
+	pager.SearchWrap = *searchWrap
+	pager.NotFoundTimeout = *notFoundTimeout
+	pager.NotFoundExitCode = *notFoundExitCode
+	pager.SearchPreview = *searchPreview
 	pager.WithTerminalFg = *terminalFg
 	pager.ScrollLeftHint = *scrollLeftHint
 	pager.ScrollRightHint = *scrollRightHint
+	pager.ScrollOff = int(*scrollOff)
+	pager.HeaderLines = int(*header)
+	pager.PageOverlap = int(*pageOverlap)
+	pager.SmoothScroll = *smoothScroll
+	pager.FormFeedPages = *formFeedPages
 	pager.SideScrollAmount = int(*shift)
+	pager.ScrollStepLines = int(*scrollStep)
+	pager.WheelScrollAmount = int(*wheelLines)
 	pager.TabSize = int(*tabSize)
 	pager.WithSearchHitLineBackground = !*noSearchLineHighlight
+	pager.DimNonMatchingLines = *dimNonMatching
+	pager.ColumnDelimiter = *columnDelimiter
+	pager.SearchHitStyle = *searchHitStyle
+	pager.SearchJumpOffset = *searchJumpOffset
+	pager.KeyBindings = keyBindings
+	pager.Theme = theme
+	pager.StatusFile = *statusFile
 
 	pager.TargetLine = targetLine
+	pager.StartAtEnd = startAtEnd
+	pager.InitialSearch = initialSearch
+	if pager.InitialSearch == "" {
+		pager.InitialSearch = *pattern
+	}
 	if *follow && pager.TargetLine == nil {
 		reallyHigh := linemetadata.IndexMax()
 		pager.TargetLine = &reallyHigh
@@ -651,12 +986,15 @@ func main() {
 	log.SetOutput(&loglines)
 	russiaNotSupported()
 
+	// See Pager.ExitCode()
+	exitCode := 0
+
 	defer func() {
 		err := recover()
 		haveLogsToShow := len(loglines.String()) > 0 && logsRequested
 		if err == nil && !haveLogsToShow {
 			// No problems
-			return
+			os.Exit(exitCode)
 		}
 
 		printProblemsHeader()
@@ -675,7 +1013,7 @@ func main() {
 		}
 
 		// We were asked to print logs, and we did. Success!
-		os.Exit(0)
+		os.Exit(exitCode)
 	}()
 
 	stdinIsRedirected := !term.IsTerminal(int(os.Stdin.Fd()))
@@ -699,6 +1037,7 @@ func main() {
 	}
 
 	startPaging(pager, screen, &style, formatter)
+	exitCode = pager.ExitCode()
 }
 
 // Define a generic flag with specified name, default value, and usage string.
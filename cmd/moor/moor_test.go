@@ -2,6 +2,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"github.com/walles/moor/v2/internal/linemetadata"
 	"github.com/walles/moor/v2/internal/textstyles"
@@ -52,3 +53,85 @@ func TestGetTargetLine(t *testing.T) {
 	assert.Equal(t, *index, linemetadata.IndexFromOneBased(1))
 	assert.DeepEqual(t, remaining, []string{})
 }
+
+func TestGetStartAtEndOrSearch(t *testing.T) {
+	atEnd, search, remaining := getStartAtEndOrSearch([]string{"file.txt"})
+	assert.Assert(t, !atEnd)
+	assert.Equal(t, search, "")
+	assert.DeepEqual(t, remaining, []string{"file.txt"})
+
+	atEnd, search, remaining = getStartAtEndOrSearch([]string{"+G", "file.txt"})
+	assert.Assert(t, atEnd)
+	assert.Equal(t, search, "")
+	assert.DeepEqual(t, remaining, []string{"file.txt"})
+
+	atEnd, search, remaining = getStartAtEndOrSearch([]string{"file.txt", "+/some thing"})
+	assert.Assert(t, !atEnd)
+	assert.Equal(t, search, "some thing")
+	assert.DeepEqual(t, remaining, []string{"file.txt"})
+
+	// Nothing to search for, pretend this is a file name
+	atEnd, search, remaining = getStartAtEndOrSearch([]string{"+/"})
+	assert.Assert(t, !atEnd)
+	assert.Equal(t, search, "")
+	assert.DeepEqual(t, remaining, []string{"+/"})
+}
+
+func TestPatternFlag(t *testing.T) {
+	for _, flag := range []string{"-p", "--pattern"} {
+		pager, _, _, _, _, err := pagerFromArgs(
+			[]string{"", flag, "some thing", "moor_test.go"},
+			func(_ twin.MouseMode, _ twin.ColorCount) (twin.Screen, error) {
+				return twin.NewFakeScreen(80, 24), nil
+			},
+			false, // stdin is redirected
+			false, // stdout is redirected
+		)
+
+		assert.NilError(t, err)
+		assert.Equal(t, "some thing", pager.InitialSearch, flag)
+	}
+}
+
+func TestParseColumnDelimiter(t *testing.T) {
+	for input, expected := range map[string]string{"": "", "whitespace": "", "tab": "\t", ",": ",", "│": "│"} {
+		delimiter, err := parseColumnDelimiter(input)
+		assert.NilError(t, err, input)
+		assert.Equal(t, expected, delimiter, input)
+	}
+
+	_, err := parseColumnDelimiter("::")
+	assert.ErrorContains(t, err, "got <::>")
+}
+
+func TestParseNotFoundTimeout(t *testing.T) {
+	timeout, err := parseNotFoundTimeout("1500ms")
+	assert.NilError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, timeout)
+
+	_, err = parseNotFoundTimeout("-1s")
+	assert.ErrorContains(t, err, "negative")
+}
+
+func TestParseExitCode(t *testing.T) {
+	exitCode, err := parseExitCode("1")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, exitCode)
+
+	_, err = parseExitCode("256")
+	assert.ErrorContains(t, err, "between 0 and 255")
+
+	_, err = parseExitCode("-1")
+	assert.ErrorContains(t, err, "between 0 and 255")
+}
+
+func TestParseAutoWrapPercent(t *testing.T) {
+	for input, expected := range map[string]int{"0": 0, "30": 30, "30%": 30, "100": 100} {
+		percent, err := parseAutoWrapPercent(input)
+		assert.NilError(t, err, input)
+		assert.Equal(t, expected, percent, input)
+	}
+
+	_, err := parseAutoWrapPercent("101")
+	assert.ErrorContains(t, err, "between 0 and 100")
+}
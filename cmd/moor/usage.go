@@ -196,6 +196,8 @@ func printUsage(flagSet *flag.FlagSet, colors twin.ColorCount) {
 	envSection += renderPlainEnvVar("TERM")
 	envSection += renderPlainEnvVar("TERM_PROGRAM")
 	envSection += renderPlainEnvVar("COLORTERM")
+	envSection += renderPlainEnvVar("NO_COLOR")
+	envSection += renderPlainEnvVar("FORCE_COLOR")
 
 	// Requested here: https://github.com/walles/moor/issues/170#issuecomment-1891154661
 	envSection += renderPlainEnvVar("MANROFFOPT")
@@ -216,6 +218,10 @@ func printUsage(flagSet *flag.FlagSet, colors twin.ColorCount) {
 
 	fmt.Println("  +1234")
 	fmt.Println("    \tImmediately scroll to line 1234")
+	fmt.Println("  +G")
+	fmt.Println("    \tStart at the end of the input")
+	fmt.Println("  +/pattern")
+	fmt.Println("    \tStart at the first line matching pattern")
 }
 
 // If $PAGER isn't pointing to us, print a help text on how to set it.
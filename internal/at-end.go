@@ -0,0 +1,45 @@
+package internal
+
+import log "github.com/sirupsen/logrus"
+
+// What to do when the user scrolls down while already at the end of the input
+type AtEnd int
+
+const (
+	// Stay at the end, like less does
+	AtEndStay AtEnd = iota
+
+	// Exit the pager, for use in scripts and pipelines
+	AtEndExit
+
+	// Tell the user they are at the end, and how to quit
+	AtEndPrompt
+)
+
+// Call before scrolling down. Returns true if we were already at the end and
+// AtEnd has handled that, in which case there is no need to scroll.
+//
+// We only do this after reading is done, more lines might show up otherwise.
+func (p *Pager) handleAtEnd() bool {
+	if p.AtEnd == AtEndStay || p.isShowingHelp {
+		return false
+	}
+
+	p.readerLock.Lock()
+	r := p.readers[p.currentReader]
+	p.readerLock.Unlock()
+
+	if !r.ReadingDone.Load() || !p.isScrolledToEnd() {
+		return false
+	}
+
+	switch p.AtEnd {
+	case AtEndExit:
+		log.Info("Exiting because of --at-end=exit, user scrolled down at the end of the input")
+		p.Quit()
+	case AtEndPrompt:
+		p.setMessage("(END) Press 'q' to quit")
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestAtEndStay(t *testing.T) {
+	pager := createLinesPager(t, 30, 4, "1", "2", "3", "4", "5")
+	pager.AtEnd = AtEndStay
+	pager.scrollToEnd()
+
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyPgDown)
+	pager.handleMouseEvent(twin.NewEventMouse(twin.MouseWheelDown, 0, 0))
+
+	assert.Assert(t, !pager.quit)
+	assert.Equal(t, "3", screenRows(pager)[0])
+	assert.Assert(t, !isInfoMode(pager))
+}
+
+func TestAtEndExit(t *testing.T) {
+	pager := createLinesPager(t, 30, 4, "1", "2", "3", "4", "5")
+	pager.AtEnd = AtEndExit
+	pager.scrollToEnd()
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Assert(t, pager.quit)
+}
+
+func TestAtEndExitPageDown(t *testing.T) {
+	pager := createLinesPager(t, 30, 4, "1", "2", "3", "4", "5")
+	pager.AtEnd = AtEndExit
+	pager.scrollToEnd()
+
+	pager.mode.onKey(twin.KeyPgDown)
+	assert.Assert(t, pager.quit)
+}
+
+func TestAtEndExitMouseWheel(t *testing.T) {
+	pager := createLinesPager(t, 30, 4, "1", "2", "3", "4", "5")
+	pager.AtEnd = AtEndExit
+	pager.scrollToEnd()
+
+	pager.handleMouseEvent(twin.NewEventMouse(twin.MouseWheelDown, 0, 0))
+	assert.Assert(t, pager.quit)
+}
+
+// Scrolling up or down before the end should work as usual
+func TestAtEndExitNotAtEnd(t *testing.T) {
+	pager := createLinesPager(t, 30, 4, "1", "2", "3", "4", "5")
+	pager.AtEnd = AtEndExit
+	pager.scrollToEnd()
+	assert.Equal(t, "3", screenRows(pager)[0])
+
+	pager.mode.onKey(twin.KeyUp)
+	assert.Equal(t, "2", screenRows(pager)[0])
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "3", screenRows(pager)[0])
+	assert.Assert(t, !pager.quit)
+}
+
+func TestAtEndPrompt(t *testing.T) {
+	pager := createLinesPager(t, 30, 4, "1", "2", "3", "4", "5")
+	pager.AtEnd = AtEndPrompt
+	pager.scrollToEnd()
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Assert(t, !pager.quit)
+	assert.Equal(t, "(END) Press 'q' to quit", screenRows(pager)[3])
+
+	// Any other key goes back to viewing
+	pager.mode.onKey(twin.KeyUp)
+	assert.Assert(t, !isInfoMode(pager))
+	assert.Equal(t, "2", screenRows(pager)[0])
+
+	// Prompting again, then quitting
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "(END) Press 'q' to quit", screenRows(pager)[3])
+	pager.mode.onRune('q')
+	assert.Assert(t, pager.quit)
+}
+
+func isInfoMode(pager *Pager) bool {
+	_, isInfo := pager.mode.(*PagerModeInfo)
+	return isInfo
+}
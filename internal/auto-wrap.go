@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"math"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+)
+
+// With AutoWrapPercent set, turn on wrapping if at least that many percent of
+// the lines are too wide for the screen. Tables and other narrow content keep
+// getting chopped.
+//
+// This is done once, when the input has first been read.
+func (p *Pager) applyAutoWrap(r *reader.ReaderImpl) {
+	if p.AutoWrapPercent <= 0 || p.autoWrapDone || !r.ReadingDone.Load() {
+		return
+	}
+	p.autoWrapDone = true
+
+	if p.WrapLongLines {
+		// Already wrapping, nothing to decide
+		return
+	}
+
+	lines := r.GetLines(linemetadata.Index{}, math.MaxInt).Lines
+	if len(lines) == 0 {
+		return
+	}
+
+	width := p.contentWidth()
+	wideCount := 0
+	for _, line := range lines {
+		if line.DisplayWidth() > width {
+			wideCount++
+		}
+	}
+
+	if wideCount*100 < p.AutoWrapPercent*len(lines) {
+		log.Debugf("Not auto wrapping, %d/%d lines are wider than %d columns", wideCount, len(lines), width)
+		return
+	}
+
+	log.Infof("Auto wrapping, %d/%d lines are wider than %d columns", wideCount, len(lines), width)
+	p.WrapLongLines = true
+}
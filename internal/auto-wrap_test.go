@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAutoWrapMostlyWide(t *testing.T) {
+	wide := strings.Repeat("x", 30)
+	pager := createLinesPager(t, 20, 5, wide, wide, wide, "narrow")
+	pager.AutoWrapPercent = 50
+
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, pager.WrapLongLines)
+}
+
+func TestAutoWrapMostlyNarrow(t *testing.T) {
+	wide := strings.Repeat("x", 30)
+	pager := createLinesPager(t, 20, 5, "a  b  c", wide, "d  e  f", "g  h  i")
+	pager.AutoWrapPercent = 50
+
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, !pager.WrapLongLines)
+
+	// A lower threshold should make the one wide line enough
+	pager = createLinesPager(t, 20, 5, "a  b  c", wide, "d  e  f", "g  h  i")
+	pager.AutoWrapPercent = 25
+
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, pager.WrapLongLines)
+}
+
+func TestAutoWrapOnlyOnce(t *testing.T) {
+	wide := strings.Repeat("x", 30)
+	pager := createLinesPager(t, 20, 5, wide, wide)
+	pager.AutoWrapPercent = 50
+
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, pager.WrapLongLines)
+
+	// The user turns wrapping off, that should stick
+	pager.mode.onRune('w')
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, !pager.WrapLongLines)
+}
+
+func TestAutoWrapOff(t *testing.T) {
+	wide := strings.Repeat("x", 30)
+	pager := createLinesPager(t, 20, 5, wide, wide)
+
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, !pager.WrapLongLines, "Auto wrapping should be off by default")
+}
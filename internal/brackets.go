@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+const openingBrackets = "([{"
+const closingBrackets = ")]}"
+
+// The first bracket on the line, and its position in the line's runes. Returns
+// -1 if there are no brackets on the line.
+func firstBracket(runes []rune) (int, rune) {
+	for i, char := range runes {
+		if strings.ContainsRune(openingBrackets+closingBrackets, char) {
+			return i, char
+		}
+	}
+
+	return -1, 0
+}
+
+// Starting next to the bracket at column on the from line, find the line with
+// the matching bracket. Brackets of other kinds are ignored, like in vi.
+// Returns nil if there is no match.
+func (p *Pager) findMatchingBracket(from linemetadata.Index, column int, bracket rune) *linemetadata.Index {
+	var other rune
+	direction := SearchDirectionForward
+	if i := strings.IndexRune(openingBrackets, bracket); i >= 0 {
+		other = rune(closingBrackets[i])
+	} else {
+		other = rune(openingBrackets[strings.IndexRune(closingBrackets, bracket)])
+		direction = SearchDirectionBackward
+	}
+
+	step := 1
+	if direction == SearchDirectionBackward {
+		step = -1
+	}
+
+	depth := 0
+	lineCount := p.Reader().GetLineCount()
+	for index := from.Index(); index >= 0 && index < lineCount; index += step {
+		lineIndex := linemetadata.IndexFromZeroBased(index)
+		line := p.Reader().GetLine(lineIndex)
+		if line == nil {
+			return nil
+		}
+
+		runes := []rune(line.Plain())
+		i := 0
+		if step < 0 {
+			i = len(runes) - 1
+		}
+		if index == from.Index() {
+			i = column
+		}
+
+		for ; i >= 0 && i < len(runes); i += step {
+			switch runes[i] {
+			case bracket:
+				depth++
+			case other:
+				depth--
+			}
+
+			if depth == 0 {
+				return &lineIndex
+			}
+		}
+	}
+
+	return nil
+}
+
+// Find the first bracket on the top line, and put the line with the matching
+// bracket at the top of the screen. Tell the user if there is no such thing.
+func (p *Pager) scrollToMatchingBracket() {
+	current := p.lineIndex()
+	if current == nil {
+		// No lines
+		return
+	}
+
+	line := p.Reader().GetLine(*current)
+	if line == nil {
+		return
+	}
+
+	column, bracket := firstBracket([]rune(line.Plain()))
+	if column < 0 {
+		p.setMessage("No bracket on the top line")
+		return
+	}
+
+	match := p.findMatchingBracket(*current, column, bracket)
+	if match == nil {
+		p.setMessage("No matching bracket found for " + string(bracket))
+		return
+	}
+
+	if *match == *current {
+		// Already there
+		return
+	}
+
+	p.rememberPosition()
+	p.scrollPosition = NewScrollPositionFromIndex(*match, "scrollToMatchingBracket")
+	if match.Index() > current.Index() {
+		p.handleScrolledDown()
+	} else {
+		p.handleScrolledUp()
+	}
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var bracketLines = []string{
+	"{",              // 0
+	`  "a": [1, 2],`, // 1
+	`  "b": {`,       // 2
+	`    "c": (`,     // 3
+	`      "]"`,      // 4, other kinds of brackets don't count
+	`    ),`,         // 5
+	`    "d": [`,     // 6
+	`    ]`,          // 7
+	`  }`,            // 8
+	"}",              // 9
+	"text",           // 10
+	"(",              // 11
+	"more",           // 12
+	"",               // 13
+}
+
+func TestMatchingBracketForward(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, bracketLines...)
+
+	pager.mode.onRune('%')
+	assert.Equal(t, 9, pager.lineIndex().Index())
+
+	// Jumping back should work
+	typeRunes(pager, "''")
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	// Nested
+	typeRunes(pager, "2j%")
+	assert.Equal(t, 8, pager.lineIndex().Index())
+	typeRunes(pager, "5k%")
+	assert.Equal(t, 5, pager.lineIndex().Index())
+}
+
+func TestMatchingBracketBackward(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, bracketLines...)
+
+	typeRunes(pager, "8j%")
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	typeRunes(pager, "5j%")
+	assert.Equal(t, 6, pager.lineIndex().Index())
+}
+
+func TestMatchingBracketOnSameLine(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, bracketLines...)
+
+	typeRunes(pager, "j%")
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	assert.Equal(t, "Viewing", modeName(pager))
+}
+
+func TestMatchingBracketNotFound(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, bracketLines...)
+
+	typeRunes(pager, "10j%")
+	assert.Equal(t, 10, pager.lineIndex().Index())
+	assert.Equal(t, "No bracket on the top line", pager.mode.(*PagerModeInfo).Text)
+
+	pager.mode = PagerModeViewing{pager: pager}
+	typeRunes(pager, "j%")
+	assert.Equal(t, 11, pager.lineIndex().Index())
+	assert.Equal(t, "No matching bracket found for (", pager.mode.(*PagerModeInfo).Text)
+}
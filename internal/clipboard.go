@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Local clipboard tools, for when the terminal won't let us copy through it
+var clipboardTools = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// Put text on the system clipboard. OSC 52 through the terminal is preferred
+// since that works over SSH, with a local clipboard tool as fallback.
+func (p *Pager) copyToClipboard(text string) error {
+	clipboarder, ok := p.screen.(twin.Clipboarder)
+	if ok && clipboarder.CopyToClipboard(text) {
+		return nil
+	}
+
+	if os.Getenv("LESSSECURE") == "1" {
+		return fmt.Errorf("Not launching a clipboard tool since LESSSECURE=1 is set in the environment")
+	}
+
+	tried := []string{}
+	for _, tool := range clipboardTools {
+		tried = append(tried, tool[0])
+		path, err := exec.LookPath(tool[0])
+		if err != nil {
+			continue
+		}
+
+		log.Debug("Copying to clipboard using ", tool)
+		command := exec.Command(path, tool[1:]...)
+		command.Stdin = strings.NewReader(text)
+		return command.Run()
+	}
+
+	return fmt.Errorf("No clipboard tool found, tried: %s", strings.Join(tried, ", "))
+}
+
+// Copy the plain text of some lines to the clipboard, and tell the user how it
+// went. The lines can be given in any order.
+func (p *Pager) copyLines(first linemetadata.Index, last linemetadata.Index) {
+	if last.IsBefore(first) {
+		first, last = last, first
+	}
+
+	lines := p.Reader().GetLines(first, first.CountLinesTo(last)).Lines
+	plainLines := make([]string, 0, len(lines))
+	for _, line := range lines {
+		plainLines = append(plainLines, line.Plain())
+	}
+
+	err := p.copyToClipboard(strings.Join(plainLines, "\n"))
+	if err != nil {
+		log.Info("Copying to clipboard failed: ", err)
+		p.setMessage("Copying failed: " + err.Error())
+		return
+	}
+
+	text := fmt.Sprintf("Copied %d lines to the clipboard", len(plainLines))
+	if len(plainLines) == 1 {
+		text = "Copied 1 line to the clipboard"
+	}
+	p.setMessage(text)
+}
+
+// The absolute path of the file backing a reader, or nil if there is no such
+// file, like when reading from stdin.
+func absoluteFileName(r *reader.ReaderImpl) *string {
+	if r == nil || r.FileName == nil {
+		return nil
+	}
+
+	absolute, err := filepath.Abs(*r.FileName)
+	if err != nil {
+		log.Info("Failed to make ", *r.FileName, " absolute, going with it as is: ", err)
+		return r.FileName
+	}
+
+	return &absolute
+}
+
+// Copy the absolute path of the current file to the clipboard, and tell the
+// user how it went.
+func (p *Pager) copyFileName() {
+	var r *reader.ReaderImpl
+	if !p.isShowingHelp {
+		p.readerLock.Lock()
+		r = p.readers[p.currentReader]
+		p.readerLock.Unlock()
+	}
+
+	fileName := absoluteFileName(r)
+	if fileName == nil {
+		p.setMessage("Not viewing a file, no file name to copy")
+		return
+	}
+
+	err := p.copyToClipboard(*fileName)
+	if err != nil {
+		log.Info("Copying file name to clipboard failed: ", err)
+		p.setMessage("Copying failed: " + err.Error())
+		return
+	}
+
+	p.setMessage("Copied file name to the clipboard: " + *fileName)
+}
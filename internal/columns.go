@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/walles/moor/v2/internal/textstyles"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Where each column starts and ends in a line of cells, as cell indices. The
+// end index is exclusive.
+//
+// With an empty delimiter, columns are separated by runs of whitespace, and any
+// leading whitespace is ignored. A "\t" delimiter splits on tabs only, and any
+// other delimiter on that character. With a delimiter, two delimiters in a row
+// means there is an empty column between them.
+func columnRanges(cells []textstyles.CellWithMetadata, delimiter string) [][2]int {
+	isDelimiter := func(cell textstyles.CellWithMetadata) bool {
+		switch delimiter {
+		case "":
+			return cell.IsTab || cell.Rune == ' '
+		case "\t":
+			return cell.StartsTab
+		}
+		return string(cell.Rune) == delimiter
+	}
+
+	ranges := [][2]int{}
+	if delimiter != "" {
+		start := 0
+		for i, cell := range cells {
+			if delimiter == "\t" && cell.IsTab && !cell.StartsTab {
+				// Expanded tabs are one delimiter, however many cells they are
+				start = i + 1
+				continue
+			}
+			if isDelimiter(cell) {
+				ranges = append(ranges, [2]int{start, i})
+				start = i + 1
+			}
+		}
+		return append(ranges, [2]int{start, len(cells)})
+	}
+
+	start := -1 // Not in a column
+	for i, cell := range cells {
+		if isDelimiter(cell) {
+			if start >= 0 {
+				ranges = append(ranges, [2]int{start, i})
+				start = -1
+			}
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		ranges = append(ranges, [2]int{start, len(cells)})
+	}
+	return ranges
+}
+
+// Mark the cells of the one based column number, if this line has that many
+// columns
+func highlightColumn(cells []textstyles.CellWithMetadata, column int, delimiter string) {
+	ranges := columnRanges(cells, delimiter)
+	if column < 1 || column > len(ranges) {
+		// Ragged rows may not have this column
+		return
+	}
+
+	columnRange := ranges[column-1]
+	for i := columnRange[0]; i < columnRange[1]; i++ {
+		cells[i].Style = cells[i].Style.WithAttr(twin.AttrUnderline)
+	}
+}
+
+// The highest number of columns of any line on screen
+func (p *Pager) visibleColumnCount() int {
+	maxCount := 0
+	for _, line := range p.renderLines().inputLines {
+		cells := line.HighlightedTokens(plainTextStyle, searchHitStyle, nil).StyledRunes
+		maxCount = max(maxCount, len(columnRanges(cells, p.ColumnDelimiter)))
+	}
+	return maxCount
+}
+
+// Highlight the next column, or the previous one if delta is -1. Going past the
+// last column on screen turns column highlighting off, and going further starts
+// over from the other end.
+func (p *Pager) cycleHighlightedColumn(delta int) {
+	columnCount := p.visibleColumnCount()
+	if columnCount == 0 {
+		p.highlightedColumn = 0
+		p.setMessage("No columns to highlight")
+		return
+	}
+
+	// Zero is for no column
+	p.highlightedColumn = (p.highlightedColumn + delta + columnCount + 1) % (columnCount + 1)
+
+	if p.highlightedColumn == 0 {
+		p.setMessage("No column highlighted")
+	} else {
+		p.setMessage(fmt.Sprintf("Highlighting column %d of %d", p.highlightedColumn, columnCount))
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/textstyles"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func testColumnRanges(t *testing.T, line string, delimiter string) [][2]int {
+	t.Helper()
+	cells := textstyles.StyledRunesFromString(twin.StyleDefault, line, &linemetadata.Index{}).StyledRunes
+	return columnRanges(cells, delimiter)
+}
+
+func TestColumnRangesWhitespace(t *testing.T) {
+	assert.DeepEqual(t, [][2]int{{2, 5}, {8, 9}, {10, 12}}, testColumnRanges(t, "  abc   d ef  ", ""))
+	assert.DeepEqual(t, [][2]int{}, testColumnRanges(t, "   ", ""))
+
+	// Tabs are expanded into spaces, but still separate columns
+	assert.DeepEqual(t, [][2]int{{0, 1}, {8, 9}}, testColumnRanges(t, "a\tb", ""))
+}
+
+func TestColumnRangesDelimiter(t *testing.T) {
+	// Empty columns count
+	assert.DeepEqual(t, [][2]int{{0, 1}, {2, 2}, {3, 6}}, testColumnRanges(t, "a,,b c", ","))
+
+	// Without a delimiter, all of the line is one column
+	assert.DeepEqual(t, [][2]int{{0, 3}}, testColumnRanges(t, "a b", ","))
+}
+
+func TestColumnRangesTab(t *testing.T) {
+	// Spaces don't separate columns, tabs do
+	assert.DeepEqual(t, [][2]int{{0, 3}, {8, 9}}, testColumnRanges(t, "a b\tc", "\t"))
+}
+
+// Underlined cells from one screen row, as a string with spaces for the
+// others. Trailing spaces are removed.
+func underlinedCells(pager *Pager, row int) string {
+	result := ""
+	for _, cell := range pager.renderLines().lines[row].cells {
+		if cell.Style.HasAttr(twin.AttrUnderline) {
+			result += string(cell.Rune)
+		} else {
+			result += " "
+		}
+	}
+	return strings.TrimRight(result, " ")
+}
+
+func TestHighlightColumn(t *testing.T) {
+	pager := createLinesPager(t, 20, 5,
+		"name  age city",
+		"alice 30  Oslo",
+		"bob",
+		"carol 4   Paris")
+
+	pager.mode.onRune(']')
+	assert.Equal(t, 1, pager.highlightedColumn)
+	assert.Equal(t, "name", underlinedCells(pager, 0))
+
+	pager.mode.onRune(']')
+	assert.Equal(t, 2, pager.highlightedColumn)
+	assert.Equal(t, "      age", underlinedCells(pager, 0))
+	assert.Equal(t, "      30", underlinedCells(pager, 1))
+	assert.Equal(t, "      4", underlinedCells(pager, 3))
+
+	// Ragged rows without the column are left alone
+	assert.Equal(t, "", underlinedCells(pager, 2))
+}
+
+func TestCycleHighlightedColumn(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a b c", "d e")
+
+	pager.mode.onRune('[')
+	assert.Equal(t, 3, pager.highlightedColumn)
+
+	pager.mode.onRune(']')
+	assert.Equal(t, 0, pager.highlightedColumn, "Past the last column should mean no column")
+	assert.Equal(t, "", underlinedCells(pager, 0))
+
+	pager.mode.onRune(']')
+	assert.Equal(t, 1, pager.highlightedColumn)
+}
+
+func TestHighlightColumnDelimiter(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "one,two words,three")
+	pager.ColumnDelimiter = ","
+	pager.highlightedColumn = 2
+
+	assert.Equal(t, "    two words", underlinedCells(pager, 0))
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+var diffHeaderStyle = twin.StyleDefault.WithAttr(twin.AttrBold)
+var diffHunkStyle = twin.StyleDefault.WithForeground(twin.NewColor16(6))    // Cyan
+var diffAddedStyle = twin.StyleDefault.WithForeground(twin.NewColor16(2))   // Green
+var diffRemovedStyle = twin.StyleDefault.WithForeground(twin.NewColor16(1)) // Red
+
+// Like "@@ -1,3 +1,4 @@". The line counts default to 1 when left out.
+var diffHunkHeaderPattern = regexp.MustCompile(`^@@ -[0-9]+(?:,([0-9]+))? \+[0-9]+(?:,([0-9]+))? @@`)
+
+// Don't look further back than this for the hunk header of a line
+const diffHunkMaxLines = 1000
+
+// For ColorDiffs, figure out how to style a line from a unified diff. Returns
+// nil for lines that should keep the plain text style, including lines that
+// are already styled, so that we don't color colored diffs twice.
+//
+// r should be the unfiltered reader, it's where we look for hunk headers.
+func diffLineStyle(r reader.Reader, line reader.NumberedLine) *twin.Style {
+	if line.Line.HasStyling() {
+		return nil
+	}
+
+	plain := line.Line.Plain()
+	isFileHeader := strings.HasPrefix(plain, "+++ ") || strings.HasPrefix(plain, "--- ")
+	switch {
+	case isFileHeader && !isInsideDiffHunk(r, line.Number, plain[0]):
+		return &diffHeaderStyle
+	case strings.HasPrefix(plain, "diff "):
+		return &diffHeaderStyle
+	case strings.HasPrefix(plain, "@@"):
+		return &diffHunkStyle
+	case strings.HasPrefix(plain, "+"):
+		return &diffAddedStyle
+	case strings.HasPrefix(plain, "-"):
+		return &diffRemovedStyle
+	}
+
+	return nil
+}
+
+// Lines starting with "--- " or "+++ " are file headers, unless they are
+// removed or added lines inside of a hunk. To tell, we find the hunk header
+// above the line and check whether its line counts reach down to this line.
+//
+// prefix is the first character of the line at number, '-' or '+'.
+func isInsideDiffHunk(r reader.Reader, number linemetadata.Number, prefix byte) bool {
+	firstIndex := max(0, number.AsZeroBased()-diffHunkMaxLines)
+	lines := r.GetLines(linemetadata.IndexFromZeroBased(firstIndex), number.AsZeroBased()-firstIndex+1).Lines
+	if len(lines) == 0 || lines[len(lines)-1].Number != number {
+		// Lines changed under our feet
+		return false
+	}
+
+	// Find the hunk header
+	headerPosition := -1
+	for i := len(lines) - 2; i >= 0; i-- {
+		plain := lines[i].Line.Plain()
+		if strings.HasPrefix(plain, "@@") {
+			headerPosition = i
+			break
+		}
+		if plain != "" && !strings.ContainsRune(" -+\\", rune(plain[0])) {
+			// Not part of a hunk
+			return false
+		}
+	}
+	if headerPosition < 0 {
+		return false
+	}
+
+	match := diffHunkHeaderPattern.FindStringSubmatch(lines[headerPosition].Line.Plain())
+	if match == nil {
+		return false
+	}
+	oldLeft := diffHunkLineCount(match[1])
+	newLeft := diffHunkLineCount(match[2])
+
+	// Count down the lines between the header and our line
+	for _, line := range lines[headerPosition+1 : len(lines)-1] {
+		if oldLeft <= 0 && newLeft <= 0 {
+			// The hunk ended before our line
+			return false
+		}
+
+		plain := line.Line.Plain()
+		switch {
+		case plain == "" || plain[0] == ' ':
+			// Context line. Some tools strip the trailing space from empty
+			// ones, so count empty lines as context too.
+			oldLeft--
+			newLeft--
+		case plain[0] == '-':
+			oldLeft--
+		case plain[0] == '+':
+			newLeft--
+		}
+	}
+
+	if prefix == '-' {
+		return oldLeft > 0
+	}
+	return newLeft > 0
+}
+
+// Parse a line count from a hunk header, missing counts mean 1
+func diffHunkLineCount(count string) int {
+	if count == "" {
+		return 1
+	}
+
+	parsed, err := strconv.Atoi(count)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
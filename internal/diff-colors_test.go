@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestColorDiffs(t *testing.T) {
+	pager := createLinesPager(t, 20, 10,
+		"diff --git a/x b/x",
+		"--- a/x",
+		"+++ b/x",
+		"@@ -1,2 +1,2 @@",
+		" context",
+		"-removed",
+		"+added",
+		"\x1b[35m+colored\x1b[m",
+	)
+	pager.ColorDiffs = true
+
+	pager.redraw("")
+	screen := pager.screen.(*twin.FakeScreen)
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(0)[0].Style)
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(1)[0].Style)
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(2)[0].Style)
+	assert.Equal(t, diffHunkStyle, screen.GetRow(3)[0].Style)
+	assert.Equal(t, plainTextStyle, screen.GetRow(4)[1].Style)
+	assert.Equal(t, diffRemovedStyle, screen.GetRow(5)[1].Style)
+	assert.Equal(t, diffAddedStyle, screen.GetRow(6)[1].Style)
+
+	// Already colored lines should keep their colors
+	assert.Equal(t, twin.StyleDefault.WithForeground(twin.NewColor16(5)), screen.GetRow(7)[1].Style)
+}
+
+func TestColorDiffsOff(t *testing.T) {
+	pager := createLinesPager(t, 20, 10, "+added")
+
+	pager.redraw("")
+	assert.Equal(t, plainTextStyle, pager.screen.(*twin.FakeScreen).GetRow(0)[1].Style)
+}
+
+// Removed and added lines looking like file headers should still be colored as
+// removed and added when they are inside a hunk
+func TestColorDiffsHeaderLookalikes(t *testing.T) {
+	pager := createLinesPager(t, 20, 12,
+		"--- a/x",
+		"+++ b/x",
+		"@@ -1,3 +1,2 @@",
+		" context",
+		"--- removed",
+		"-removed",
+		"+++ added",
+		"--- a/y",
+		"+++ b/y",
+		"@@ -1 +1 @@",
+		"-x",
+		"+y",
+	)
+	pager.ColorDiffs = true
+
+	pager.redraw("")
+	screen := pager.screen.(*twin.FakeScreen)
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(0)[0].Style)
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(1)[0].Style)
+	assert.Equal(t, diffRemovedStyle, screen.GetRow(4)[0].Style)
+	assert.Equal(t, diffAddedStyle, screen.GetRow(6)[0].Style)
+
+	// The first hunk is done, so these are headers again
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(7)[0].Style)
+	assert.Equal(t, diffHeaderStyle, screen.GetRow(8)[0].Style)
+}
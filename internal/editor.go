@@ -5,7 +5,9 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -107,18 +109,68 @@ func pickAnEditor() (string, string, error) {
 	return "", "", fmt.Errorf("No editor found, tried: $VISUAL, $EDITOR, %s", strings.Join(candidates, ", "))
 }
 
+// How to tell different editors which line to start on. The key is the editor
+// binary name. The value takes the file name and the one based line number and
+// returns the arguments to pass.
+var editorLineArgs = map[string]func(fileName string, lineNumber string) []string{
+	"vi":          plusLineNumberArgs,
+	"vim":         plusLineNumberArgs,
+	"nvim":        plusLineNumberArgs,
+	"nano":        plusLineNumberArgs,
+	"emacs":       plusLineNumberArgs,
+	"emacsclient": plusLineNumberArgs,
+	"micro":       plusLineNumberArgs,
+	"kak":         plusLineNumberArgs,
+	"joe":         plusLineNumberArgs,
+	"mg":          plusLineNumberArgs,
+
+	"code":   func(f string, n string) []string { return []string{"--goto", f + ":" + n} },
+	"codium": func(f string, n string) []string { return []string{"--goto", f + ":" + n} },
+
+	"subl": colonLineNumberArgs,
+	"hx":   colonLineNumberArgs,
+
+	"mate": func(f string, n string) []string { return []string{"-l", n, f} },
+}
+
+func plusLineNumberArgs(fileName string, lineNumber string) []string {
+	return []string{"+" + lineNumber, fileName}
+}
+
+func colonLineNumberArgs(fileName string, lineNumber string) []string {
+	return []string{fileName + ":" + lineNumber}
+}
+
+// Build the argv for opening a file in an editor. If we know how to tell the
+// editor which line to start on, and lineNumber is non-nil, we will.
+//
+// The editor string can contain arguments, like "code -w".
+func editorCommand(editor string, fileName string, lineNumber *linemetadata.Number) []string {
+	commandWithArgs := strings.Fields(editor)
+
+	editorName := filepath.Base(commandWithArgs[0])
+	if runtime.GOOS == "windows" {
+		editorName = strings.TrimSuffix(strings.ToLower(editorName), ".exe")
+	}
+
+	lineArgs, found := editorLineArgs[editorName]
+	if !found || lineNumber == nil {
+		return append(commandWithArgs, fileName)
+	}
+
+	return append(commandWithArgs, lineArgs(fileName, strconv.Itoa(lineNumber.AsOneBased()))...)
+}
+
 func handleEditingRequest(p *Pager) {
 	if os.Getenv("LESSSECURE") == "1" {
-		p.mode = &PagerModeInfo{
-			Pager: p,
-			Text:  "Not launching editor since LESSSECURE=1 is set in the environment",
-		}
+		p.setMessage("Not launching editor since LESSSECURE=1 is set in the environment")
 		return
 	}
 
 	editor, editorEnv, err := pickAnEditor()
 	if err != nil {
 		log.Warn("Failed to find an editor: ", err)
+		p.setMessage("Failed to find an editor, try setting $EDITOR")
 		return
 	}
 
@@ -127,18 +179,16 @@ func handleEditingRequest(p *Pager) {
 	firstWord := strings.Fields(editor)[0]
 	editorPath, err := exec.LookPath(firstWord)
 	if err != nil {
-		// FIXME: Show a message in the status bar instead? Nothing wrong with
-		// moor here.
 		log.Warn("Failed to find editor "+firstWord+" from $"+editorEnv+": ", err)
+		p.setMessage("Editor not found: " + firstWord)
 		return
 	}
 
 	// Check that the editor is executable
 	err = errUnlessExecutable(editorPath)
 	if err != nil {
-		// FIXME: Show a message in the status bar instead? Nothing wrong with
-		// moor here.
-		log.Warn("Editor from {} not executable: {}", editorEnv, err)
+		log.Warn("Editor from ", editorEnv, " not executable: ", err)
+		p.setMessage("Editor not executable: " + editorPath)
 		return
 	}
 
@@ -164,15 +214,22 @@ func handleEditingRequest(p *Pager) {
 		fileToEdit, err = dumpToTempFile(p.readers[p.currentReader])
 		if err != nil {
 			log.Warn("Failed to create temp file to edit: ", err)
+			p.setMessage("No file to edit, and failed to create a temporary one")
 			return
 		}
 	}
 
+	var lineNumber *linemetadata.Number
+	if lineIndex := p.lineIndex(); lineIndex != nil {
+		if line := p.Reader().GetLine(*lineIndex); line != nil {
+			lineNumber = &line.Number
+		}
+	}
+
 	p.AfterExit = func() error {
 		// NOTE: If you do any changes here, make sure they work with both "nano"
 		// and "code -w" (VSCode).
-		commandWithArgs := strings.Fields(editor)
-		commandWithArgs = append(commandWithArgs, fileToEdit)
+		commandWithArgs := editorCommand(editor, fileToEdit, lineNumber)
 
 		log.Info("'v' pressed, launching editor: ", commandWithArgs)
 		command := exec.Command(commandWithArgs[0], commandWithArgs[1:]...)
@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"gotest.tools/v3/assert"
+)
+
+func TestEditorCommand(t *testing.T) {
+	line := linemetadata.NumberFromOneBased(42)
+
+	assert.DeepEqual(t, editorCommand("vim", "x.txt", &line), []string{"vim", "+42", "x.txt"})
+	assert.DeepEqual(t, editorCommand("/usr/bin/nano", "x.txt", &line), []string{"/usr/bin/nano", "+42", "x.txt"})
+	assert.DeepEqual(t, editorCommand("code -w", "x.txt", &line), []string{"code", "-w", "--goto", "x.txt:42"})
+	assert.DeepEqual(t, editorCommand("subl -w", "x.txt", &line), []string{"subl", "-w", "x.txt:42"})
+	assert.DeepEqual(t, editorCommand("mate -w", "x.txt", &line), []string{"mate", "-w", "-l", "42", "x.txt"})
+}
+
+func TestEditorCommandUnknownEditor(t *testing.T) {
+	line := linemetadata.NumberFromOneBased(42)
+	assert.DeepEqual(t, editorCommand("ed", "x.txt", &line), []string{"ed", "x.txt"})
+}
+
+func TestEditorCommandNoLineNumber(t *testing.T) {
+	assert.DeepEqual(t, editorCommand("vim", "x.txt", nil), []string{"vim", "x.txt"})
+}
@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -8,42 +10,63 @@ func (p *Pager) previousFile() {
 	p.readerLock.Lock()
 	defer p.readerLock.Unlock()
 
-	newIndex := p.currentReader - 1
-	if newIndex < 0 {
-		newIndex = 0
+	if p.currentReader == 0 {
+		p.setMessage("Already at the first file")
+		return
 	}
-	p.currentReader = newIndex
-	log.Tracef("Switched to previous file, index %d", p.currentReader)
 
-	select {
-	case p.readerSwitched <- struct{}{}:
-	default:
-	}
+	p.switchToReader(p.currentReader - 1)
+	log.Tracef("Switched to previous file, index %d", p.currentReader)
 }
 
 func (p *Pager) nextFile() {
 	p.readerLock.Lock()
 	defer p.readerLock.Unlock()
 
-	newIndex := p.currentReader + 1
-	if newIndex >= len(p.readers) {
-		newIndex = len(p.readers) - 1
+	if p.currentReader >= len(p.readers)-1 {
+		p.setMessage("Already at the last file")
+		return
 	}
-	p.currentReader = newIndex
-	log.Tracef("Switched to next file, index %d", p.currentReader)
 
-	select {
-	case p.readerSwitched <- struct{}{}:
-	default:
-	}
+	p.switchToReader(p.currentReader + 1)
+	log.Tracef("Switched to next file, index %d", p.currentReader)
 }
 
 func (p *Pager) firstFile() {
 	p.readerLock.Lock()
 	defer p.readerLock.Unlock()
 
-	p.currentReader = 0
+	p.switchToReader(0)
 	log.Tracef("Switched to first file, index %d", p.currentReader)
+}
+
+// Must be called with readerLock held
+func (p *Pager) switchToReader(newIndex int) {
+	if newIndex == p.currentReader {
+		return
+	}
+
+	// Remember where we were in the file we're leaving
+	if p.scrollPositions == nil {
+		p.scrollPositions = make(map[int]scrollPosition)
+	}
+	p.scrollPositions[p.currentReader] = p.scrollPosition
+
+	p.currentReader = newIndex
+
+	// Filters, marks and piped output are about the previous file, they make
+	// no sense in the new one
+	p.filterPattern = nil
+	p.pipedReader = nil
+	p.bookmarks = make(map[rune]scrollPosition)
+
+	p.filteringReader.SetBackingReader(p.readers[p.currentReader])
+
+	if scrollPosition, found := p.scrollPositions[p.currentReader]; found {
+		p.scrollPosition = scrollPosition
+	} else {
+		p.scrollPosition = newScrollPosition(fmt.Sprintf("Pager file %d", p.currentReader+1))
+	}
 
 	select {
 	case p.readerSwitched <- struct{}{}:
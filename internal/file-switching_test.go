@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func colonCommand(pager *Pager, command rune) {
+	pager.mode.onRune(':')
+	pager.mode.onRune(command)
+}
+
+func TestNextPreviousFile(t *testing.T) {
+	pager := NewPager(
+		reader.NewFromTextForTesting("first", "a1\na2\na3\na4\na5"),
+		reader.NewFromTextForTesting("second", "b1\nb2\nb3\nb4\nb5"),
+		reader.NewFromTextForTesting("third", "c1\nc2\nc3\nc4\nc5"),
+	)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.showLineNumbers = false
+	assert.Equal(t, "a1", screenRows(pager)[0])
+
+	colonCommand(pager, 'n')
+	assert.Equal(t, "b1", screenRows(pager)[0])
+
+	colonCommand(pager, 'n')
+	assert.Equal(t, "c1", screenRows(pager)[0])
+
+	colonCommand(pager, 'p')
+	assert.Equal(t, "b1", screenRows(pager)[0])
+
+	colonCommand(pager, 'x')
+	assert.Equal(t, "a1", screenRows(pager)[0])
+}
+
+func TestFileSwitchingStatusBar(t *testing.T) {
+	pager := NewPager(
+		reader.NewFromTextForTesting("first", "a1\na2\na3\na4\na5"),
+		reader.NewFromTextForTesting("second", "b1\nb2\nb3\nb4\nb5"),
+		reader.NewFromTextForTesting("third", "c1\nc2\nc3\nc4\nc5"),
+	)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.showLineNumbers = false
+	colonCommand(pager, 'n')
+
+	rows := screenRows(pager)
+	assert.Equal(t, "(file 2 of 3) second: 5 lines  40%", rows[2][:len("(file 2 of 3) second: 5 lines  40%")])
+}
+
+func TestFileSwitchingPastTheEnds(t *testing.T) {
+	pager := NewPager(
+		reader.NewFromTextForTesting("first", "a1\na2\na3\na4\na5"),
+		reader.NewFromTextForTesting("second", "b1\nb2\nb3\nb4\nb5"),
+		reader.NewFromTextForTesting("third", "c1\nc2\nc3\nc4\nc5"),
+	)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.showLineNumbers = false
+
+	colonCommand(pager, 'p')
+	assert.Equal(t, "Already at the first file", screenRows(pager)[2])
+	assert.Equal(t, "a1", screenRows(pager)[0])
+
+	colonCommand(pager, 'n')
+	colonCommand(pager, 'n')
+	colonCommand(pager, 'n')
+	assert.Equal(t, "Already at the last file", screenRows(pager)[2])
+	assert.Equal(t, "c1", screenRows(pager)[0])
+}
+
+func TestFileSwitchingKeepsScrollPositions(t *testing.T) {
+	pager := NewPager(
+		reader.NewFromTextForTesting("first", "a1\na2\na3\na4\na5"),
+		reader.NewFromTextForTesting("second", "b1\nb2\nb3\nb4\nb5"),
+		reader.NewFromTextForTesting("third", "c1\nc2\nc3\nc4\nc5"),
+	)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.showLineNumbers = false
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "a3", screenRows(pager)[0])
+
+	// New files start at the top
+	colonCommand(pager, 'n')
+	assert.Equal(t, "b1", screenRows(pager)[0])
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "b2", screenRows(pager)[0])
+
+	// Going back should take us to where we were
+	colonCommand(pager, 'p')
+	assert.Equal(t, "a3", screenRows(pager)[0])
+
+	colonCommand(pager, 'n')
+	assert.Equal(t, "b2", screenRows(pager)[0])
+}
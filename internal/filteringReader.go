@@ -21,8 +21,12 @@ type FilteringReader struct {
 	// original pattern, including if it is set to nil.
 	FilterPattern **regexp.Regexp
 
-	// Protects filteredLinesCache, unfilteredLineCountWhenCaching, and
-	// filterPatternWhenCaching.
+	// If this points to true, we keep the lines not matching FilterPattern
+	// rather than the ones matching it
+	FilterInverted *bool
+
+	// Protects filteredLinesCache, unfilteredLineCountWhenCaching,
+	// filterPatternWhenCaching and filterInvertedWhenCaching.
 	lock sync.Mutex
 
 	// nil means no filtering has happened yet
@@ -36,6 +40,14 @@ type FilteringReader struct {
 	// This is the pattern that was used when we cached the lines. If it
 	// doesn't match the current pattern, then our cache needs to be rebuilt.
 	filterPatternWhenCaching *regexp.Regexp
+
+	// Whether FilterInverted was set when we cached the lines
+	filterInvertedWhenCaching bool
+}
+
+// Please hold the lock when calling this method.
+func (f *FilteringReader) isInverted() bool {
+	return f.FilterInverted != nil && *f.FilterInverted
 }
 
 // Please hold the lock when calling this method.
@@ -48,13 +60,15 @@ func (f *FilteringReader) rebuildCache() {
 	// Mark cache base conditions
 	f.unfilteredLineCountWhenCaching = f.BackingReader.GetLineCount()
 	f.filterPatternWhenCaching = filterPattern
+	f.filterInvertedWhenCaching = f.isInverted()
 
 	// Repopulate the cache
 	allBaseLines := f.BackingReader.GetLines(linemetadata.Index{}, math.MaxInt)
 	resultIndex := 0
 	for _, line := range allBaseLines.Lines {
-		if filterPattern != nil && len(filterPattern.String()) > 0 && !filterPattern.MatchString(line.Line.Plain()) {
-			// We have a pattern but it doesn't match
+		if filterPattern != nil && len(filterPattern.String()) > 0 && filterPattern.MatchString(line.Line.Plain()) == f.filterInvertedWhenCaching {
+			// We have a pattern but it doesn't match, or it matches and we're
+			// inverted
 			continue
 		}
 
@@ -94,7 +108,7 @@ func (f *FilteringReader) getAllLines() []reader.NumberedLine {
 	if f.filterPatternWhenCaching != nil {
 		cacheFilterPattern = f.filterPatternWhenCaching.String()
 	}
-	if currentFilterPattern != cacheFilterPattern {
+	if currentFilterPattern != cacheFilterPattern || f.isInverted() != f.filterInvertedWhenCaching {
 		f.rebuildCache()
 		return *f.filteredLinesCache
 	}
@@ -129,6 +143,10 @@ func (f *FilteringReader) ShouldShowLineCount() bool {
 	panic("Unexpected call to FilteringReader.ShouldShowLineCount()")
 }
 
+func (f *FilteringReader) IsLoading() bool {
+	return f.BackingReader.IsLoading()
+}
+
 func (f *FilteringReader) GetLine(index linemetadata.Index) *reader.NumberedLine {
 	if f.shouldPassThrough() {
 		return f.BackingReader.GetLine(index)
@@ -202,8 +220,13 @@ func (f *FilteringReader) createStatus(lastLine *linemetadata.Index) string {
 		lineString += "s"
 	}
 
-	return fmt.Sprintf("Filtered: %s%s %s  %d%%",
-		acceptedCountString, baseCountString, lineString, percent)
+	loading := ""
+	if f.BackingReader.IsLoading() {
+		loading = "loading… "
+	}
+
+	return fmt.Sprintf("Filtered: %s%s%s %s  %d%%",
+		loading, acceptedCountString, baseCountString, lineString, percent)
 }
 
 // SetBackingReader switches the underlying reader while holding the lock and
@@ -222,4 +245,13 @@ func (f *FilteringReader) SetBackingReader(r reader.Reader) {
 	f.filteredLinesCache = nil
 	f.unfilteredLineCountWhenCaching = -1
 	f.filterPatternWhenCaching = nil
+	f.filterInvertedWhenCaching = false
+}
+
+// Get the underlying reader while holding the lock
+func (f *FilteringReader) getBackingReader() reader.Reader {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.BackingReader
 }
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+// Lines with form feeds in them start new pages, like on a printer
+func (p *Pager) isPageBreak(index linemetadata.Index) bool {
+	line := p.Reader().GetLine(index)
+	return line != nil && line.Line.HasFormFeed()
+}
+
+// The closest page break after from, or before it when going backwards. Stops
+// looking after limit, inclusive. Returns nil if there is no page break on the
+// way.
+func (p *Pager) findPageBreak(from linemetadata.Index, limit int, direction SearchDirection) *linemetadata.Index {
+	step := 1
+	if direction == SearchDirectionBackward {
+		step = -1
+	}
+
+	lineCount := p.Reader().GetLineCount()
+	for index := from.Index() + step; index >= 0 && index < lineCount; index += step {
+		if index*step > limit*step {
+			// Past the limit
+			return nil
+		}
+
+		candidate := linemetadata.IndexFromZeroBased(index)
+		if p.isPageBreak(candidate) {
+			return &candidate
+		}
+	}
+
+	return nil
+}
+
+// Scroll this many screen lines, negative means up. With FormFeedPages, stop
+// at any page break on the way.
+func (p *Pager) scrollPage(lines int) {
+	current := p.lineIndex()
+	if !p.FormFeedPages || current == nil {
+		p.scrollScreenLines(lines)
+		return
+	}
+
+	direction := SearchDirectionForward
+	if lines < 0 {
+		direction = SearchDirectionBackward
+	}
+
+	target := p.scrollPosition.NextLine(lines)
+	limit := target.lineIndex(p)
+	if limit == nil {
+		p.scrollScreenLines(lines)
+		return
+	}
+
+	pageBreak := p.findPageBreak(*current, limit.Index(), direction)
+	if pageBreak == nil {
+		p.scrollScreenLines(lines)
+		return
+	}
+
+	if direction == SearchDirectionForward && p.handleAtEnd() {
+		return
+	}
+
+	p.scrollPosition = NewScrollPositionFromIndex(*pageBreak, "scrollPage")
+	p.handleScrolled(lines)
+}
+
+// Put the next page break at the top of the screen. At the end of the input,
+// go to the end and tell the user.
+func (p *Pager) scrollToNextPage() {
+	current := p.lineIndex()
+	if current == nil {
+		// No lines
+		return
+	}
+
+	pageBreak := p.findPageBreak(*current, p.Reader().GetLineCount(), SearchDirectionForward)
+	if pageBreak == nil {
+		p.scrollToEnd()
+		p.setMessage("No more pages below")
+		return
+	}
+
+	p.scrollPosition = NewScrollPositionFromIndex(*pageBreak, "scrollToNextPage")
+	p.handleScrolledDown()
+}
+
+// Put the previous page break at the top of the screen. Before the first page
+// break, go to the start and tell the user.
+func (p *Pager) scrollToPreviousPage() {
+	current := p.lineIndex()
+	if current == nil {
+		// No lines
+		return
+	}
+
+	pageBreak := p.findPageBreak(*current, 0, SearchDirectionBackward)
+	if pageBreak == nil {
+		p.scrollPosition = newScrollPosition("scrollToPreviousPage")
+		p.handleScrolledUp()
+		p.setMessage("No more pages above")
+		return
+	}
+
+	p.scrollPosition = NewScrollPositionFromIndex(*pageBreak, "scrollToPreviousPage")
+	p.handleScrolledUp()
+}
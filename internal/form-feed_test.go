@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+var formFeedLines = []string{
+	"page 1",   // 0
+	"one",      // 1
+	"\fpage 2", // 2
+	"two",      // 3
+	"two",      // 4
+	"two",      // 5
+	"two",      // 6
+	"two",      // 7
+	"two",      // 8
+	"\fpage 3", // 9
+	"three",    // 10
+	"three",    // 11
+	"three",    // 12
+	"three",    // 13
+	"three",    // 14
+	"three",    // 15
+}
+
+func TestFormFeedPagesDown(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, formFeedLines...)
+	pager.FormFeedPages = true
+
+	pager.mode.onRune(' ')
+	assert.Equal(t, 2, pager.lineIndex().Index(), "Should stop at the form feed")
+
+	pager.mode.onRune(' ')
+	assert.Equal(t, 6, pager.lineIndex().Index(), "No form feed on the way, a full page")
+
+	pager.mode.onKey(twin.KeyPgDown)
+	assert.Equal(t, 9, pager.lineIndex().Index(), "Should stop at the form feed")
+}
+
+func TestFormFeedPagesUp(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, formFeedLines...)
+	pager.FormFeedPages = true
+	pager.scrollPosition = NewScrollPositionFromIndex(pager.lineIndex().NonWrappingAdd(9), "TestFormFeedPagesUp")
+
+	pager.mode.onRune('b')
+	assert.Equal(t, 5, pager.lineIndex().Index(), "No form feed on the way, a full page")
+
+	pager.mode.onRune('b')
+	assert.Equal(t, 2, pager.lineIndex().Index(), "Should stop at the form feed")
+
+	pager.mode.onRune('b')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestFormFeedPagesOff(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, formFeedLines...)
+
+	pager.mode.onRune(' ')
+	assert.Equal(t, 4, pager.lineIndex().Index(), "Form feeds should not matter by default")
+}
+
+func TestJumpBetweenFormFeedPages(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, formFeedLines...)
+
+	pager.mode.onRune(')')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	pager.mode.onRune(')')
+	assert.Equal(t, 9, pager.lineIndex().Index())
+
+	pager.mode.onRune(')')
+	assert.Assert(t, pager.isScrolledToEnd())
+	assert.Assert(t, isInfoMode(pager), "Should say there are no more pages")
+
+	pager.mode = PagerModeViewing{pager: pager}
+	pager.mode.onRune('(')
+	assert.Equal(t, 9, pager.lineIndex().Index())
+
+	pager.mode.onRune('(')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	pager.mode.onRune('(')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+	assert.Assert(t, isInfoMode(pager), "Should say there are no more pages")
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+)
+
+// How many input lines are shown as header lines right now? There is always at
+// least one line and one screen row left for scrolling.
+func (p *Pager) headerLineCount() int {
+	if p.HeaderLines <= 0 || p.isShowingHelp {
+		return 0
+	}
+
+	return max(0, min(p.HeaderLines, p.unfilteredReader().GetLineCount()-1, p.contentsHeight()-1))
+}
+
+// Header lines always come from the start of the input, even when filtering
+func (p *Pager) unfilteredReader() reader.Reader {
+	if p.isShowingHelp {
+		return _HelpReader
+	}
+
+	return p.filteringReader.getBackingReader()
+}
+
+// Scrolling up stops here, the lines above this one are in the header
+func (p *Pager) firstScrollableIndex() linemetadata.Index {
+	count := p.headerLineCount()
+	if count == 0 || p.filterPattern == nil {
+		return linemetadata.IndexFromZeroBased(count)
+	}
+
+	// Filtering keeps the line order, so any header lines that pass the filter
+	// come first. Skip those so they don't show up twice.
+	headerLines := p.unfilteredReader().GetLines(linemetadata.Index{}, count).Lines
+	lastHeaderNumber := headerLines[len(headerLines)-1].Number
+	skip := 0
+	for _, line := range p.Reader().GetLines(linemetadata.Index{}, count).Lines {
+		if line.Number.IsAfter(lastHeaderNumber) {
+			break
+		}
+		skip++
+	}
+
+	return linemetadata.IndexFromZeroBased(skip)
+}
+
+// Header lines are never wrapped, but they do scroll sideways along with the
+// rest of the lines to keep columns aligned.
+func (p *Pager) renderHeaderLines(numberPrefixLength int) []renderedLine {
+	count := p.headerLineCount()
+	if count == 0 {
+		return nil
+	}
+
+	rendered := make([]renderedLine, 0, count)
+	for _, line := range p.unfilteredReader().GetLines(linemetadata.Index{}, count).Lines {
+		rendered = append(rendered, p.renderHeaderLine(line, numberPrefixLength))
+	}
+
+	return rendered
+}
+
+func (p *Pager) renderHeaderLine(line reader.NumberedLine, numberPrefixLength int) renderedLine {
+	highlighted := p.highlightedLine(line)
+
+	lineNumber := line.Number
+	return renderedLine{
+		inputLineIndex:    line.Index,
+		cells:             p.decorateLine(&lineNumber, "", numberPrefixLength, highlighted.StyledRunes),
+		containsSearchHit: highlighted.ContainsSearchHit,
+		trailer:           highlighted.Trailer,
+	}
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestHeaderLines(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "name  age", "anna  31", "bert  42", "cecil 53", "doris 64")
+	pager.HeaderLines = 1
+
+	assert.DeepEqual(t, screenRows(pager), []string{"name  age", "anna  31", "bert  42", "5 lines  60%  (TOP)"})
+
+	// The header stays while the body scrolls
+	typeRunes(pager, "j")
+	assert.DeepEqual(t, screenRows(pager)[:3], []string{"name  age", "bert  42", "cecil 53"})
+
+	typeRunes(pager, "G")
+	assert.DeepEqual(t, screenRows(pager)[:3], []string{"name  age", "cecil 53", "doris 64"})
+
+	// Scrolling up stops below the header, it should never show up twice
+	typeRunes(pager, "<k")
+	assert.DeepEqual(t, screenRows(pager)[:3], []string{"name  age", "anna  31", "bert  42"})
+	assert.Equal(t, 1, pager.lineIndex().Index())
+}
+
+func TestHeaderLinesScrollSideways(t *testing.T) {
+	pager := createLinesPager(t, 6, 3, "0123456789", "abcdefghij", "ABCDEFGHIJ")
+	pager.HeaderLines = 1
+	pager.ShowStatusBar = false
+
+	screenRows(pager) // Needed for moveRight() to know how far it can go
+	pager.moveRight(4)
+	rows := screenRows(pager)
+	assert.Equal(t, rows[0], "<56789")
+	assert.Equal(t, rows[1], "<fghij")
+}
+
+func TestHeaderLinesLeaveRoomForScrolling(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, "a", "b", "c", "d")
+	pager.HeaderLines = 5
+	pager.ShowStatusBar = false
+
+	// One row left for scrolling
+	assert.Equal(t, pager.headerLineCount(), 2)
+	assert.DeepEqual(t, screenRows(pager), []string{"a", "b", "c"})
+
+	typeRunes(pager, "j")
+	assert.DeepEqual(t, screenRows(pager), []string{"a", "b", "d"})
+}
+
+// Filtering applies to the lines below the header, the header itself stays
+func TestHeaderLinesFiltered(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "name  age", "anna  31", "bert  42", "cecil 53")
+	pager.HeaderLines = 1
+	pager.ShowStatusBar = false
+
+	typeFilter(pager, "bert")
+	assert.DeepEqual(t, screenRows(pager)[:2], []string{"name  age", "bert  42"})
+
+	// A header line passing the filter must not show up twice
+	pager.mode.onKey(twin.KeyEscape)
+	typeFilter(pager, "a")
+	assert.DeepEqual(t, screenRows(pager)[:3], []string{"name  age", "anna  31", "---"})
+}
+
+// Header rows get the same highlighting as other rows
+func TestHeaderLinesColorDiffs(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "+added", "-removed")
+	pager.HeaderLines = 1
+	pager.ColorDiffs = true
+
+	pager.redraw("")
+	screen := pager.screen.(*twin.FakeScreen)
+	assert.Equal(t, diffAddedStyle, screen.GetRow(0)[1].Style)
+}
@@ -13,6 +13,7 @@ type AcceptMode int
 const (
 	INPUTBOX_ACCEPT_ALL AcceptMode = iota
 	INPUTBOX_ACCEPT_POSITIVE_NUMBERS
+	INPUTBOX_ACCEPT_POSITIVE_NUMBERS_AND_PERCENT
 )
 
 type InputBox struct {
@@ -147,6 +148,11 @@ func (b *InputBox) handleRune(char rune) bool {
 			return false
 		}
 	}
+	if b.accept == INPUTBOX_ACCEPT_POSITIVE_NUMBERS_AND_PERCENT {
+		if !unicode.IsDigit(char) && char != '%' {
+			return false
+		}
+	}
 
 	// Insert at cursor position
 	runes := []rune(b.text)
@@ -0,0 +1,579 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Something the user can make the pager do by pressing a key in viewing mode.
+//
+// count is the number typed before the key, as in "10j", or 0 if none was
+// typed.
+type pagerAction func(p *Pager, count int)
+
+// Bind a key to this to make it do nothing
+const unboundAction = "none"
+
+// All actions keys can be bound to, by name
+var pagerActions = map[string]pagerAction{
+	"quit": func(p *Pager, _ int) {
+		p.quitOrConfirm(p.ConfirmQuit)
+	},
+
+	// For binding vi style ZZ: "Z confirmQuit"
+	"confirmQuit": func(p *Pager, _ int) {
+		p.quitOrConfirm(true)
+	},
+
+	"editFile": func(p *Pager, _ int) {
+		handleEditingRequest(p)
+	},
+
+	"reload": func(p *Pager, _ int) {
+		p.reloadFile()
+	},
+
+	"showHelp": func(p *Pager, _ int) {
+		p.showHelp()
+	},
+
+	"toggleStatusBar": func(p *Pager, _ int) {
+		p.ShowStatusBar = !p.ShowStatusBar
+	},
+
+	"scrollUp": func(p *Pager, count int) {
+		// Clipping is done in _Redraw()
+		p.scrollPosition = p.scrollPosition.PreviousLine(max(1, count) * p.ScrollStepLines)
+		p.handleScrolledUp()
+	},
+
+	"scrollDown": func(p *Pager, count int) {
+		if p.handleAtEnd() {
+			return
+		}
+
+		// Clipping is done in _Redraw()
+		p.scrollPosition = p.scrollPosition.NextLine(max(1, count) * p.ScrollStepLines)
+		p.handleScrolledDown()
+	},
+
+	"scrollLeft": func(p *Pager, _ int) {
+		p.moveRight(-p.SideScrollAmount)
+	},
+
+	"scrollRight": func(p *Pager, _ int) {
+		p.moveRight(p.SideScrollAmount)
+	},
+
+	"scrollLeftOneColumn": func(p *Pager, _ int) {
+		p.moveRight(-1)
+	},
+
+	"scrollRightOneColumn": func(p *Pager, _ int) {
+		p.moveRight(1)
+	},
+
+	"scrollToLeftEdge": func(p *Pager, _ int) {
+		p.leftColumnZeroBased = 0
+		if !p.showLineNumbers {
+			// Line numbers not visible, turn them on if the user wants them.
+			p.showLineNumbers = p.ShowLineNumbers
+		}
+	},
+
+	"scrollToRightEdge": func(p *Pager, _ int) {
+		p.scrollToRightEdge()
+	},
+
+	"pageUp": func(p *Pager, count int) {
+		p.scrollPage(-max(1, count) * p.pageScrollDistance())
+	},
+
+	"pageDown": func(p *Pager, count int) {
+		p.scrollPage(max(1, count) * p.pageScrollDistance())
+	},
+
+	"halfPageUp": func(p *Pager, count int) {
+		p.scrollScreenLines(-max(1, count) * p.halfPageScrollDistance())
+	},
+
+	"halfPageDown": func(p *Pager, count int) {
+		p.scrollScreenLines(max(1, count) * p.halfPageScrollDistance())
+	},
+
+	"previousParagraph": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.scrollToPreviousParagraph()
+		}
+	},
+
+	"nextParagraph": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.scrollToNextParagraph()
+		}
+	},
+
+	"previousPage": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.scrollToPreviousPage()
+		}
+	},
+
+	"nextPage": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.scrollToNextPage()
+		}
+	},
+
+	"matchingBracket": func(p *Pager, _ int) {
+		p.scrollToMatchingBracket()
+	},
+
+	"widestLine": func(p *Pager, _ int) {
+		p.scrollToWidestLine()
+	},
+
+	"gotoStart": func(p *Pager, _ int) {
+		p.rememberPosition()
+		p.scrollPosition = newScrollPosition("Pager scroll position")
+		p.handleScrolledUp()
+	},
+
+	"gotoEnd": func(p *Pager, count int) {
+		p.rememberPosition()
+		if count > 0 {
+			// "5G" goes to line 5, just like in less and vi
+			p.goToLine(linemetadata.IndexFromOneBased(count))
+			return
+		}
+		p.scrollToEnd()
+	},
+
+	"gotoLine": func(p *Pager, count int) {
+		if count > 0 {
+			// "5g" goes to line 5, just like in less
+			p.rememberPosition()
+			p.goToLine(linemetadata.IndexFromOneBased(count))
+			return
+		}
+		p.mode = NewPagerModeGotoLine(p)
+		p.setTargetLine(nil)
+	},
+
+	"follow": func(p *Pager, _ int) {
+		p.rememberPosition()
+		p.startFollowing()
+		p.setMessage("Following, scroll up to stop")
+	},
+
+	"searchForward": func(p *Pager, _ int) {
+		p.mode = NewPagerModeSearch(p, SearchDirectionForward, p.scrollPosition)
+		p.setTargetLine(nil)
+		p.searchString = ""
+		p.searchPattern = nil
+	},
+
+	"searchBackward": func(p *Pager, _ int) {
+		p.mode = NewPagerModeSearch(p, SearchDirectionBackward, p.scrollPosition)
+		p.setTargetLine(nil)
+		p.searchString = ""
+		p.searchPattern = nil
+	},
+
+	"searchNext": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.scrollToNextSearchHit()
+			if p.isNotFound() {
+				break
+			}
+		}
+	},
+
+	"searchPrevious": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.scrollToPreviousSearchHit()
+			if p.isNotFound() {
+				break
+			}
+		}
+	},
+
+	"searchAgain": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.repeatSearch(false)
+			if p.isNotFound() {
+				break
+			}
+		}
+	},
+
+	"searchAgainReversed": func(p *Pager, count int) {
+		for range max(1, count) {
+			p.repeatSearch(true)
+			if p.isNotFound() {
+				break
+			}
+		}
+	},
+
+	"clearSearch": func(p *Pager, _ int) {
+		p.searchString = ""
+		p.searchPattern = nil
+	},
+
+	"filter": func(p *Pager, _ int) {
+		if !p.isShowingHelp {
+			// Filtering the help text is not supported. Feel free to work on
+			// that if you feel that's time well spent.
+			p.mode = NewPagerModeFilter(p)
+			p.searchString = ""
+			p.searchPattern = nil
+			p.clearFilter()
+		}
+	},
+
+	"switchFile": func(p *Pager, _ int) {
+		if len(p.readers) > 1 {
+			p.mode = &PagerModeColonCommand{pager: p}
+			p.setTargetLine(nil)
+		} else {
+			p.setMessage("Pass more files on the command line to be able to switch between them.")
+		}
+	},
+
+	"pipe": func(p *Pager, _ int) {
+		if !p.isShowingHelp {
+			p.mode = NewPagerModePipe(p)
+		}
+	},
+
+	"highlight": func(p *Pager, _ int) {
+		p.mode = NewPagerModeHighlight(p)
+	},
+
+	"reposition": func(p *Pager, count int) {
+		p.mode = PagerModeReposition{pager: p, count: count}
+		p.setTargetLine(nil)
+	},
+
+	"copy": func(p *Pager, _ int) {
+		p.mode = PagerModeCopy{pager: p}
+		p.setTargetLine(nil)
+	},
+
+	"openUrl": func(p *Pager, _ int) {
+		p.startOpeningUrl()
+	},
+
+	"copyFileName": func(p *Pager, _ int) {
+		p.copyFileName()
+	},
+
+	"setMark": func(p *Pager, _ int) {
+		p.mode = PagerModeMark{pager: p}
+		p.setTargetLine(nil)
+	},
+
+	"jumpToMark": func(p *Pager, _ int) {
+		p.mode = PagerModeJumpToMark{pager: p}
+		p.setTargetLine(nil)
+	},
+
+	"listMarks": func(p *Pager, _ int) {
+		p.listMarks()
+	},
+
+	"toggleWrap": func(p *Pager, _ int) {
+		p.toggleWrapLongLines()
+	},
+
+	"toggleSqueezeBlankLines": func(p *Pager, _ int) {
+		p.toggleSqueezeBlankLines()
+	},
+
+	"toggleRuler": func(p *Pager, _ int) {
+		p.toggleRuler()
+	},
+
+	"toggleShowEscapes": func(p *Pager, _ int) {
+		p.toggleShowEscapes()
+	},
+
+	"nextColumn": func(p *Pager, _ int) {
+		p.cycleHighlightedColumn(1)
+	},
+
+	"previousColumn": func(p *Pager, _ int) {
+		p.cycleHighlightedColumn(-1)
+	},
+
+	"toggleDimNonMatchingLines": func(p *Pager, _ int) {
+		p.toggleDimNonMatchingLines()
+	},
+
+	"cycleLineNumbers": func(p *Pager, _ int) {
+		p.cycleLineNumbers()
+	},
+
+	"cycleTabSize": func(p *Pager, _ int) {
+		p.cycleTabSize()
+	},
+}
+
+// Names of special keys, for use in key binding files. Single characters are
+// bound by just typing them, and control characters as "ctrl-x".
+var keyCodeNames = map[string]twin.KeyCode{
+	"escape":    twin.KeyEscape,
+	"enter":     twin.KeyEnter,
+	"backspace": twin.KeyBackspace,
+	"delete":    twin.KeyDelete,
+	"up":        twin.KeyUp,
+	"down":      twin.KeyDown,
+	"right":     twin.KeyRight,
+	"left":      twin.KeyLeft,
+	"alt-up":    twin.KeyAltUp,
+	"alt-down":  twin.KeyAltDown,
+	"alt-right": twin.KeyAltRight,
+	"alt-left":  twin.KeyAltLeft,
+	"home":      twin.KeyHome,
+	"end":       twin.KeyEnd,
+	"pgup":      twin.KeyPgUp,
+	"pgdown":    twin.KeyPgDown,
+}
+
+// Maps keys to names of pagerActions
+type KeyBindings struct {
+	keys  map[twin.KeyCode]string
+	runes map[rune]string
+}
+
+func DefaultKeyBindings() KeyBindings {
+	return KeyBindings{
+		keys: map[twin.KeyCode]string{
+			twin.KeyEscape:   "quit",
+			twin.KeyUp:       "scrollUp",
+			twin.KeyDown:     "scrollDown",
+			twin.KeyEnter:    "scrollDown",
+			twin.KeyRight:    "scrollRight",
+			twin.KeyLeft:     "scrollLeft",
+			twin.KeyAltRight: "scrollRightOneColumn",
+			twin.KeyAltLeft:  "scrollLeftOneColumn",
+			twin.KeyHome:     "gotoStart",
+			twin.KeyEnd:      "gotoEnd",
+			twin.KeyPgUp:     "pageUp",
+			twin.KeyPgDown:   "pageDown",
+		},
+		runes: map[rune]string{
+			'q': "quit",
+			'v': "editFile",
+			'h': "showHelp",
+			'=': "toggleStatusBar",
+
+			// '\x10' = CTRL-p and '\x0e' = CTRL-n should scroll one line.
+			// Ref: https://github.com/walles/moor/issues/107#issuecomment-1328354080
+			'k':    "scrollUp",
+			'y':    "scrollUp",
+			'\x10': "scrollUp",
+			'j':    "scrollDown",
+			'e':    "scrollDown",
+			'\x0e': "scrollDown",
+
+			'<': "gotoStart",
+			'>': "gotoEnd",
+			'G': "gotoEnd",
+			'g': "gotoLine",
+			'F': "follow",
+			'z': "reposition",
+
+			'f':    "pageDown",
+			' ':    "pageDown",
+			'\x06': "pageDown", // CTRL-f
+			'b':    "pageUp",
+			'\x02': "pageUp", // CTRL-b
+
+			// CTRL-u and CTRL-d should work like just 'u' and 'd'.
+			// Ref: https://github.com/walles/moor/issues/90
+			'u':    "halfPageUp",
+			'\x15': "halfPageUp",
+			'd':    "halfPageDown",
+			'\x04': "halfPageDown",
+
+			'{': "previousParagraph",
+			'}': "nextParagraph",
+			'(': "previousPage",
+			')': "nextPage",
+			'%': "matchingBracket",
+			'W': "widestLine",
+
+			'/': "searchForward",
+			'?': "searchBackward",
+			'n': "searchAgain",
+			'p': "searchPrevious",
+			'N': "searchAgainReversed",
+			'U': "clearSearch", // Like ESC-u in less
+			'&': "filter",
+			'H': "highlight",
+			'|': "pipe",
+			':': "switchFile",
+
+			'c':  "copy",
+			'C':  "copyFileName",
+			'o':  "openUrl",
+			'm':  "setMark",
+			'\'': "jumpToMark",
+			'M':  "listMarks",
+
+			'w':    "toggleWrap",
+			's':    "toggleSqueezeBlankLines",
+			'E':    "toggleShowEscapes",
+			'D':    "toggleDimNonMatchingLines",
+			'r':    "toggleRuler", // Not 'R', that reloads like in less
+			'R':    "reload",
+			'#':    "cycleLineNumbers",
+			']':    "nextColumn",
+			'[':    "previousColumn",
+			'\x14': "cycleTabSize",      // CTRL-t
+			'\x01': "scrollToLeftEdge",  // CTRL-a
+			'\x05': "scrollToRightEdge", // CTRL-e
+		},
+	}
+}
+
+// Bind a key to an action. Keys are single characters like "j", control
+// characters like "ctrl-f", or special keys like "pgdown". Binding to "none"
+// makes the key do nothing.
+func (b KeyBindings) Bind(key string, action string) error {
+	if action != unboundAction {
+		if _, found := pagerActions[action]; !found {
+			return fmt.Errorf("Unknown action <%s>, must be one of: %s", action, strings.Join(actionNames(), ", "))
+		}
+	}
+
+	if keyCode, found := keyCodeNames[strings.ToLower(key)]; found {
+		b.keys[keyCode] = action
+		return nil
+	}
+
+	char, err := parseKeyRune(key)
+	if err != nil {
+		return err
+	}
+	if char >= '0' && char <= '9' {
+		return fmt.Errorf("Digits can't be rebound, they are for typing counts like in \"10j\"")
+	}
+	b.runes[char] = action
+	return nil
+}
+
+func parseKeyRune(key string) (rune, error) {
+	if strings.ToLower(key) == "space" {
+		// Can't type a plain space in a binding file
+		return ' ', nil
+	}
+
+	runes := []rune(key)
+	if len(runes) == 1 {
+		return runes[0], nil
+	}
+
+	lower := strings.ToLower(key)
+	if len(lower) == len("ctrl-x") && strings.HasPrefix(lower, "ctrl-") {
+		letter := lower[len(lower)-1]
+		if letter >= 'a' && letter <= 'z' {
+			return rune(letter - 'a' + 1), nil
+		}
+	}
+
+	return 0, fmt.Errorf("Unknown key <%s>, should be a single character, \"space\", \"ctrl-x\" or one of: %s", key, strings.Join(keyNames(), ", "))
+}
+
+func actionNames() []string {
+	names := []string{}
+	for name := range pagerActions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return append(names, unboundAction)
+}
+
+func keyNames() []string {
+	names := []string{}
+	for name := range keyCodeNames {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Returns nil if this key isn't bound to anything
+func (b KeyBindings) keyAction(keyCode twin.KeyCode) pagerAction {
+	return pagerActions[b.keys[keyCode]]
+}
+
+// Returns nil if this rune isn't bound to anything
+func (b KeyBindings) runeAction(char rune) pagerAction {
+	return pagerActions[b.runes[char]]
+}
+
+// Apply bindings from a text with one "key action" pair per line on top of
+// the default bindings. Empty lines and lines starting with # are ignored.
+func ParseKeyBindings(text string) (KeyBindings, error) {
+	bindings := DefaultKeyBindings()
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return KeyBindings{}, fmt.Errorf("Line %d: Expected \"key action\", got <%s>", lineNumber, line)
+		}
+
+		err := bindings.Bind(fields[0], fields[1])
+		if err != nil {
+			return KeyBindings{}, fmt.Errorf("Line %d: %w", lineNumber, err)
+		}
+	}
+
+	return bindings, scanner.Err()
+}
+
+// Load key bindings from a file. An empty file name means the moor/keys file
+// in the XDG config directory, and the defaults if that doesn't exist.
+func LoadKeyBindings(fileName string) (KeyBindings, error) {
+	if fileName == "" {
+		xdgPath, err := xdg.SearchConfigFile("moor/keys")
+		if err != nil {
+			// No bindings file, not a problem
+			log.Debug("No key bindings file found, using defaults: ", err)
+			return DefaultKeyBindings(), nil
+		}
+		fileName = xdgPath
+	}
+
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return KeyBindings{}, err
+	}
+
+	bindings, err := ParseKeyBindings(string(contents))
+	if err != nil {
+		return KeyBindings{}, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	log.Debug("Key bindings loaded from ", fileName)
+	return bindings, nil
+}
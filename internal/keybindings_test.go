@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+// Every default binding must point to an existing action
+func TestDefaultKeyBindingsAreValid(t *testing.T) {
+	defaults := DefaultKeyBindings()
+	for keyCode, action := range defaults.keys {
+		assert.Assert(t, defaults.keyAction(keyCode) != nil, "Key %v bound to unknown action %s", keyCode, action)
+	}
+	for char, action := range defaults.runes {
+		assert.Assert(t, defaults.runeAction(char) != nil, "Rune %q bound to unknown action %s", char, action)
+	}
+}
+
+func TestRebindRune(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	bindings, err := ParseKeyBindings("# Like in vi\nx scrollDown\n")
+	assert.NilError(t, err)
+	pager.KeyBindings = bindings
+
+	pager.mode.onRune('x')
+	assert.Equal(t, 1, pager.lineIndex().Index())
+
+	// Counts work for rebound keys too
+	typeRunes(pager, "3x")
+	assert.Equal(t, 4, pager.lineIndex().Index())
+
+	// The defaults are still there
+	pager.mode.onRune('k')
+	assert.Equal(t, 3, pager.lineIndex().Index())
+}
+
+func TestRebindKeyCode(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	bindings, err := ParseKeyBindings("down quit\nctrl-x gotoEnd\n")
+	assert.NilError(t, err)
+	pager.KeyBindings = bindings
+
+	pager.mode.onRune('\x18') // CTRL-x
+	assert.Equal(t, "line 20", screenRows(pager)[4])
+	assert.Assert(t, !pager.quit)
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Assert(t, pager.quit)
+}
+
+func TestUnbindKey(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	bindings, err := ParseKeyBindings("q none\nspace none")
+	assert.NilError(t, err)
+	pager.KeyBindings = bindings
+
+	pager.mode.onRune('q')
+	pager.mode.onRune(' ')
+	assert.Assert(t, !pager.quit)
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestRebindSearchNextInNotFound(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	bindings, err := ParseKeyBindings("x searchNext")
+	assert.NilError(t, err)
+	pager.KeyBindings = bindings
+
+	pager.searchString = "line 1"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.scrollToEnd()
+
+	pager.mode.onRune('x')
+	assert.Equal(t, "NotFound", modeName(pager))
+
+	// Pressing the search-next key again should wrap to the top
+	pager.mode.onRune('x')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestParseKeyBindingsUnknownAction(t *testing.T) {
+	_, err := ParseKeyBindings("j scrollDown\nx explode\n")
+	assert.ErrorContains(t, err, "Line 2: Unknown action <explode>")
+}
+
+func TestParseKeyBindingsUnknownKey(t *testing.T) {
+	_, err := ParseKeyBindings("pagedown pageDown")
+	assert.ErrorContains(t, err, "Line 1: Unknown key <pagedown>")
+}
+
+func TestParseKeyBindingsBadLine(t *testing.T) {
+	_, err := ParseKeyBindings("x")
+	assert.ErrorContains(t, err, "Line 1: Expected \"key action\"")
+}
+
+func TestParseKeyBindingsDigits(t *testing.T) {
+	_, err := ParseKeyBindings("5 quit")
+	assert.ErrorContains(t, err, "Digits can't be rebound")
+}
+
+func TestLoadKeyBindings(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "keys")
+	assert.NilError(t, os.WriteFile(fileName, []byte("x quit\n"), 0o600))
+
+	bindings, err := LoadKeyBindings(fileName)
+	assert.NilError(t, err)
+	assert.Equal(t, "quit", bindings.runes['x'])
+
+	assert.NilError(t, os.WriteFile(fileName, []byte("x nope\n"), 0o600))
+	_, err = LoadKeyBindings(fileName)
+	assert.ErrorContains(t, err, fileName+": Line 1: Unknown action <nope>")
+}
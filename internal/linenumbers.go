@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+)
+
+// The number to show in the line numbers column for this line.
+//
+// With RelativeLineNumbers, this is the distance from currentLine, like vim's
+// "relativenumber". currentLine itself gets its own number, so that you can
+// still tell where you are.
+func (p *Pager) lineNumberToShow(line reader.NumberedLine, currentLine *linemetadata.Index) linemetadata.Number {
+	if !p.RelativeLineNumbers || currentLine == nil {
+		return line.Number
+	}
+
+	distance := line.Index.Index() - currentLine.Index()
+	if distance < 0 {
+		distance = -distance
+	}
+	if distance == 0 {
+		return line.Number
+	}
+
+	// Format() shows one based numbers, so this makes it show the distance
+	return linemetadata.NumberFromOneBased(distance)
+}
+
+// Go from absolute line numbers to relative ones to none at all and back
+func (p *Pager) cycleLineNumbers() {
+	// Line numbers take space from wrapped lines, keep the same input line at
+	// the top of the screen even if wrapping changes
+	lineIndex := p.lineIndex()
+
+	switch {
+	case !p.ShowLineNumbers:
+		p.ShowLineNumbers = true
+		p.RelativeLineNumbers = false
+		p.setMessage("Showing line numbers")
+	case !p.RelativeLineNumbers:
+		p.RelativeLineNumbers = true
+		p.setMessage("Showing relative line numbers")
+	default:
+		p.ShowLineNumbers = false
+		p.RelativeLineNumbers = false
+		p.setMessage("Line numbers hidden")
+	}
+	if p.leftColumnZeroBased == 0 || !p.ShowLineNumbers {
+		// When scrolled right, line numbers come back when scrolling back to
+		// the left edge, see moveRight()
+		p.showLineNumbers = p.ShowLineNumbers
+	}
+
+	if lineIndex != nil {
+		p.scrollPosition = NewScrollPositionFromIndex(*lineIndex, "cycleLineNumbers")
+	}
+}
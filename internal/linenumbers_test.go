@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"gotest.tools/v3/assert"
+)
+
+func TestAbsoluteLineNumbers(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "b", "c", "d", "e", "f")
+	pager.showLineNumbers = true
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(2), "TestAbsoluteLineNumbers")
+
+	rows := screenRows(pager)
+	assert.Equal(t, "  3 c", rows[0])
+	assert.Equal(t, "  4 d", rows[1])
+	assert.Equal(t, "  6 f", rows[3])
+}
+
+func TestRelativeLineNumbers(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "b", "c", "d", "e", "f")
+	pager.showLineNumbers = true
+	pager.RelativeLineNumbers = true
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(2), "TestRelativeLineNumbers")
+
+	rows := screenRows(pager)
+	assert.Equal(t, "  3 c", rows[0], "Top line should show its own number")
+	assert.Equal(t, "  1 d", rows[1])
+	assert.Equal(t, "  2 e", rows[2])
+	assert.Equal(t, "  3 f", rows[3])
+}
+
+func TestRelativeLineNumbersFollowScrolling(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "b", "c", "d", "e", "f")
+	pager.showLineNumbers = true
+	pager.RelativeLineNumbers = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "  1 a", rows[0])
+	assert.Equal(t, "  1 b", rows[1])
+	assert.Equal(t, "  3 d", rows[3])
+
+	typeRunes(pager, "j")
+	rows = screenRows(pager)
+	assert.Equal(t, "  2 b", rows[0])
+	assert.Equal(t, "  1 c", rows[1])
+	assert.Equal(t, "  3 e", rows[3])
+}
+
+func TestCycleLineNumbers(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "b", "c", "d", "e", "f")
+	pager.showLineNumbers = true
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(1), "TestCycleLineNumbers")
+	assert.Equal(t, "  2 b", screenRows(pager)[0])
+
+	typeRunes(pager, "#")
+	assert.Assert(t, pager.RelativeLineNumbers)
+	rows := screenRows(pager)
+	assert.Equal(t, "  2 b", rows[0])
+	assert.Equal(t, "  1 c", rows[1])
+
+	// No gutter at all when hidden
+	typeRunes(pager, "#")
+	assert.Assert(t, !pager.ShowLineNumbers)
+	rows = screenRows(pager)
+	assert.Equal(t, "b", rows[0])
+	assert.Equal(t, "c", rows[1])
+
+	typeRunes(pager, "#")
+	assert.Assert(t, pager.ShowLineNumbers)
+	assert.Assert(t, !pager.RelativeLineNumbers)
+	rows = screenRows(pager)
+	assert.Equal(t, "  2 b", rows[0])
+	assert.Equal(t, "  3 c", rows[1])
+}
@@ -20,7 +20,7 @@ func rowsToString(cellLines []textstyles.CellWithMetadataSlice) string {
 	for _, cellLine := range cellLines {
 		lineString := ""
 		for _, cell := range cellLine {
-			lineString += string(cell.Rune)
+			lineString += string(cell.Rune) + cell.Combining()
 		}
 
 		if len(returnMe) > 0 {
@@ -168,3 +168,15 @@ func BenchmarkWrapLine(b *testing.B) {
 
 	b.StopTimer()
 }
+
+func TestWordWrapCombiningAccents(t *testing.T) {
+	// Each "e\u0301" is one screen cell wide
+	assertWrap(t, "e\u0301e\u0301e\u0301e\u0301", 4, "e\u0301e\u0301e\u0301e\u0301")
+	assertWrap(t, "e\u0301e\u0301e\u0301e\u0301", 3, "e\u0301e\u0301e\u0301", "e\u0301")
+	assertWrap(t, "cafe\u0301 au lait", 6, "cafe\u0301", "au", "lait")
+}
+
+func TestWordWrapCjk(t *testing.T) {
+	assertWrap(t, "日本語のテキスト", 6, "日本語", "のテキ", "スト")
+	assertWrap(t, "日本語のテキスト", 5, "日本", "語の", "テキ", "スト")
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"math"
+	"path/filepath"
 	"regexp"
 	"runtime/debug"
 	"sync"
@@ -39,6 +40,13 @@ type eventSpinnerUpdate struct {
 
 type eventMoreLinesAvailable struct{}
 
+// The search hit counter has made progress, redraw to show it
+type eventSearchHitsCounted struct{}
+
+// The current reader dropped its lines and is reading its file again from the
+// start
+type eventReaderReloaded struct{}
+
 // Either reading, highlighting or both are done. Check reader.Done() and
 // reader.HighlightingDone() for details.
 type eventMaybeDone struct{}
@@ -51,6 +59,9 @@ type Pager struct {
 
 	readerSwitched chan struct{}
 
+	// Scroll positions of files we have switched away from, by reader index
+	scrollPositions map[int]scrollPosition
+
 	// A view of the current reader, possibly filtered
 	filteringReader FilteringReader
 
@@ -67,11 +78,52 @@ type Pager struct {
 	searchString  string
 	searchPattern *regexp.Regexp
 
+	// One based column number to highlight, 0 for none. See ColumnDelimiter.
+	highlightedColumn int
+
+	// Direction of the last search, for 'n' / 'N' to repeat it
+	searchDirection SearchDirection
+
+	// If non-nil, overrides smart case for the search being typed. Toggled
+	// with CTRL-t while searching, reset when the search is dismissed.
+	searchCaseSensitive *bool
+
+	// If true, search strings are matched verbatim rather than as regexps.
+	// Toggled with CTRL-r while searching.
+	searchLiteral bool
+
+	// If true, searches only match whole words. Toggled with CTRL-w while
+	// searching.
+	searchWholeWords bool
+
 	// This should never be null while paging. Configured in NewPager().
 	searchHistory *SearchHistory
 
+	// Loaded on startup if RememberPositions is set
+	positionHistory *PositionHistory
+
+	// Digits typed in viewing mode before a motion key, as in "10j". Zero
+	// means no count has been typed.
+	countPrefix int
+
 	filterPattern *regexp.Regexp
 
+	// If true, the filter shows the lines not matching filterPattern. Typed
+	// as '!' before the filter pattern, like in less.
+	filterInverted bool
+
+	// Output from piping the current input through a command using '|',
+	// shown instead of the input. Nil when not piping.
+	pipedReader *reader.ReaderImpl
+
+	// Patterns highlighted in their own colors, in addition to the search.
+	// Added and removed with 'H'.
+	stickyHighlights []stickyHighlight
+
+	// Underlines all URLs with HighlightUrls, created on first use by
+	// urlHighlights()
+	urlUnderlines []reader.PatternHighlight
+
 	// We used to have a "Following" field here. If you want to follow, set
 	// TargetLineNumber to LineNumberMax() instead, see below.
 
@@ -84,22 +136,138 @@ type Pager struct {
 	// Current state, initialized in StartPaging()
 	showLineNumbers bool
 
+	// If true, line numbers show the distance from the top line rather than
+	// the position in the input, like vim's "relativenumber"
+	RelativeLineNumbers bool
+
+	// If true, show how much time has passed since the previous line, for
+	// lines starting with a timestamp. See TimestampFormats.
+	ShowTimeDeltas bool
+
+	// Layouts as for time.Parse() to look for at the start of lines when
+	// ShowTimeDeltas is set. Empty means a few common ones.
+	TimestampFormats []string
+
 	StatusBarStyle StatusBarOption
 	ShowStatusBar  bool
 
+	// If set, this replaces the default status bar text. See
+	// formatStatusBar() for the available placeholders.
+	StatusBarFormat string
+
 	UnprintableStyle textstyles.UnprintableStyleT
 
+	CarriageReturnStyle textstyles.CarriageReturnStyleT
+
 	WrapLongLines bool
 
+	// If non-zero, wrapping is turned on after the input has been read if at
+	// least this many percent of the lines are wider than the screen
+	AutoWrapPercent int
+	autoWrapDone    bool
+
+	// If true, runs of blank lines are shown as one single blank line, like
+	// "cat -s" does
+	SqueezeBlankLines bool
+
+	// If true, tabs are shown as '→' and trailing spaces as '·'
+	ShowWhitespace bool
+
+	// If true, a row of column markers is shown at the top of the screen, for
+	// counting columns in fixed width data
+	ShowRuler bool
+
+	// If true, the rightmost column shows where in the input we are, and
+	// which parts of it have search hits
+	ShowScrollbar bool
+
+	// If true, escape codes and other control characters are shown as text,
+	// like "cat -v" does, rather than being interpreted
+	ShowEscapes bool
+
+	// What to do when a search finds nothing or wraps around
+	SearchFeedback SearchFeedback
+
+	// Whether searching again at the end of the input restarts from the
+	// other end
+	SearchWrap SearchWrap
+
+	// How long to show "Not found" before going back to viewing mode. Zero
+	// means until the user does something.
+	NotFoundTimeout time.Duration
+
+	// Incremented every time we show "Not found", so that timeouts from
+	// earlier searches can be ignored
+	notFoundGeneration int
+
+	// Incremented every time we flash the screen, so that only the latest
+	// flash makes the screen normal again
+	flashGeneration int
+
+	// If true, the current search hit is shown with a few lines of context
+	// above the search prompt while searching. Toggle with CTRL-p.
+	SearchPreview bool
+
+	// If true, added, removed and hunk header lines of uncolored diffs get
+	// colors
+	ColorDiffs bool
+
+	// If true, http:// and https:// URLs are underlined. Open them with 'o'
+	// either way.
+	HighlightUrls bool
+
+	// What separates the columns highlighted using '[' and ']'. Empty means
+	// runs of whitespace, "\t" means tabs.
+	ColumnDelimiter string
+
+	// If true, lines without search hits are dimmed while there is a search.
+	// Like filtering, but keeping the other lines for context. Toggle with
+	// 'D'.
+	DimNonMatchingLines bool
+
+	// If true, the topmost line on screen gets a different background, to
+	// make it easier to keep track of where you are
+	HighlightCurrentLine bool
+
+	// What to do when scrolling down at the end of the input
+	AtEnd AtEnd
+
 	// Ref: https://github.com/walles/moor/issues/113
 	QuitIfOneScreen bool
 
+	// If true, reopening a file goes back to where the user was when
+	// quitting the last time. Ignored if some other start position is asked
+	// for.
+	RememberPositions bool
+
+	// If true, quitting requires pressing the quit key twice. Helps users
+	// who press 'q' by mistake.
+	ConfirmQuit bool
+
 	// Ref: https://github.com/walles/moor/issues/94
 	ScrollLeftHint  textstyles.CellWithMetadata
 	ScrollRightHint textstyles.CellWithMetadata
 
 	SideScrollAmount int // Left / right arrow keys scroll amount
 
+	ScrollStepLines int // Up / down arrow keys scroll amount in lines
+
+	WheelScrollAmount int // Mouse wheel scroll amount in lines
+
+	// When scrolling a full page, keep this many lines from the previous page
+	// on screen. Defaults to 1.
+	PageOverlap int
+
+	// If true, page scrolls are animated so that the eye can follow along
+	SmoothScroll bool
+
+	// If true, page scrolls stop at form feeds, like a printer would start a
+	// new page there
+	FormFeedPages bool
+
+	// Set while SmoothScroll is moving us towards some position
+	scrollAnimation *scrollAnimation
+
 	TabSize int // Number of spaces per tab, default 8, should be positive
 
 	// If non-nil, scroll to this line as soon as possible. Set this value to
@@ -123,11 +291,67 @@ type Pager struct {
 	// actual hits)
 	WithSearchHitLineBackground bool
 
+	// If set, search hits are highlighted using this style rather than the
+	// default reverse video. A style with only a background color set will
+	// retain the colors of the hits, and just change their background.
+	SearchHitStyle *twin.Style
+
+	// User configured UI colors. SearchHitStyle wins over the theme's search
+	// hit colors.
+	Theme Theme
+
+	// If set, jumping to a search hit puts the hit this many rows from the top
+	// of the screen. If nil, search hits are centered vertically.
+	SearchJumpOffset *int
+
+	// Counts the search hits in the background, use getSearchHitCounter()
+	searchHitCounter *searchHitCounter
+
+	// The search hit we last moved to, for telling which hit that was. Nil if
+	// we haven't moved to any hit, or if the user has scrolled away since.
+	currentSearchHit *linemetadata.Index
+
+	// Like scrolloff in Vim. When jumping to a search hit or a line number,
+	// try to keep at least this many rows of context above and below it.
+	ScrollOff int
+
+	// Like --header in less. This many lines from the start of the input stay
+	// at the top of the screen while the rest scrolls beneath them.
+	HeaderLines int
+
+	// If set, the current position is written to this file or named pipe
+	// after every redraw, for tmux status bars and the like
+	StatusFile       string
+	statusFileWriter *statusFileWriter
+
+	// Like +G in less: Start at the end of the input. Unlike following, we
+	// stop scrolling down once all input has been read.
+	StartAtEnd      bool
+	followUntilDone bool
+
+	// Like +/pattern in less: Start at the first hit for this search
+	InitialSearch string
+
+	// Where to continue looking for InitialSearch hits as more lines come in,
+	// nil if we're not looking
+	initialSearchFrom *linemetadata.Index
+
+	// Set when InitialSearch has found a hit
+	initialSearchFound bool
+
+	// If InitialSearch finds nothing, ExitCode() returns this, for scripts
+	// using us like grep. Zero by default.
+	NotFoundExitCode int
+
+	// Which keys do what in viewing mode. Defaults to DefaultKeyBindings().
+	KeyBindings KeyBindings
+
 	// Length of the longest line displayed. This is used for limiting scrolling
 	// to the right.
 	longestLineLength int
 
-	// Bookmarks that you can come back to.
+	// Bookmarks that you can come back to. The ' (single quote) mark is set
+	// automatically before large jumps, so that '' takes you back.
 	//
 	// Ref: https://github.com/walles/moor/issues/175
 	bookmarks map[rune]scrollPosition
@@ -148,9 +372,20 @@ Miscellaneous
 -------------
 * Press 'q' or 'ESC' to quit
 * Press 'w' to toggle wrapping of long lines
+* Press 's' to toggle squeezing runs of blank lines into one
+* Press 'E' to toggle showing escape codes as text, like "cat -v"
+* Press 'r' to toggle a column ruler at the top of the screen
+* Press '#' to switch between absolute, relative and no line numbers
+* Press ']' / '[' to highlight the next / previous column of a table
 * Press '=' to toggle showing the status bar at the bottom
 * Press 'v' to edit the file in your favorite editor
+* Press 'o' to open a URL on screen in your browser, 'o' again for the next one
+* Press 'R' to reload the file, picking up any changes to it
+* Press 'cc' to copy the top line to the clipboard, or 'c' plus a mark letter
+  to copy the lines from that mark to the top line
+* Press 'C' to copy the full path of the current file to the clipboard
 * Press CTRL-t to change the tab size
+* Keys can be rebound, see the --keybindings option in "moor --help"
 
 Moving around
 -------------
@@ -158,26 +393,52 @@ Moving around
 * Alt key plus left / right arrow steps one column at a time
 * Left / right can be used to hide / show line numbers
 * Home and End for start / end of the document
-* 'g' for going to a specific line number
+* 'g' for going to a specific line number, a percentage like "50%" or a byte
+  offset like "b1234"
+* Type a number before a motion key to repeat it, like "10j". "5G" goes to
+  line 5 and "3n" goes to the third next search hit.
+* 'zt', 'zz' and 'zb' move the current line to the top, center or bottom of
+  the screen. "15zz" centers line 15.
 * 'm' sets a mark, you will be asked for a letter to label it with
 * ' (single quote) jumps to the mark
+* '' (two single quotes) jumps back to before the last search, goto or jump
+* 'M' lists all marks, for jumping to or removing them
 * CTRL-p moves to the previous line
 * CTRL-n moves to the next line
-* PageUp / 'b' and PageDown / 'f'
+* PageUp / 'b' / CTRL-b and PageDown / 'f' / CTRL-f, keeping one line of
+  the previous page on screen
 * SPACE moves down a page
 * < / 'gg' to go to the start of the document
 * > / 'G' to go to the end of the document
+* 'F' to go to the end of the document and follow any new lines
 * Half page 'u'p / 'd'own, or CTRL-u / CTRL-d
-* CTRL-a moves to the leftmost position
+* '{' / '}' to go to the previous / next blank line between paragraphs
+* '(' / ')' to go to the previous / next form feed page break
+* '%' to go to the bracket matching the first bracket on the top line
+* 'W' to go to the widest line
+* CTRL-a moves to the leftmost position, CTRL-e to the end of the widest line
 * RETURN moves down one line
 
 Switching files (if you opened multiple files)
 ----------------------------------------------
 * Press ':' to enter file switching mode
 
+Highlighting
+------------
+Type 'H' and a pattern to keep highlighting it in its own color, independent
+of the search. Add more patterns by typing 'H' again.
+
+Type 'H' and an already highlighted pattern to remove it.
+
+Piping
+------
+Type '|' and a shell command, like "sort", to show the input piped through
+that command. Submit an empty command to restore the original input.
+
 Filtering
 ---------
-Type '&' to start filtering, then type your filter expression.
+Type '&' to start filtering, then type your filter expression. Start the
+expression with '!' to show only the lines not matching it.
 
 While filtering, arrow keys, PageUp, PageDown, Home and End work as usual.
 
@@ -189,10 +450,17 @@ Searching
 * Type ? to search backwards, then type what you want to find
 * Type RETURN to stop searching, or ESC to skip back to where the search started
 * Press up / down arrows while searching to access search history
-* Find next by typing 'n' (for "next")
-* Find previous by typing SHIFT-N or 'p' (for "previous")
+* Find next by typing 'n' (for "next"), in the same direction as the search
+* SHIFT-N finds the next hit in the opposite direction
+* Find previous by typing 'p' (for "previous")
 * Search is case sensitive if it contains any UPPER CASE CHARACTERS
+* Press CTRL-t while searching to toggle case sensitivity
 * Search is interpreted as a regexp if it is a valid one
+* Search hits stay highlighted after searching, press 'U' to clear them
+* Press 'D' to dim lines without search hits, like filtering but keeping the
+  other lines in view
+* Press CTRL-r while searching to toggle between regexp and literal search
+* Press CTRL-w while searching to only match whole words
 
 Reporting bugs
 --------------
@@ -227,22 +495,28 @@ func NewPager(readers ...*reader.ReaderImpl) *Pager {
 		currentReader:               0,
 		readerSwitched:              make(chan struct{}, 1),
 		quit:                        false,
-		ShowLineNumbers:             true, // Constant throghout the lifetime of the pager
+		ShowLineNumbers:             true, // Changed by the user pressing '#'
 		showLineNumbers:             true, // Will be updated over time
 		ShowStatusBar:               true,
 		DeInit:                      true,
 		SideScrollAmount:            16,
+		ScrollStepLines:             1,
+		WheelScrollAmount:           1,
+		PageOverlap:                 1,
 		TabSize:                     8, // This is what less defaults to
 		ScrollLeftHint:              textstyles.CellWithMetadata{Rune: '<', Style: twin.StyleDefault.WithAttr(twin.AttrReverse)},
 		ScrollRightHint:             textstyles.CellWithMetadata{Rune: '>', Style: twin.StyleDefault.WithAttr(twin.AttrReverse)},
 		scrollPosition:              newScrollPosition(name),
 		WithSearchHitLineBackground: true,
+		bookmarks:                   make(map[rune]scrollPosition),
+		KeyBindings:                 DefaultKeyBindings(),
 	}
 
 	pager.mode = PagerModeViewing{pager: &pager}
 	pager.filteringReader = FilteringReader{
-		BackingReader: readers[0], // Always start with the first reader
-		FilterPattern: &pager.filterPattern,
+		BackingReader:  readers[0], // Always start with the first reader
+		FilterPattern:  &pager.filterPattern,
+		FilterInverted: &pager.filterInverted,
 	}
 
 	searchHistory := BootSearchHistory("")
@@ -251,9 +525,26 @@ func NewPager(readers ...*reader.ReaderImpl) *Pager {
 	return &pager
 }
 
-// How many lines are visible on screen? Depends on screen height and whether or
-// not the status bar is visible.
+// How many scrollable lines are visible on screen? Depends on screen height,
+// whether or not the status bar is visible and on how many header lines there
+// are.
 func (p *Pager) visibleHeight() int {
+	return p.contentsHeight() - p.headerLineCount()
+}
+
+// How many screen rows are there for header lines and scrollable lines
+// together?
+func (p *Pager) contentsHeight() int {
+	if p.isShowingRuler() {
+		return p.rowsAboveStatusBar() - 1
+	}
+
+	return p.rowsAboveStatusBar()
+}
+
+// How many screen rows are there for the ruler, header lines and scrollable
+// lines together?
+func (p *Pager) rowsAboveStatusBar() int {
 	_, height := p.screen.Size()
 
 	// Only the viewing mode can be without status bar
@@ -266,16 +557,30 @@ func (p *Pager) visibleHeight() int {
 	return height
 }
 
-// How many cells are needed for this line number? Includes padding.
+// How many cells are needed for this line number? Includes padding and any
+// time deltas gutter.
 //
-// Returns 0 if line numbers are disabled.
+// Returns 0 if line numbers and time deltas are disabled.
 func (p *Pager) getLineNumberPrefixLength(lineNumber linemetadata.Number) int {
+	return p.timeDeltasWidth() + p.getLineNumberLength(lineNumber)
+}
+
+// Like getLineNumberPrefixLength(), but for the line number only
+func (p *Pager) getLineNumberLength(lineNumber linemetadata.Number) int {
 	if !p.showLineNumbers {
 		return 0
 	}
 
 	length := len(lineNumber.Format()) + 1 // +1 for the space after the line number
 
+	// Make room for the last line number of the input, so that the line
+	// numbers column doesn't change width while scrolling. Filtered lines
+	// keep their original numbers, that's why we still look at lineNumber.
+	lineCount := p.Reader().GetLineCount()
+	if lineCount > 0 {
+		length = max(length, len(linemetadata.NumberFromOneBased(lineCount).Format())+1)
+	}
+
 	if length < 4 {
 		// 4 = space for 3 digits followed by one whitespace
 		//
@@ -358,6 +663,12 @@ func (p *Pager) Quit() {
 	p.preHelpState = nil
 }
 
+// Remember the current position before jumping somewhere far away, so that
+// pressing ' twice can take the user back here.
+func (p *Pager) rememberPosition() {
+	p.bookmarks['\''] = p.scrollPosition
+}
+
 // Negative deltas move left instead
 func (p *Pager) moveRight(delta int) {
 	if p.showLineNumbers && delta > 0 {
@@ -384,6 +695,91 @@ func (p *Pager) moveRight(delta int) {
 	}
 }
 
+// Scroll right just far enough for the end of the widest line on screen to be
+// visible
+func (p *Pager) scrollToRightEdge() {
+	rendered := p.renderLines()
+	widest := widestLineWidth(rendered, p.ShowEscapes)
+
+	screenWidth := p.contentWidth()
+	if rendered.numberPrefixWidth+widest <= screenWidth {
+		// Everything fits already
+		p.leftColumnZeroBased = 0
+		return
+	}
+
+	// Just like in moveRight(), line numbers go away when scrolling right
+	p.showLineNumbers = false
+	p.leftColumnZeroBased = widest - (screenWidth - p.timeDeltasWidth())
+}
+
+// Screen columns needed to show all of the widest line on screen, not counting
+// line numbers or trailing whitespace
+func widestLineWidth(rendered renderedScreen, showEscapes bool) int {
+	onScreen := map[linemetadata.Index]bool{}
+	for _, line := range rendered.lines {
+		onScreen[line.inputLineIndex] = true
+	}
+
+	widest := 0
+	for _, line := range rendered.inputLines {
+		if !onScreen[line.Index] {
+			continue
+		}
+
+		if showEscapes {
+			line.Line = line.Line.WithEscapesShown()
+		}
+
+		cells := textstyles.CellWithMetadataSlice(line.Line.HighlightedTokens(plainTextStyle, searchHitStyle, nil, nil).StyledRunes)
+		width := 0
+		for _, cell := range cells.WithoutSpaceRight() {
+			width += cell.Width()
+		}
+		widest = max(widest, width)
+	}
+
+	return widest
+}
+
+func (p *Pager) handleMouseEvent(event twin.EventMouse) {
+	switch event.Buttons() {
+	case twin.MouseWheelUp:
+		// Clipping is done in _Redraw()
+		p.scrollPosition = p.scrollPosition.PreviousLine(p.WheelScrollAmount)
+		p.handleScrolledUp()
+
+	case twin.MouseWheelDown:
+		if p.handleAtEnd() {
+			return
+		}
+
+		// Clipping is done in _Redraw()
+		p.scrollPosition = p.scrollPosition.NextLine(p.WheelScrollAmount)
+		p.handleScrolledDown()
+
+	case twin.MouseWheelLeft:
+		p.moveRight(-p.SideScrollAmount)
+
+	case twin.MouseWheelRight:
+		p.moveRight(p.SideScrollAmount)
+
+	case twin.MouseButtonPrimary:
+		// Clicks in the contents area are ignored. Clicking the status bar
+		// dismisses any message shown there.
+		_, y := event.Position()
+		_, height := p.screen.Size()
+		if y != height-1 {
+			return
+		}
+
+		switch p.mode.(type) {
+		case *PagerModeInfo, PagerModeNotFound:
+			p.mode = PagerModeViewing{pager: p}
+		}
+	}
+}
+
 func (p *Pager) Reader() reader.Reader {
 	if p.isShowingHelp {
 		return _HelpReader
@@ -392,10 +788,12 @@ func (p *Pager) Reader() reader.Reader {
 }
 
 func (p *Pager) handleScrolledUp() {
+	p.currentSearchHit = nil
 	p.setTargetLine(nil)
 }
 
 func (p *Pager) handleScrolledDown() {
+	p.currentSearchHit = nil
 	if p.isScrolledToEnd() {
 		// Follow output
 		reallyHigh := linemetadata.IndexMax()
@@ -414,6 +812,11 @@ func (p *Pager) setTargetLine(targetLine *linemetadata.Index) {
 
 	log.Trace("Pager: Setting target line to ", targetLine, "...")
 	p.TargetLine = targetLine
+
+	// Old lines may only go away while we're following the end of the input,
+	// otherwise they would move under the user's feet
+	r.SetDropOldLines(targetLine != nil && *targetLine == linemetadata.IndexMax())
+
 	if targetLine == nil {
 		// No target, just do your thing
 		r.SetPauseAfterLines(reader.DEFAULT_PAUSE_AFTER_LINES)
@@ -440,8 +843,18 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 	defer func() {
 		p.readerLock.Lock()
 		r := p.readers[p.currentReader]
+		for _, input := range p.readers {
+			// Don't keep downloading after we're gone
+			input.Cancel()
+		}
 		p.readerLock.Unlock()
 
+		p.savePositions()
+
+		if p.statusFileWriter != nil {
+			p.statusFileWriter.stop()
+		}
+
 		if r.Err != nil {
 			log.Warnf("Reader reported an error: %s", r.Err.Error())
 		}
@@ -450,18 +863,31 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 	p.showLineNumbers = p.ShowLineNumbers
 
 	textstyles.UnprintableStyle = p.UnprintableStyle
+	textstyles.CarriageReturnStyle = p.CarriageReturnStyle
 	if p.TabSize > 0 {
 		// "0" = unset, stay at the default. If the tab size is negative, just
 		// ignoring it seems like the right move.
 		textstyles.TabSize = p.TabSize
 	}
 	consumeLessTermcapEnvs(screen.TerminalBackground(), chromaStyle, chromaFormatter)
+	customSearchHitStyle = p.SearchHitStyle
+	if customSearchHitStyle == nil {
+		customSearchHitStyle = p.Theme.searchHitStyle()
+	}
 	styleUI(screen.TerminalBackground(), chromaStyle, chromaFormatter, p.StatusBarStyle, p.WithTerminalFg, p.WithSearchHitLineBackground)
+	p.Theme.apply()
+	withoutColors = false
+	if colorCounter, ok := screen.(twin.ColorCounter); ok && colorCounter.ColorCount() == twin.ColorCountNone {
+		styleWithoutColors()
+	}
 
 	p.screen = screen
 	p.mode = PagerModeViewing{pager: p}
 	p.bookmarks = make(map[rune]scrollPosition)
 
+	p.restorePosition()
+	p.applyStartupPosition()
+
 	// Make sure the reader knows how many lines we want
 	p.setTargetLine(p.TargetLine)
 
@@ -490,11 +916,8 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 			select {
 			case <-p.readerSwitched:
 				// A different reader is now active
-				p.filterPattern = nil
-
 				p.readerLock.Lock()
 				r = p.readers[p.currentReader]
-				p.filteringReader.SetBackingReader(r)
 				p.readerLock.Unlock()
 
 				// Look in the right place for more lines
@@ -542,6 +965,9 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 
 			case <-r.MaybeDone:
 				screen.Events() <- eventMaybeDone{}
+
+			case <-r.Reloaded:
+				screen.Events() <- eventReaderReloaded{}
 			}
 		}
 	}()
@@ -589,50 +1015,57 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 		switch event := event.(type) {
 		case twin.EventKeyCode:
 			log.Tracef("Handling key event %d...", event.KeyCode())
+			p.finishScrollAnimation()
 			p.mode.onKey(event.KeyCode())
 
 		case twin.EventRune:
 			log.Tracef("Handling rune event '%c'/0x%04x...", event.Rune(), event.Rune())
+			p.finishScrollAnimation()
 			p.mode.onRune(event.Rune())
 
 		case twin.EventMouse:
 			log.Tracef("Handling mouse event %d...", event.Buttons())
-			switch event.Buttons() {
-			case twin.MouseWheelUp:
-				// Clipping is done in _Redraw()
-				p.scrollPosition = p.scrollPosition.PreviousLine(1)
-
-			case twin.MouseWheelDown:
-				// Clipping is done in _Redraw()
-				p.scrollPosition = p.scrollPosition.NextLine(1)
-
-			case twin.MouseWheelLeft:
-				p.moveRight(-p.SideScrollAmount)
-
-			case twin.MouseWheelRight:
-				p.moveRight(p.SideScrollAmount)
-			}
+			p.finishScrollAnimation()
+			p.handleMouseEvent(event)
 
 		case twin.EventResize:
 			// We'll be implicitly redrawn just by taking another lap in the loop
+			p.finishScrollAnimation()
+			p.handleResize()
 
 		case twin.EventExit:
 			log.Info("Got a Twin exit event, exiting")
 			return
 
 		case eventMoreLinesAvailable:
-			if p.TargetLine != nil {
-				// The user wants to scroll down to a specific line number
-				if linemetadata.IndexFromLength(p.Reader().GetLineCount()).IsBefore(*p.TargetLine) {
-					// Not there yet, keep scrolling
-					p.scrollToEnd()
-				} else {
-					// We see the target, scroll to it
-					p.scrollPosition = NewScrollPositionFromIndex(*p.TargetLine, "goToTargetLine")
-					p.setTargetLine(nil)
-				}
+			p.handleMoreLinesAvailable()
+			p.continueInitialSearch()
+
+		case eventReaderReloaded:
+			p.handleReaderReloaded()
+
+		case eventSearchDebounced:
+			if event.mode == p.mode {
+				event.mode.onSearchDebounced(event.generation)
+			}
+
+		case eventScrollAnimationFrame:
+			p.onScrollAnimationFrame(event.animation)
+
+		case eventNotFoundTimeout:
+			p.onNotFoundTimeout(event.generation)
+
+		case eventFlashDone:
+			p.onFlashDone(event.generation)
+
+		case eventPipeDone:
+			if event.mode == p.mode {
+				event.mode.onPipeDone()
 			}
 
+		case eventSearchHitsCounted:
+			// We'll be implicitly redrawn just by taking another lap in the loop
+
 		case eventMaybeDone:
 			// Man pages come pre-formatted for the screen width, and line
 			// numbers will mess that up. So we disable line numbers if we
@@ -646,6 +1079,8 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 				log.Info("man page detected by contents, disabling line numbers")
 			}
 
+			p.handleReadingMaybeDone()
+
 		case eventSpinnerUpdate:
 			spinner = event.spinner
 
@@ -655,6 +1090,122 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 	}
 }
 
+// Scroll towards TargetLine as new lines come in. If TargetLine is IndexMax(),
+// this will keep us at the end of the input.
+func (p *Pager) handleMoreLinesAvailable() {
+	if p.TargetLine == nil {
+		return
+	}
+
+	// The user wants to scroll down to a specific line number
+	if linemetadata.IndexFromLength(p.Reader().GetLineCount()).IsBefore(*p.TargetLine) {
+		// Not there yet, keep scrolling
+		p.scrollToEnd()
+	} else {
+		// We see the target, scroll to it
+		targetLine := *p.TargetLine
+		p.scrollPosition = NewScrollPositionFromIndex(targetLine, "goToTargetLine")
+		p.applyScrollOff(targetLine)
+		p.setTargetLine(nil)
+	}
+}
+
+// Read the current file again from the start, to pick up any changes to it.
+// Once that starts, handleReaderReloaded() keeps us in place.
+func (p *Pager) reloadFile() {
+	p.readerLock.Lock()
+	r := p.readers[p.currentReader]
+	p.readerLock.Unlock()
+
+	if r.FileName == nil {
+		p.setMessage("Can't reload, input is not from a file")
+		return
+	}
+
+	name := filepath.Base(*r.FileName)
+	if !r.Reload() {
+		p.setMessage("Can't reload " + name)
+		return
+	}
+
+	p.setMessage("Reloading " + name)
+}
+
+// The file we're showing was truncated or replaced, and is being read again.
+//
+// If we were following the end of the input, we keep doing that. Otherwise we
+// try to stay on the same line number. If the new contents are shorter than
+// that, we end up at the end of them.
+func (p *Pager) handleReaderReloaded() {
+	if p.TargetLine != nil && *p.TargetLine == linemetadata.IndexMax() {
+		// Following, stay at the end
+		p.scrollToEnd()
+		return
+	}
+
+	// Not canonicalizing here, that would clamp our position to however many
+	// lines have been re-read so far
+	lineIndex := p.scrollPosition.internalDontTouch.lineIndex
+	if lineIndex == nil {
+		// We weren't showing anything, start from the top
+		return
+	}
+
+	// Get as close to the old position as we can, then move on to it if more
+	// lines come in
+	targetLine := *lineIndex
+	p.setTargetLine(&targetLine)
+	p.handleMoreLinesAvailable()
+}
+
+// The screen changed size. Keep the same input line at the top of the screen.
+//
+// Without this, a wrapped line getting fewer sub lines on a wider screen could
+// push the next input line to the top.
+func (p *Pager) handleResize() {
+	if p.isFollowing() {
+		p.scrollToEnd()
+		return
+	}
+
+	// Not canonicalizing here, that would be done for the new screen size
+	lineIndex := p.scrollPosition.internalDontTouch.lineIndex
+	deltaScreenLines := p.scrollPosition.internalDontTouch.deltaScreenLines
+	if lineIndex == nil || deltaScreenLines == 0 {
+		// Already showing the start of the top line
+		return
+	}
+
+	line := p.Reader().GetLine(*lineIndex)
+	if line == nil {
+		return
+	}
+
+	// Stay on the same sub line if it's still there, otherwise go to the last
+	// one of the same input line
+	subLines := p.renderLine(*line, p.getLineNumberPrefixLength(line.Number), true, nil)
+	p.scrollPosition = scrollPosition{
+		internalDontTouch: scrollPositionInternal{
+			name:             "handleResize",
+			lineIndex:        lineIndex,
+			deltaScreenLines: max(0, min(deltaScreenLines, len(subLines)-1)),
+		},
+	}
+}
+
+// Jump to the end of the input and stay there as more lines arrive. Scrolling
+// up stops following, scrolling back down to the end resumes it.
+func (p *Pager) startFollowing() {
+	reallyHigh := linemetadata.IndexMax()
+	p.setTargetLine(&reallyHigh)
+	p.scrollToEnd()
+}
+
+// True if we're following the end of the input, see startFollowing()
+func (p *Pager) isFollowing() bool {
+	return p.TargetLine != nil && *p.TargetLine == linemetadata.IndexMax()
+}
+
 // The height parameter is the terminal height minus the height of the user's
 // shell prompt.
 //
@@ -692,6 +1243,7 @@ func (p *Pager) fitsOnOneScreenWrapped() bool {
 	fakePager.showLineNumbers = false
 
 	fakePager.WrapLongLines = p.WrapLongLines
+	fakePager.SqueezeBlankLines = p.SqueezeBlankLines
 	fakePager.ShowStatusBar = false // We are only interested in content lines
 	fakePager.TabSize = p.TabSize
 
@@ -707,7 +1259,8 @@ func (p *Pager) fitsOnOneScreen() bool {
 		return false
 	}
 
-	if p.WrapLongLines {
+	if p.WrapLongLines || p.SqueezeBlankLines {
+		// Line counts don't match screen rows any more, render to find out
 		return p.fitsOnOneScreenWrapped()
 	}
 
@@ -739,7 +1292,7 @@ func (p *Pager) fitsOnOneScreen() bool {
 func (p *Pager) ReprintAfterExit() {
 	// Figure out how many screen lines are used by pager contents
 	renderedScreen := p.renderLines()
-	screenLinesCount := len(renderedScreen.lines)
+	screenLinesCount := len(renderedScreen.ruler) + len(renderedScreen.headerLines) + len(renderedScreen.lines)
 
 	_, screenHeight := p.screen.Size()
 	screenHeightWithoutFooter := screenHeight - p.DeInitFalseMargin
@@ -1,11 +1,13 @@
 package internal
 
 import (
+	"io"
 	"os"
 	"path"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
@@ -673,3 +675,494 @@ func TestTerminalFg(t *testing.T) {
 	assertRunesEqual(t, styleAnswer, startPagingWithTerminalFg(t, reader, false).GetRow(0)[0])
 	assertRunesEqual(t, terminalAnswer, startPagingWithTerminalFg(t, reader, true).GetRow(0)[0])
 }
+
+// Wait for the reader to have at least this many lines
+func awaitLineCount(t *testing.T, reader *reader.ReaderImpl, lineCount int) {
+	for range 50 {
+		if reader.GetLineCount() >= lineCount {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d lines, got %d", lineCount, reader.GetLineCount())
+}
+
+// 'F' should take us to the end and start following in one go, like in less
+func TestFollowKey(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	assert.Assert(t, !pager.isFollowing())
+
+	pager.mode.onRune('F')
+	assert.Assert(t, pager.isScrolledToEnd())
+	assert.Assert(t, pager.isFollowing())
+	assert.Equal(t, "Following", pager.statusModeName())
+
+	// Scrolling up stops following
+	pager.mode = PagerModeViewing{pager: pager}
+	pager.mode.onRune('k')
+	assert.Assert(t, !pager.isScrolledToEnd())
+	assert.Assert(t, !pager.isFollowing())
+	assert.Equal(t, "Viewing", pager.statusModeName())
+}
+
+func TestFollowGrowingInput(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close() //nolint:errcheck
+
+	// NewFromStream() wants some bytes to look at before returning
+	go func() {
+		_, _ = pipeWriter.Write([]byte("1\n2\n3\n4\n5\n"))
+	}()
+
+	reader, err := reader.NewFromStream("", pipeReader, formatters.TTY16m, reader.ReaderOptions{})
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 5)
+
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(20, 4)
+
+	pager.mode.onRune('F')
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	// More lines coming in should keep us at the end
+	_, err = pipeWriter.Write([]byte("6\n7\n"))
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 7)
+	pager.handleMoreLinesAvailable()
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	// Scrolling up should stop following
+	pager.mode.onKey(twin.KeyUp)
+	_, err = pipeWriter.Write([]byte("8\n"))
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 8)
+	pager.handleMoreLinesAvailable()
+	assert.Assert(t, !pager.isScrolledToEnd())
+
+	// Scrolling back down to the end should resume following
+	for !pager.isScrolledToEnd() {
+		pager.mode.onKey(twin.KeyDown)
+	}
+	_, err = pipeWriter.Write([]byte("9\n"))
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 9)
+	pager.handleMoreLinesAvailable()
+	assert.Assert(t, pager.isScrolledToEnd())
+	assert.Equal(t, "9", pager.renderLines().inputLines[2].Plain())
+}
+
+// The line count and percentage aren't final until the input has been read
+func TestStatusBarWhileLoading(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	// NewFromStream() wants some bytes to look at before returning
+	go func() {
+		_, _ = pipeWriter.Write([]byte("a\nb\n"))
+	}()
+
+	reader, err := reader.NewFromStream("", pipeReader, formatters.TTY16m, reader.ReaderOptions{})
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 2)
+
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(50, 4)
+	pager.ShowLineNumbers = false
+	pager.showLineNumbers = false
+
+	assert.Assert(t, strings.HasPrefix(screenRows(pager)[3], "loading… 2 lines  100%"), screenRows(pager)[3])
+
+	assert.NilError(t, pipeWriter.Close())
+	for range 50 {
+		if !reader.IsLoading() {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Assert(t, strings.HasPrefix(screenRows(pager)[3], "2 lines  100%"), screenRows(pager)[3])
+}
+
+// Like tail -f | grep
+func TestFollowFiltered(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close() //nolint:errcheck
+
+	// NewFromStream() wants some bytes to look at before returning
+	go func() {
+		_, _ = pipeWriter.Write([]byte("ok 1\nnoise 2\nok 3\n"))
+	}()
+
+	reader, err := reader.NewFromStream("", pipeReader, formatters.TTY16m, reader.ReaderOptions{})
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 3)
+
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(50, 4)
+	pager.ShowLineNumbers = false
+	pager.showLineNumbers = false
+
+	typeFilter(pager, "ok")
+	pager.mode.onKey(twin.KeyEnter)
+	pager.mode.onRune('F')
+	assert.Equal(t, "Following, filtered", pager.statusModeName())
+
+	// Only matching lines should show up, and we should stay at the end
+	_, err = pipeWriter.Write([]byte("noise 4\nok 5\nnoise 6\nok 7\nnoise 8\n"))
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 8)
+	pager.handleMoreLinesAvailable()
+	pager.mode = PagerModeViewing{pager: pager}
+
+	rows := screenRows(pager)
+	assert.DeepEqual(t, rows[:3], []string{"ok 3", "ok 5", "ok 7"})
+	assert.Assert(t, strings.HasPrefix(rows[3], "Filtered: loading… 4/8 lines  100%  (FOLLOWING)"), rows[3])
+	assert.Equal(t, "Following, filtered", pager.statusModeName())
+}
+
+func TestHorizontalScrolling(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "0123456789abcdefghij\nshort"))
+	pager.ShowLineNumbers = false
+	pager.showLineNumbers = false
+	pager.SideScrollAmount = 5
+	screen := twin.NewFakeScreen(10, 3)
+	pager.screen = screen
+	pager.redraw("")
+
+	pager.mode.onKey(twin.KeyRight)
+	assert.Equal(t, 5, pager.leftColumnZeroBased)
+
+	pager.redraw("")
+	assert.Equal(t, "<6789abcd>", rowToString(screen.GetRow(0)))
+	assert.Equal(t, "<", rowToString(screen.GetRow(1)))
+
+	// Don't scroll past the end of the longest line
+	for range 10 {
+		pager.mode.onKey(twin.KeyRight)
+		pager.redraw("")
+	}
+	rightmost := pager.leftColumnZeroBased
+	assert.Assert(t, rightmost < 20, rightmost)
+	pager.mode.onKey(twin.KeyRight)
+	assert.Equal(t, rightmost, pager.leftColumnZeroBased)
+
+	// Don't scroll past the start of the lines
+	for range 10 {
+		pager.mode.onKey(twin.KeyLeft)
+	}
+	assert.Equal(t, 0, pager.leftColumnZeroBased)
+
+	// Scrolling left from column 0 brings the line numbers back
+	pager.redraw("")
+	assert.Equal(t, "  1 01234>", rowToString(screen.GetRow(0)))
+}
+
+func TestHorizontalHomeEnd(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "0123456789abcdefghij\nshort"))
+	pager.ShowLineNumbers = true
+	pager.showLineNumbers = true
+	screen := twin.NewFakeScreen(10, 3)
+	pager.screen = screen
+	pager.redraw("")
+
+	// End shows the end of the widest line at the right edge of the screen
+	pager.mode.onRune('\x05') // CTRL-e
+	assert.Equal(t, 10, pager.leftColumnZeroBased)
+	assert.Assert(t, !pager.showLineNumbers)
+	pager.redraw("")
+	assert.Equal(t, "<bcdefghij", rowToString(screen.GetRow(0)))
+
+	// Pressing end again changes nothing
+	pager.mode.onRune('\x05') // CTRL-e
+	assert.Equal(t, 10, pager.leftColumnZeroBased)
+
+	// Home goes back to the start, with line numbers
+	pager.mode.onRune('\x01') // CTRL-a
+	assert.Equal(t, 0, pager.leftColumnZeroBased)
+	assert.Assert(t, pager.showLineNumbers)
+	pager.redraw("")
+	assert.Equal(t, "  1 01234>", rowToString(screen.GetRow(0)))
+}
+
+// Only lines that are on screen count when finding the end
+func TestHorizontalEndVisibleLinesOnly(t *testing.T) {
+	pager := createLinesPager(t, 10, 3, "short", "0123456789abc", "hidden below 0123456789")
+
+	pager.mode.onRune('\x05') // CTRL-e
+	assert.Equal(t, 3, pager.leftColumnZeroBased)
+	assert.Equal(t, "<456789abc", screenRows(pager)[1])
+}
+
+// If everything fits already, end does nothing
+func TestHorizontalEndAllVisible(t *testing.T) {
+	pager := createLinesPager(t, 10, 3, "short", "lines")
+	pager.showLineNumbers = true
+
+	pager.mode.onRune('\x05') // CTRL-e
+	assert.Equal(t, 0, pager.leftColumnZeroBased)
+	assert.Assert(t, pager.showLineNumbers)
+}
+
+func TestMouseWheelScrollAmount(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "0\n1\n2\n3\n4\n5\n6\n7\n8\n9"))
+	pager.screen = twin.NewFakeScreen(20, 4)
+	pager.WheelScrollAmount = 3
+
+	pager.handleMouseEvent(twin.NewEventMouse(twin.MouseWheelDown, 0, 0))
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	pager.handleMouseEvent(twin.NewEventMouse(twin.MouseWheelUp, 0, 0))
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestMouseClickStatusBar(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "0\n1\n2\n3\n4\n5\n6\n7\n8\n9"))
+	pager.screen = twin.NewFakeScreen(20, 4)
+	pager.mode = &PagerModeInfo{Pager: pager, Text: "Hello"}
+
+	// Clicking the contents does nothing
+	pager.handleMouseEvent(twin.NewEventMouse(twin.MouseButtonPrimary, 0, 1))
+	_, isInfo := pager.mode.(*PagerModeInfo)
+	assert.Assert(t, isInfo)
+
+	// Clicking the status bar dismisses the message
+	pager.handleMouseEvent(twin.NewEventMouse(twin.MouseButtonPrimary, 0, 3))
+	assert.Equal(t, "Viewing", modeName(pager))
+}
+
+func TestTabHandling_ScrolledRight(t *testing.T) {
+	pager := createLinesPager(t, 10, 3, "ab\tcdefghijk")
+	pager.TabSize = 4
+	textstyles.TabSize = 4
+	defer func() { textstyles.TabSize = 8 }()
+
+	pager.leftColumnZeroBased = 3
+	assert.Equal(t, "<cdefghijk", screenRows(pager)[0])
+}
+
+func TestTabHandling_Wrapped(t *testing.T) {
+	pager := createLinesPager(t, 10, 4, "a\tb\tc\td\te")
+	pager.TabSize = 4
+	textstyles.TabSize = 4
+	defer func() { textstyles.TabSize = 8 }()
+
+	pager.WrapLongLines = true
+	rows := screenRows(pager)
+	assert.Equal(t, "a   b   c", rows[0])
+	assert.Equal(t, "d   e", rows[1])
+}
+
+// Like numberedLines(), but as one newline terminated string
+func numberedText(prefix string, count int) string {
+	return strings.Join(numberedLines(prefix, 1, count), "\n") + "\n"
+}
+
+// Write new (shorter) contents to the file, and wait for them to be read
+func truncateTailedFile(t *testing.T, pager *Pager, fileName string, contents string, lineCount int) {
+	assert.NilError(t, os.WriteFile(fileName, []byte(contents), 0o600))
+
+	r := pager.readers[0]
+	select {
+	case <-r.Reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reader to reload")
+	}
+	awaitLineCount(t, r, lineCount)
+
+	pager.handleReaderReloaded()
+}
+
+func TestReloadCommand(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "reloadme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("old 1\nold 2\n"), 0o600))
+
+	// Make sure the reload comes from us, not from tailing
+	tailInterval := time.Hour
+	r, err := reader.NewFromFilename(fileName, formatters.TTY, reader.ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 4)
+	pager.ShowLineNumbers = false
+	pager.showLineNumbers = false
+
+	assert.NilError(t, os.WriteFile(fileName, []byte("new 1\nnew 2\n"), 0o600))
+	pager.mode.onRune('R')
+	assert.Equal(t, "Reloading reloadme.txt", pager.mode.(*PagerModeInfo).Text)
+
+	select {
+	case <-r.Reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reader to reload")
+	}
+	awaitLineCount(t, r, 2)
+	pager.handleReaderReloaded()
+
+	pager.mode = PagerModeViewing{pager: pager}
+	assert.DeepEqual(t, []string{"new 1", "new 2"}, screenRows(pager)[:2])
+}
+
+func TestReloadCommandStdin(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "piped")
+
+	pager.mode.onRune('R')
+	assert.Equal(t, "Can't reload, input is not from a file", pager.mode.(*PagerModeInfo).Text)
+}
+
+func TestReloadKeepsPosition(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "rotateme.log")
+	assert.NilError(t, os.WriteFile(fileName, []byte(numberedText("line ", 30)), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	r, err := reader.NewFromFilename(fileName, formatters.TTY, reader.ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 6)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(10), "test")
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	truncateTailedFile(t, pager, fileName, numberedText("n", 20), 20)
+
+	assert.Equal(t, 10, pager.lineIndex().Index())
+	assert.Equal(t, "n11", pager.renderLines().inputLines[0].Plain())
+	assert.Assert(t, pager.TargetLine == nil)
+}
+
+func TestReloadShorterClampsPosition(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "rotateme.log")
+	assert.NilError(t, os.WriteFile(fileName, []byte(numberedText("line ", 30)), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	r, err := reader.NewFromFilename(fileName, formatters.TTY, reader.ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 6)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(10), "test")
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	truncateTailedFile(t, pager, fileName, numberedText("n", 8), 8)
+
+	// Our old line is gone, we should be at the end of the new contents
+	assert.Equal(t, 8-5, pager.lineIndex().Index())
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestReloadWhileFollowing(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "rotateme.log")
+	assert.NilError(t, os.WriteFile(fileName, []byte(numberedText("line ", 30)), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	r, err := reader.NewFromFilename(fileName, formatters.TTY, reader.ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 6)
+	pager.mode.onRune('F')
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	truncateTailedFile(t, pager, fileName, numberedText("n", 12), 12)
+
+	// Following should go on in the new contents
+	assert.Assert(t, pager.isScrolledToEnd())
+	assert.Equal(t, 12-5, pager.lineIndex().Index())
+	assert.Equal(t, "Following", pager.statusModeName())
+}
+
+func TestQuitIfOneScreen_Fits(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "a\nb\n")
+	assert.NilError(t, reader.Wait())
+
+	pager := NewPager(reader)
+	pager.QuitIfOneScreen = true
+
+	// Without QuitIfOneScreen kicking in, this would wait forever for input
+	// from the FakeScreen
+	pager.StartPaging(twin.NewFakeScreen(20, 3), nil, nil)
+
+	assert.Assert(t, pager.quit)
+	assert.Assert(t, !pager.DeInit, "Contents should be left on screen")
+}
+
+func TestQuitIfOneScreen_DoesNotFit(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "a\nb\nc\nd\ne\n")
+	assert.NilError(t, reader.Wait())
+
+	pager := NewPager(reader)
+	pager.QuitIfOneScreen = true
+	pager.screen = twin.NewFakeScreen(20, 3)
+
+	assert.Assert(t, !pager.fitsOnOneScreen())
+}
+
+// Filling the screen exactly fits, one more line doesn't
+func TestQuitIfOneScreen_Boundary(t *testing.T) {
+	for _, wrap := range []bool{false, true} {
+		exactly := NewPager(reader.NewFromTextForTesting("", "a\nb\nc\n"))
+		assert.NilError(t, exactly.readers[0].Wait())
+		exactly.screen = twin.NewFakeScreen(20, 3)
+		exactly.WrapLongLines = wrap
+		assert.Assert(t, exactly.fitsOnOneScreen(), "wrap=%v", wrap)
+
+		// Leaving room for the shell prompt
+		exactly.DeInitFalseMargin = 1
+		assert.Assert(t, !exactly.fitsOnOneScreen(), "wrap=%v", wrap)
+
+		overflow := NewPager(reader.NewFromTextForTesting("", "a\nb\nc\nd\n"))
+		assert.NilError(t, overflow.readers[0].Wait())
+		overflow.screen = twin.NewFakeScreen(20, 3)
+		overflow.WrapLongLines = wrap
+		assert.Assert(t, !overflow.fitsOnOneScreen(), "wrap=%v", wrap)
+	}
+
+	// A line wrapping onto a second row makes two lines overflow two rows
+	wrapped := NewPager(reader.NewFromTextForTesting("", "a\n0123456789abc\n"))
+	assert.NilError(t, wrapped.readers[0].Wait())
+	wrapped.screen = twin.NewFakeScreen(10, 2)
+	wrapped.WrapLongLines = true
+	assert.Assert(t, !wrapped.fitsOnOneScreen())
+}
+
+// Making the screen wider means fewer sub lines for wrapped lines. The top
+// input line should stay at the top anyway.
+func TestResizeKeepsTopLine(t *testing.T) {
+	pager := createLinesPager(t, 10, 4, "first", "aaaa bbbb cccc dddd eeee", "third", "fourth", "fifth")
+	pager.WrapLongLines = true
+	pager.ShowStatusBar = false
+
+	// Show the third sub line of the second line at the top
+	pager.scrollPosition = pager.scrollPosition.NextLine(3)
+	assert.DeepEqual(t, screenRows(pager), []string{"eeee", "third", "fourth", "fifth"})
+
+	// Wider, line two now has only two sub lines, stay on the last one
+	pager.screen = twin.NewFakeScreen(15, 4)
+	pager.handleResize()
+	assert.DeepEqual(t, screenRows(pager), []string{"dddd eeee", "third", "fourth", "fifth"})
+	assert.Equal(t, 1, pager.lineIndex().Index())
+
+	// Wide enough for no wrapping at all
+	pager.screen = twin.NewFakeScreen(40, 4)
+	pager.handleResize()
+	assert.DeepEqual(t, screenRows(pager), []string{"aaaa bbbb cccc dddd eeee", "third", "fourth", "fifth"})
+	assert.Equal(t, 1, pager.lineIndex().Index())
+
+	// Narrower again, the top line should still be the same
+	pager.screen = twin.NewFakeScreen(10, 4)
+	pager.handleResize()
+	assert.DeepEqual(t, screenRows(pager), []string{"aaaa bbbb", "cccc dddd", "eeee", "third"})
+}
@@ -0,0 +1,42 @@
+package internal
+
+import "github.com/walles/moor/v2/twin"
+
+// Asks the user to press a quit key again before quitting. Any other key
+// cancels.
+type PagerModeConfirmQuit struct {
+	pager *Pager
+}
+
+func (m PagerModeConfirmQuit) drawFooter(_ string, _ string) {
+	m.pager.setFooter("Press again to quit, any other key cancels", "")
+}
+
+func (m PagerModeConfirmQuit) onKey(key twin.KeyCode) {
+	m.confirmIfQuitAction(m.pager.KeyBindings.keys[key])
+}
+
+func (m PagerModeConfirmQuit) onRune(char rune) {
+	m.confirmIfQuitAction(m.pager.KeyBindings.runes[char])
+}
+
+func (m PagerModeConfirmQuit) confirmIfQuitAction(actionName string) {
+	p := m.pager
+	p.mode = PagerModeViewing{pager: p}
+
+	if actionName == "quit" || actionName == "confirmQuit" {
+		p.Quit()
+	}
+}
+
+// Quit, after asking for confirmation if the user wants that. Leaving the help
+// screen never needs confirmation.
+func (p *Pager) quitOrConfirm(confirm bool) {
+	if !confirm || p.isShowingHelp {
+		p.Quit()
+		return
+	}
+
+	p.mode = PagerModeConfirmQuit{pager: p}
+	p.setTargetLine(nil)
+}
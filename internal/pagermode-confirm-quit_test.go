@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestQuitWithoutConfirmation(t *testing.T) {
+	pager := createThreeLinesPager(t)
+
+	typeRunes(pager, "q")
+	assert.Assert(t, pager.quit)
+}
+
+func TestConfirmQuit(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.ConfirmQuit = true
+
+	typeRunes(pager, "q")
+	assert.Assert(t, !pager.quit)
+	assert.Equal(t, "ConfirmQuit", modeName(pager))
+
+	typeRunes(pager, "q")
+	assert.Assert(t, pager.quit)
+}
+
+func TestConfirmQuitCancel(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.ConfirmQuit = true
+
+	typeRunes(pager, "qj")
+	assert.Assert(t, !pager.quit)
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// Cancelling should not scroll
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	// Back to square one, the next 'q' should ask again
+	typeRunes(pager, "q")
+	assert.Assert(t, !pager.quit)
+	pager.mode.onKey(twin.KeyDown)
+	assert.Assert(t, !pager.quit)
+	assert.Equal(t, "Viewing", modeName(pager))
+}
+
+func TestConfirmQuitZZ(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	assert.NilError(t, pager.KeyBindings.Bind("Z", "confirmQuit"))
+
+	typeRunes(pager, "Z")
+	assert.Assert(t, !pager.quit)
+
+	typeRunes(pager, "Z")
+	assert.Assert(t, pager.quit)
+}
@@ -0,0 +1,40 @@
+package internal
+
+import "github.com/walles/moor/v2/twin"
+
+// Asks the user whether to continue searching from the other end of the input.
+// 'y' does that, any other key cancels.
+type PagerModeConfirmWrap struct {
+	pager     *Pager
+	direction SearchDirection
+}
+
+func (m PagerModeConfirmWrap) drawFooter(_ string, _ string) {
+	otherEnd := "top"
+	if m.direction == SearchDirectionBackward {
+		otherEnd = "bottom"
+	}
+
+	m.pager.setFooter("Not found: "+m.pager.searchString+". Press 'y' to search from the "+otherEnd+", any other key cancels", "")
+}
+
+func (m PagerModeConfirmWrap) onKey(_ twin.KeyCode) {
+	m.pager.mode = PagerModeViewing{pager: m.pager}
+}
+
+func (m PagerModeConfirmWrap) onRune(char rune) {
+	p := m.pager
+	p.mode = PagerModeViewing{pager: p}
+	if char != 'y' {
+		return
+	}
+
+	// Searching from not-found mode restarts from the other end
+	p.mode = PagerModeNotFound{pager: p}
+	switch m.direction {
+	case SearchDirectionForward:
+		p.scrollToNextSearchHit()
+	case SearchDirectionBackward:
+		p.scrollToPreviousSearchHit()
+	}
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestConfirmWrapForward(t *testing.T) {
+	pager := createLinesPager(t, 80, 3, "a", "b", "c", "d", "e", "f")
+	pager.SearchWrap = SearchWrapConfirm
+	pager.scrollToEnd()
+
+	pager.searchString = "a"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.mode.onRune('n')
+	assert.Equal(t, "ConfirmWrap", modeName(pager))
+	assert.Equal(t,
+		"Not found: a. Press 'y' to search from the top, any other key cancels",
+		statusBarRow(pager))
+
+	pager.mode.onRune('y')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Assert(t, pager.lineIndex().IsZero())
+}
+
+func TestConfirmWrapBackward(t *testing.T) {
+	pager := createLinesPager(t, 80, 3, "a", "b", "c", "d", "e", "f")
+	pager.SearchWrap = SearchWrapConfirm
+
+	pager.searchString = "f"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.mode.onRune('N')
+	assert.Equal(t, "ConfirmWrap", modeName(pager))
+	assert.Equal(t,
+		"Not found: f. Press 'y' to search from the bottom, any other key cancels",
+		statusBarRow(pager))
+
+	pager.mode.onRune('y')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
+
+func TestConfirmWrapCancel(t *testing.T) {
+	pager := createLinesPager(t, 80, 3, "a", "b", "c", "d", "e", "f")
+	pager.SearchWrap = SearchWrapConfirm
+	pager.scrollToEnd()
+
+	pager.searchString = "a"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	// Pressing 'n' again means no
+	typeRunes(pager, "nn")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 4, pager.lineIndex().Index())
+
+	// Keys cancel too, without doing anything else
+	pager.mode.onRune('n')
+	assert.Equal(t, "ConfirmWrap", modeName(pager))
+	pager.mode.onKey(twin.KeyUp)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/twin"
+)
+
+// Copy lines to the clipboard. 'c' copies the top line, and any mark copies the
+// lines between that mark and the top line.
+type PagerModeCopy struct {
+	pager *Pager
+}
+
+func (m PagerModeCopy) drawFooter(_ string, _ string) {
+	p := m.pager
+
+	_, height := p.screen.Size()
+
+	prompt := "Press 'c' to copy the top line, or a mark to copy from there: "
+	if len(p.bookmarks) == 0 {
+		prompt = "Press 'c' to copy the top line: "
+	}
+
+	pos := 0
+	for _, token := range prompt {
+		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(token, twin.StyleDefault))
+	}
+
+	// Add a cursor
+	p.screen.SetCell(pos, height-1, twin.NewStyledRune(' ', twin.StyleDefault.WithAttr(twin.AttrReverse)))
+}
+
+func (m PagerModeCopy) onKey(key twin.KeyCode) {
+	p := m.pager
+
+	switch key {
+	case twin.KeyEnter, twin.KeyEscape:
+		// Never mind I
+		p.mode = PagerModeViewing{pager: p}
+
+	default:
+		// Never mind II
+		p.mode = PagerModeViewing{pager: p}
+		p.mode.onKey(key)
+	}
+}
+
+func (m PagerModeCopy) onRune(char rune) {
+	p := m.pager
+	p.mode = PagerModeViewing{pager: p}
+
+	topLine := p.lineIndex()
+	if topLine == nil {
+		p.setMessage("Nothing to copy")
+		return
+	}
+
+	if char == 'c' {
+		p.copyLines(*topLine, *topLine)
+		return
+	}
+
+	mark, ok := p.bookmarks[char]
+	if !ok {
+		p.setMessage("No such mark: " + string(char))
+		return
+	}
+
+	markLine := mark.lineIndex(p)
+	if markLine == nil {
+		p.setMessage("Nothing to copy")
+		return
+	}
+
+	p.copyLines(*markLine, *topLine)
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestCopyTopLine(t *testing.T) {
+	pager := createLinesPager(t, 40, 3, "\x1b[31mfirst\x1b[m", "second", "third")
+	pager.mode.onKey(twin.KeyDown)
+
+	pager.mode.onRune('c')
+	pager.mode.onRune('c')
+
+	assert.Equal(t, "second", pager.screen.(*twin.FakeScreen).GetClipboard())
+	assert.Equal(t, "Copied 1 line to the clipboard", screenRows(pager)[2])
+}
+
+func TestCopyFromMark(t *testing.T) {
+	pager := createLinesPager(t, 40, 3, "\x1b[31mfirst\x1b[m", "second", "third", "fourth")
+
+	pager.mode.onRune('m')
+	pager.mode.onRune('a')
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyDown)
+
+	pager.mode.onRune('c')
+	pager.mode.onRune('a')
+
+	// No ANSI codes in the copied text
+	assert.Equal(t, "first\nsecond\nthird", pager.screen.(*twin.FakeScreen).GetClipboard())
+	assert.Equal(t, "Copied 3 lines to the clipboard", screenRows(pager)[2])
+}
+
+func TestCopyFromMissingMark(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, "first", "second")
+
+	pager.mode.onRune('c')
+	pager.mode.onRune('x')
+
+	assert.Equal(t, "", pager.screen.(*twin.FakeScreen).GetClipboard())
+	assert.Equal(t, "No such mark: x", screenRows(pager)[2])
+}
+
+func TestAbsoluteFileName(t *testing.T) {
+	dir := t.TempDir()
+	fileName := path.Join(dir, "file.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("hello\n"), 0o600))
+
+	// Open the file through a relative path
+	workingDir, err := os.Getwd()
+	assert.NilError(t, err)
+	relative, err := filepath.Rel(workingDir, fileName)
+	assert.NilError(t, err)
+	assert.Assert(t, !filepath.IsAbs(relative))
+
+	r, err := reader.NewFromFilename(relative, formatters.TTY, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	absolute := absoluteFileName(r)
+	assert.Assert(t, absolute != nil)
+	assert.Equal(t, fileName, *absolute)
+}
+
+func TestAbsoluteFileNameStdin(t *testing.T) {
+	r, err := reader.NewFromStream("stdin", strings.NewReader("hello\n"), formatters.TTY, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	assert.Assert(t, absoluteFileName(r) == nil)
+	assert.Assert(t, absoluteFileName(nil) == nil)
+}
+
+func TestCopyFileName(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "file.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("hello\n"), 0o600))
+
+	r, err := reader.NewFromFilename(fileName, formatters.TTY, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(200, 3)
+
+	pager.mode.onRune('C')
+
+	assert.Equal(t, fileName, pager.screen.(*twin.FakeScreen).GetClipboard())
+	assert.Equal(t, "Copied file name to the clipboard: "+fileName, screenRows(pager)[2])
+}
+
+func TestCopyFileNameStdin(t *testing.T) {
+	pager := createLinesPager(t, 50, 3, "first", "second")
+
+	pager.mode.onRune('C')
+
+	assert.Equal(t, "", pager.screen.(*twin.FakeScreen).GetClipboard())
+	assert.Equal(t, "Not viewing a file, no file name to copy", screenRows(pager)[2])
+}
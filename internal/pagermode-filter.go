@@ -1,7 +1,11 @@
 package internal
 
 import (
+	"strings"
+
 	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
 	"github.com/walles/moor/v2/twin"
 )
 
@@ -27,8 +31,24 @@ func (m PagerModeFilter) drawFooter(_ string, _ string) {
 	m.inputBox.draw(m.pager.screen, "Type to filter, 'ENTER' submits, 'ESC' cancels", "Filter: ")
 }
 
+// A leading '!' means show the lines not matching the rest of the text, like
+// in less. An empty pattern means no filtering.
 func (m *PagerModeFilter) updateFilterPattern(text string) {
+	inverted := strings.HasPrefix(text, "!")
+	if inverted {
+		text = strings.TrimPrefix(text, "!")
+	}
+
 	m.pager.filterPattern = toPattern(text)
+	m.pager.filterInverted = inverted
+
+	if inverted {
+		// None of the lines shown match, nothing to highlight
+		m.pager.searchString = ""
+		m.pager.searchPattern = nil
+		return
+	}
+
 	m.pager.searchString = text
 	m.pager.searchPattern = toPattern(text)
 }
@@ -44,7 +64,7 @@ func (m *PagerModeFilter) onKey(key twin.KeyCode) {
 
 	case twin.KeyEscape:
 		m.pager.mode = PagerModeViewing{pager: m.pager}
-		m.pager.filterPattern = nil
+		m.pager.clearFilter()
 		m.pager.searchString = ""
 		m.pager.searchPattern = nil
 
@@ -60,3 +80,25 @@ func (m *PagerModeFilter) onKey(key twin.KeyCode) {
 func (m *PagerModeFilter) onRune(char rune) {
 	m.inputBox.handleRune(char)
 }
+
+// Stop filtering, keeping the currently topmost line at the top of the screen.
+func (p *Pager) clearFilter() {
+	if p.filterPattern == nil {
+		return
+	}
+
+	var topLine *reader.NumberedLine
+	if lineIndex := p.lineIndex(); lineIndex != nil {
+		topLine = p.Reader().GetLine(*lineIndex)
+	}
+
+	p.filterPattern = nil
+	p.filterInverted = false
+
+	if topLine != nil {
+		// Filtered lines keep their original line numbers, use that to find our
+		// way back in the unfiltered input
+		p.scrollPosition = NewScrollPositionFromIndex(
+			linemetadata.IndexFromZeroBased(topLine.Number.AsZeroBased()), "clearFilter")
+	}
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func createLinesPager(t *testing.T, width int, height int, lines ...string) *Pager {
+	reader := reader.NewFromTextForTesting("", strings.Join(lines, "\n"))
+	assert.NilError(t, reader.Wait())
+
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(width, height)
+	pager.showLineNumbers = false
+
+	return pager
+}
+
+// Lines like "line 1", "line 2" and so on, for prefix "line "
+func numberedLines(prefix string, first int, last int) []string {
+	lines := []string{}
+	for i := first; i <= last; i++ {
+		lines = append(lines, prefix+strconv.Itoa(i))
+	}
+	return lines
+}
+
+func typeFilter(pager *Pager, filter string) {
+	pager.mode.onRune('&')
+	for _, char := range filter {
+		pager.mode.onRune(char)
+	}
+}
+
+func screenRows(pager *Pager) []string {
+	pager.redraw("")
+
+	_, height := pager.screen.Size()
+	rows := []string{}
+	for i := range height {
+		rows = append(rows, rowToString(pager.screen.(*twin.FakeScreen).GetRow(i)))
+	}
+	return rows
+}
+
+func TestFilter(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "träff 1", "miss 2", "TRÄFF 3", "miss 4", "träff 5")
+
+	typeFilter(pager, "träff")
+	assert.Equal(t, "Filter", modeName(pager))
+
+	rows := screenRows(pager)
+	assert.Equal(t, "träff 1", rows[0])
+	assert.Equal(t, "TRÄFF 3", rows[1])
+	assert.Equal(t, "träff 5", rows[2])
+	assert.Equal(t, "---", rows[3])
+
+	// Submitting the filter should keep it active
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	rows = screenRows(pager)
+	assert.Equal(t, "träff 1", rows[0])
+	assert.Equal(t, "TRÄFF 3", rows[1])
+	assert.Equal(t, "träff 5", rows[2])
+	assert.Assert(t, strings.HasPrefix(rows[4], "Filtered: 3/5 lines"), rows[4])
+}
+
+func TestFilterKeepsLineNumbers(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "träff 1", "miss 2", "TRÄFF 3")
+	pager.showLineNumbers = true
+
+	typeFilter(pager, "träff")
+	pager.mode.onKey(twin.KeyEnter)
+
+	rows := screenRows(pager)
+	assert.Equal(t, "  1 träff 1", rows[0])
+	assert.Equal(t, "  3 TRÄFF 3", rows[1])
+}
+
+func TestFilterWrapLongLines(t *testing.T) {
+	pager := createLinesPager(t, 10, 5, "miss", "träff 1234567890", "miss")
+	pager.WrapLongLines = true
+
+	typeFilter(pager, "träff")
+	pager.mode.onKey(twin.KeyEnter)
+
+	rows := screenRows(pager)
+	assert.Equal(t, "träff", rows[0])
+	assert.Equal(t, "1234567890", rows[1])
+	assert.Equal(t, "---", rows[2])
+}
+
+func TestFilterCancelKeepsTopLine(t *testing.T) {
+	lines := []string{}
+	for i := range 20 {
+		if i%4 == 0 {
+			lines = append(lines, "träff "+strconv.Itoa(i))
+		} else {
+			lines = append(lines, "miss "+strconv.Itoa(i))
+		}
+	}
+	pager := createLinesPager(t, 20, 3, lines...)
+
+	typeFilter(pager, "träff")
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "träff 8", screenRows(pager)[0])
+
+	// Cancelling the filter should keep the same line at the top of the screen
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+	rows := screenRows(pager)
+	assert.Equal(t, "träff 8", rows[0])
+	assert.Equal(t, "miss 9", rows[1])
+}
+
+// Like "&!pattern" in less
+func TestFilterInverted(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "träff 1", "miss 2", "TRÄFF 3", "miss 4", "träff 5")
+
+	typeFilter(pager, "!träff")
+	pager.mode.onKey(twin.KeyEnter)
+
+	rows := screenRows(pager)
+	assert.Equal(t, "miss 2", rows[0])
+	assert.Equal(t, "miss 4", rows[1])
+	assert.Equal(t, "---", rows[2])
+
+	// Nothing on screen matches, nothing to highlight
+	assert.Assert(t, pager.searchPattern == nil)
+
+	// Changing to a non-inverted filter should show the other lines
+	typeFilter(pager, "miss")
+	rows = screenRows(pager)
+	assert.Equal(t, "miss 2", rows[0])
+	assert.Equal(t, "miss 4", rows[1])
+	assert.Equal(t, "---", rows[2])
+
+	// Adding a '!' while typing inverts the filter
+	pager.mode.onKey(twin.KeyHome)
+	pager.mode.onRune('!')
+	rows = screenRows(pager)
+	assert.Equal(t, "träff 1", rows[0])
+	assert.Equal(t, "TRÄFF 3", rows[1])
+	assert.Equal(t, "träff 5", rows[2])
+}
+
+func TestFilterEmptyClears(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "träff 1", "miss 2", "träff 3")
+
+	typeFilter(pager, "!")
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	rows := screenRows(pager)
+	assert.Equal(t, "träff 1", rows[0])
+	assert.Equal(t, "miss 2", rows[1])
+	assert.Equal(t, "träff 3", rows[2])
+}
@@ -1,10 +1,14 @@
 package internal
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/internal/util"
 	"github.com/walles/moor/v2/twin"
 )
 
@@ -17,7 +21,7 @@ func NewPagerModeGotoLine(p *Pager) *PagerModeGotoLine {
 	m := &PagerModeGotoLine{
 		pager: p,
 		inputBox: InputBox{
-			accept:        INPUTBOX_ACCEPT_POSITIVE_NUMBERS,
+			accept:        INPUTBOX_ACCEPT_POSITIVE_NUMBERS_AND_PERCENT,
 			onTextChanged: nil,
 		},
 	}
@@ -25,10 +29,20 @@ func NewPagerModeGotoLine(p *Pager) *PagerModeGotoLine {
 }
 
 func (m *PagerModeGotoLine) drawFooter(_ string, _ string) {
-	m.inputBox.draw(m.pager.screen, "'ENTER' submits, 'ESC' cancels", "Go to line number: ")
+	m.inputBox.draw(m.pager.screen, "'ENTER' submits, 'ESC' cancels", "Go to line number, percentage or b<byte offset>: ")
 }
 
 func (m *PagerModeGotoLine) updateLineNumber(text string) {
+	if strings.Contains(text, "%") {
+		m.updatePercentage(text)
+		return
+	}
+
+	if strings.HasPrefix(text, "b") {
+		m.updateByteOffset(text)
+		return
+	}
+
 	newLineNumber, err := strconv.Atoi(text)
 	if err != nil {
 		log.Debugf("Got non-number goto text '%s'", text)
@@ -38,12 +52,79 @@ func (m *PagerModeGotoLine) updateLineNumber(text string) {
 		log.Debugf("Got non-positive goto line number: %d", newLineNumber)
 		return
 	}
-	targetIndex := linemetadata.IndexFromOneBased(newLineNumber)
-	m.pager.scrollPosition = NewScrollPositionFromIndex(
-		targetIndex,
-		"onGotoLineKey",
-	)
-	m.pager.setTargetLine(&targetIndex)
+	m.pager.goToLine(linemetadata.IndexFromOneBased(newLineNumber))
+}
+
+// Jump to some percentage of the input. Text is expected to be a number
+// followed by a single '%'.
+func (m *PagerModeGotoLine) updatePercentage(text string) {
+	p := m.pager
+
+	percentage, err := strconv.Atoi(strings.TrimSuffix(text, "%"))
+	if err != nil {
+		log.Debugf("Got unparsable goto percentage '%s'", text)
+		p.setMessage("Not a line number or percentage: " + text)
+		return
+	}
+
+	lineCount := p.Reader().GetLineCount()
+	if lineCount == 0 {
+		return
+	}
+
+	if percentage >= 100 {
+		p.scrollToEnd()
+		return
+	}
+	if percentage < 0 {
+		percentage = 0
+	}
+
+	// With 6 lines, 100% should be the last index, which is 5
+	targetIndex := linemetadata.IndexFromZeroBased((lineCount - 1) * percentage / 100)
+	p.scrollPosition = NewScrollPositionFromIndex(targetIndex, "onGotoPercentage")
+	p.setTargetLine(nil)
+}
+
+// Jump to the line containing some byte offset. Text is expected to be a 'b'
+// followed by a number.
+func (m *PagerModeGotoLine) updateByteOffset(text string) {
+	p := m.pager
+
+	offset, err := strconv.ParseInt(strings.TrimPrefix(text, "b"), 10, 64)
+	if err != nil || offset < 0 {
+		log.Debugf("Got unparsable goto byte offset '%s'", text)
+		p.setMessage("Not a byte offset: " + text)
+		return
+	}
+
+	if p.filterPattern != nil {
+		// Byte offsets are into the unfiltered input
+		p.setMessage("Can't go to a byte offset while filtering")
+		return
+	}
+
+	var r *reader.ReaderImpl
+	if p.isShowingHelp {
+		r = _HelpReader
+	} else if p.pipedReader != nil {
+		r = p.pipedReader
+	} else {
+		p.readerLock.Lock()
+		r = p.readers[p.currentReader]
+		p.readerLock.Unlock()
+	}
+
+	targetIndex, pastEnd := r.GetLineIndexForByteOffset(offset)
+	if targetIndex == nil {
+		p.setMessage("Byte offsets not available for this input")
+		return
+	}
+
+	p.goToLine(*targetIndex)
+	if pastEnd {
+		p.setMessage(fmt.Sprintf("Byte offset %s is past the end of the input", util.FormatInt(int(offset))))
+	}
 }
 
 func (m *PagerModeGotoLine) onKey(key twin.KeyCode) {
@@ -53,8 +134,9 @@ func (m *PagerModeGotoLine) onKey(key twin.KeyCode) {
 
 	switch key {
 	case twin.KeyEnter:
-		m.updateLineNumber(m.inputBox.text)
 		m.pager.mode = PagerModeViewing{pager: m.pager}
+		m.pager.rememberPosition()
+		m.updateLineNumber(m.inputBox.text)
 
 	case twin.KeyEscape:
 		m.pager.mode = PagerModeViewing{pager: m.pager}
@@ -75,11 +157,18 @@ func (m *PagerModeGotoLine) onRune(char rune) {
 	}
 
 	if char == 'g' {
+		p.rememberPosition()
 		p.scrollPosition = newScrollPosition("Pager scroll position")
 		p.handleScrolledUp()
 		p.mode = PagerModeViewing{pager: p}
 		return
 	}
 
+	if char == 'b' && m.inputBox.text == "" {
+		// "b12345" goes to byte offset 12345
+		m.inputBox.setText("b")
+		return
+	}
+
 	m.inputBox.handleRune(char)
 }
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+// Type something into the goto line prompt and press RETURN
+func gotoLine(pager *Pager, text string) {
+	pager.mode = NewPagerModeGotoLine(pager)
+	for _, char := range text {
+		pager.mode.onRune(char)
+	}
+	pager.mode.onKey(twin.KeyEnter)
+}
+
+func TestGotoPercentage(t *testing.T) {
+	pager := createThreeLinesPager(t)
+
+	gotoLine(pager, "100%")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	gotoLine(pager, "0%")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	// Six lines, so the midpoint is line index 2 of 0-5
+	gotoLine(pager, "50%")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	// Clamp to the end
+	gotoLine(pager, "150%")
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestGotoPercentageInvalid(t *testing.T) {
+	pager := createThreeLinesPager(t)
+
+	gotoLine(pager, "50%3")
+	_, isInfo := pager.mode.(*PagerModeInfo)
+	assert.Assert(t, isInfo)
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestGotoByteOffset(t *testing.T) {
+	// Lines start at byte offsets 0, 4, 7, 8, 14 and 18
+	r, err := reader.NewFromStream("", strings.NewReader("abc\nde\n\nfghij\nklm\nno\n"), formatters.TTY,
+		reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 3)
+
+	gotoLine(pager, "b8")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	gotoLine(pager, "b6")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 1, pager.lineIndex().Index())
+
+	gotoLine(pager, "b0")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+// Highlighting shouldn't make us lose track of the byte offsets
+func TestGotoByteOffsetHighlighted(t *testing.T) {
+	// Lines start at byte offsets 0, 13, 14 and 28
+	fileName := path.Join(t.TempDir(), "main.go")
+	assert.NilError(t, os.WriteFile(fileName, []byte("package main\n\nfunc main() {\n}\n"), 0o600))
+
+	r, err := reader.NewFromFilename(fileName, formatters.TTY, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 3)
+
+	// Make sure we did highlight the file
+	firstLine := pager.Reader().GetLine(linemetadata.Index{})
+	assert.Equal(t, "package main", firstLine.Plain())
+	tokens := firstLine.HighlightedTokens(twin.StyleDefault, twin.StyleDefault, nil)
+	assert.Assert(t, tokens.StyledRunes[0].Style != twin.StyleDefault)
+
+	gotoLine(pager, "b20")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+// While showing piped output, byte offsets are into that output
+func TestGotoByteOffsetPiped(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, "a", "b", "c", "d", "e", "f")
+
+	// Lines start at byte offsets 0, 5, 10, 15 and 20
+	piped, err := reader.NewFromStream("| cat", strings.NewReader("long\nlong\nlong\nlong\nlong\n"), formatters.TTY,
+		reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, piped.Wait())
+	pager.setPipedReader(piped)
+
+	gotoLine(pager, "b13")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+func TestGotoByteOffsetPastEnd(t *testing.T) {
+	// Lines start at byte offsets 0, 4, 7, 8, 14 and 18
+	r, err := reader.NewFromStream("", strings.NewReader("abc\nde\n\nfghij\nklm\nno\n"), formatters.TTY,
+		reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 3)
+
+	gotoLine(pager, "b1000")
+	assert.Equal(t, "Info", modeName(pager))
+	assert.Equal(t, "Byte offset 1000 is past the end of the input", pager.mode.(*PagerModeInfo).Text)
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestGotoByteOffsetInvalid(t *testing.T) {
+	// Lines start at byte offsets 0, 4, 7, 8, 14 and 18
+	r, err := reader.NewFromStream("", strings.NewReader("abc\nde\n\nfghij\nklm\nno\n"), formatters.TTY,
+		reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 3)
+
+	// 'b' is only accepted first, so this becomes "b12"
+	gotoLine(pager, "b1b2")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	gotoLine(pager, "b")
+	assert.Equal(t, "Info", modeName(pager))
+	assert.Equal(t, 3, pager.lineIndex().Index())
+}
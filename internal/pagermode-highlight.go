@@ -0,0 +1,135 @@
+package internal
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Sticky highlights get these colors in order. When a highlight is removed,
+// its color is reused by the next one added.
+var stickyHighlightColors = []twin.Color{
+	twin.NewColor16(3), // Yellow
+	twin.NewColor16(2), // Green
+	twin.NewColor16(6), // Cyan
+	twin.NewColor16(5), // Magenta
+	twin.NewColor16(1), // Red
+	twin.NewColor16(4), // Blue
+}
+
+// A pattern the user wants highlighted, independent of the search
+type stickyHighlight struct {
+	// What the user typed, for finding the highlight again when removing it
+	text string
+
+	highlight reader.PatternHighlight
+}
+
+type PagerModeHighlight struct {
+	pager    *Pager
+	inputBox *InputBox
+}
+
+func NewPagerModeHighlight(p *Pager) *PagerModeHighlight {
+	return &PagerModeHighlight{
+		pager: p,
+		inputBox: &InputBox{
+			accept: INPUTBOX_ACCEPT_ALL,
+		},
+	}
+}
+
+func (m *PagerModeHighlight) drawFooter(_ string, _ string) {
+	m.inputBox.draw(m.pager.screen, "Type an existing pattern to remove it, 'ENTER' submits, 'ESC' cancels", "Highlight: ")
+}
+
+func (m *PagerModeHighlight) onKey(key twin.KeyCode) {
+	if m.inputBox.handleKey(key) {
+		return
+	}
+
+	switch key {
+	case twin.KeyEnter:
+		m.pager.mode = PagerModeViewing{pager: m.pager}
+		m.pager.toggleStickyHighlight(m.inputBox.text)
+
+	case twin.KeyEscape:
+		m.pager.mode = PagerModeViewing{pager: m.pager}
+
+	default:
+		log.Debugf("Unhandled highlight key event %v", key)
+	}
+}
+
+func (m *PagerModeHighlight) onRune(char rune) {
+	m.inputBox.handleRune(char)
+}
+
+// Remove the highlight for text if there is one, otherwise add one.
+func (p *Pager) toggleStickyHighlight(text string) {
+	for i, sticky := range p.stickyHighlights {
+		if sticky.text == text {
+			p.stickyHighlights = append(p.stickyHighlights[:i:i], p.stickyHighlights[i+1:]...)
+			return
+		}
+	}
+
+	pattern := toPattern(text)
+	if pattern == nil {
+		// Empty text, never mind
+		return
+	}
+
+	p.stickyHighlights = append(p.stickyHighlights, stickyHighlight{
+		text: text,
+		highlight: reader.PatternHighlight{
+			Pattern: pattern,
+			Style:   stickyHighlightStyle(p.nextStickyHighlightColor()),
+		},
+	})
+}
+
+func stickyHighlightStyle(background twin.Color) twin.Style {
+	style := twin.StyleDefault.WithForeground(twin.NewColor16(0)).WithBackground(background)
+	if withoutColors {
+		// Colors will be stripped, keep the highlight visible anyway. Reverse
+		// is taken by search hits, so we go for something else.
+		style = style.WithAttr(twin.AttrBold | twin.AttrUnderline)
+	}
+
+	return style
+}
+
+// The first color not used by any current highlight
+func (p *Pager) nextStickyHighlightColor() twin.Color {
+	for _, color := range stickyHighlightColors {
+		used := false
+		for _, sticky := range p.stickyHighlights {
+			if sticky.highlight.Style.Background() == color {
+				used = true
+				break
+			}
+		}
+
+		if !used {
+			return color
+		}
+	}
+
+	// All colors taken, start over
+	return stickyHighlightColors[len(p.stickyHighlights)%len(stickyHighlightColors)]
+}
+
+// Sticky highlights in the form renderLine() wants them
+func (p *Pager) highlights() []reader.PatternHighlight {
+	urlHighlights := p.urlHighlights()
+	if len(p.stickyHighlights) == 0 && len(urlHighlights) == 0 {
+		return nil
+	}
+
+	highlights := make([]reader.PatternHighlight, 0, len(p.stickyHighlights)+len(urlHighlights))
+	for _, sticky := range p.stickyHighlights {
+		highlights = append(highlights, sticky.highlight)
+	}
+	return append(highlights, urlHighlights...)
+}
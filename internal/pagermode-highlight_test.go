@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func addStickyHighlight(pager *Pager, text string) {
+	pager.mode.onRune('H')
+	typeRunes(pager, text)
+	pager.mode.onKey(twin.KeyEnter)
+}
+
+func TestStickyHighlights(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "apa bepa", "bepa cepa")
+
+	addStickyHighlight(pager, "apa")
+	assert.Equal(t, "Viewing", modeName(pager))
+	addStickyHighlight(pager, "cepa")
+
+	apaStyle := twin.StyleDefault.WithForeground(twin.NewColor16(0)).WithBackground(stickyHighlightColors[0])
+	cepaStyle := twin.StyleDefault.WithForeground(twin.NewColor16(0)).WithBackground(stickyHighlightColors[1])
+
+	pager.redraw("")
+	screen := pager.screen.(*twin.FakeScreen)
+	assert.Equal(t, apaStyle, screen.GetRow(0)[0].Style)
+	assert.Equal(t, twin.StyleDefault, screen.GetRow(0)[3].Style)
+	assert.Equal(t, twin.StyleDefault, screen.GetRow(0)[5].Style)
+	assert.Equal(t, cepaStyle, screen.GetRow(1)[5].Style)
+
+	// Removing one highlight leaves the other one alone
+	addStickyHighlight(pager, "apa")
+	pager.redraw("")
+	assert.Equal(t, twin.StyleDefault, screen.GetRow(0)[0].Style)
+	assert.Equal(t, cepaStyle, screen.GetRow(1)[5].Style)
+
+	// The freed color goes to the next highlight
+	addStickyHighlight(pager, "bepa")
+	bepaStyle := twin.StyleDefault.WithForeground(twin.NewColor16(0)).WithBackground(stickyHighlightColors[0])
+	pager.redraw("")
+	assert.Equal(t, bepaStyle, screen.GetRow(0)[4].Style)
+}
+
+// Search hits should win over sticky highlights, and earlier highlights over
+// later ones
+func TestStickyHighlightPrecedence(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "abc")
+
+	addStickyHighlight(pager, "ab")
+	addStickyHighlight(pager, "bc")
+	pager.searchString = "a"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	abStyle := twin.StyleDefault.WithForeground(twin.NewColor16(0)).WithBackground(stickyHighlightColors[0])
+	bcStyle := twin.StyleDefault.WithForeground(twin.NewColor16(0)).WithBackground(stickyHighlightColors[1])
+
+	pager.redraw("")
+	row := pager.screen.(*twin.FakeScreen).GetRow(0)
+	assert.Equal(t, searchHitStyle, row[0].Style)
+	assert.Equal(t, abStyle, row[1].Style)
+	assert.Equal(t, bcStyle, row[2].Style)
+}
+
+func TestStickyHighlightEscape(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "abc")
+
+	pager.mode.onRune('H')
+	typeRunes(pager, "ab")
+	pager.mode.onKey(twin.KeyEscape)
+
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 0, len(pager.stickyHighlights))
+}
@@ -13,6 +13,12 @@ type PagerModeInfo struct {
 	logged bool
 }
 
+// Show a message in the status bar. The message goes away on the next keypress,
+// which is then handled as usual.
+func (p *Pager) setMessage(text string) {
+	p.mode = &PagerModeInfo{Pager: p, Text: text}
+}
+
 func (m *PagerModeInfo) drawFooter(_ string, _ string) {
 	if !m.logged {
 		log.Infof("Displaying info message to user: %q", m.Text)
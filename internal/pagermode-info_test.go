@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func statusLine(pager *Pager) string {
+	rows := screenRows(pager)
+	return rows[len(rows)-1]
+}
+
+func TestMessageShownAfterAction(t *testing.T) {
+	pager := createLinesPager(t, 40, 4, "a", "b", "c", "d", "e")
+
+	pager.mode.onRune('w')
+	assert.Equal(t, "Word wrapping enabled", statusLine(pager))
+}
+
+func TestMessageClearedByRune(t *testing.T) {
+	pager := createLinesPager(t, 40, 4, "a", "b", "c", "d", "e")
+
+	pager.setMessage("Hello")
+	assert.Equal(t, "Info", modeName(pager))
+	assert.Equal(t, "Hello", statusLine(pager))
+
+	// The key should both clear the message and be handled as usual
+	pager.mode.onRune('j')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	assert.Assert(t, statusLine(pager) != "Hello")
+}
+
+func TestMessageClearedByKey(t *testing.T) {
+	pager := createLinesPager(t, 40, 4, "a", "b", "c", "d", "e")
+
+	pager.setMessage("Hello")
+	assert.Equal(t, "Info", modeName(pager))
+	assert.Equal(t, "Hello", statusLine(pager))
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	assert.Assert(t, statusLine(pager) != "Hello")
+}
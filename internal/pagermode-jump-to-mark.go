@@ -74,6 +74,7 @@ func (m PagerModeJumpToMark) onRune(char rune) {
 
 	destination, ok := m.pager.bookmarks[char]
 	if ok {
+		m.pager.rememberPosition()
 		m.pager.scrollPosition = destination
 	}
 
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestJumpToMark(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.mode.onKey(twin.KeyDown)
+	startIndex := *pager.lineIndex()
+
+	// Set mark "a"
+	pager.mode.onRune('m')
+	pager.mode.onRune('a')
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// Scroll away
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyDown)
+	assert.Assert(t, *pager.lineIndex() != startIndex)
+
+	// Jump back
+	pager.mode.onRune('\'')
+	pager.mode.onRune('a')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, startIndex, *pager.lineIndex())
+}
+
+func TestJumpBackAfterLargeJump(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.mode.onKey(twin.KeyDown)
+	startIndex := *pager.lineIndex()
+
+	pager.mode.onRune('G')
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	// '' should take us back to where we were before the jump...
+	pager.mode.onRune('\'')
+	pager.mode.onRune('\'')
+	assert.Equal(t, startIndex, *pager.lineIndex())
+
+	// ... and once more should take us back to the end again
+	pager.mode.onRune('\'')
+	pager.mode.onRune('\'')
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestJumpBackAfterGotoLine(t *testing.T) {
+	pager := createThreeLinesPager(t)
+
+	gotoLine(pager, "4")
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	pager.mode.onRune('\'')
+	pager.mode.onRune('\'')
+	assert.Assert(t, pager.lineIndex().IsZero())
+}
+
+func TestMarksResetOnFileSwitch(t *testing.T) {
+	pager := NewPager(
+		reader.NewFromTextForTesting("first", "a1\na2"),
+		reader.NewFromTextForTesting("second", "b1\nb2"),
+	)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.mode.onRune('m')
+	pager.mode.onRune('a')
+	assert.Equal(t, 1, len(pager.bookmarks))
+
+	pager.nextFile()
+	assert.Equal(t, 0, len(pager.bookmarks))
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/walles/moor/v2/twin"
+	"golang.org/x/exp/maps"
+)
+
+// Lists all marks above the status bar, for picking one to jump to or to
+// remove.
+type PagerModeMarkList struct {
+	pager *Pager
+
+	// Index into marks() of the highlighted mark
+	selected int
+}
+
+// Show the list of marks, or tell the user there aren't any
+func (p *Pager) listMarks() {
+	if len(p.bookmarks) == 0 {
+		p.setMessage("No marks set, press 'm' to set one!")
+		return
+	}
+
+	p.mode = &PagerModeMarkList{pager: p}
+	p.setTargetLine(nil)
+}
+
+// All marks in alphabetical order
+func (m *PagerModeMarkList) marks() []rune {
+	marks := maps.Keys(m.pager.bookmarks)
+	sort.Slice(marks, func(i, j int) bool {
+		return marks[i] < marks[j]
+	})
+	return marks
+}
+
+// Something like "a  12: The text of line 12"
+func (m *PagerModeMarkList) describe(mark rune) string {
+	p := m.pager
+	position := p.bookmarks[mark]
+	index := position.lineIndex(p)
+	if index == nil {
+		return string(mark)
+	}
+
+	line := p.Reader().GetLine(*index)
+	if line == nil {
+		return string(mark)
+	}
+
+	return fmt.Sprintf("%c  %s: %s", mark, line.Number.Format(), line.Plain())
+}
+
+func (m *PagerModeMarkList) drawFooter(_ string, _ string) {
+	p := m.pager
+	width, height := p.screen.Size()
+
+	marks := m.marks()
+
+	// Leave room for the status bar, and scroll the list if needed to keep the
+	// selected mark visible
+	rowCount := min(len(marks), height-1)
+	first := max(0, m.selected-rowCount+1)
+	for row := range rowCount {
+		style := twin.StyleDefault
+		if first+row == m.selected {
+			style = style.WithAttr(twin.AttrReverse)
+		}
+
+		screenRow := height - 1 - rowCount + row
+		pos := 0
+		for _, char := range m.describe(marks[first+row]) {
+			if pos >= width {
+				break
+			}
+			pos += p.screen.SetCell(pos, screenRow, twin.NewStyledRune(char, style))
+		}
+		for pos < width {
+			pos += p.screen.SetCell(pos, screenRow, twin.NewStyledRune(' ', style))
+		}
+	}
+
+	p.setFooter("Marks", "'ENTER' jumps, 'DELETE' removes the mark, 'ESC' cancels")
+}
+
+func (m *PagerModeMarkList) onKey(key twin.KeyCode) {
+	p := m.pager
+
+	switch key {
+	case twin.KeyUp:
+		m.selected = max(0, m.selected-1)
+
+	case twin.KeyDown:
+		m.selected = min(len(p.bookmarks)-1, m.selected+1)
+
+	case twin.KeyEnter:
+		destination := p.bookmarks[m.marks()[m.selected]]
+		p.mode = PagerModeViewing{pager: p}
+		p.rememberPosition()
+		p.scrollPosition = destination
+
+	case twin.KeyDelete, twin.KeyBackspace:
+		delete(p.bookmarks, m.marks()[m.selected])
+		if len(p.bookmarks) == 0 {
+			p.mode = PagerModeViewing{pager: p}
+			return
+		}
+		m.selected = min(m.selected, len(p.bookmarks)-1)
+
+	case twin.KeyEscape:
+		p.mode = PagerModeViewing{pager: p}
+	}
+}
+
+func (m *PagerModeMarkList) onRune(char rune) {
+	switch char {
+	case 'k':
+		m.onKey(twin.KeyUp)
+	case 'j':
+		m.onKey(twin.KeyDown)
+	case 'q':
+		m.onKey(twin.KeyEscape)
+	}
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestMarkList(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	typeRunes(pager, "2jma5jmb4j") // Mark lines 3 and 8, end up on line 12
+	assert.Equal(t, 11, pager.lineIndex().Index())
+
+	typeRunes(pager, "M")
+	assert.Equal(t, "MarkList", modeName(pager))
+	rows := screenRows(pager)
+	assert.Equal(t, rows[3], "a  3: line 3")
+	assert.Equal(t, rows[4], "b  8: line 8")
+	assert.Equal(t, pager.screen.(*twin.FakeScreen).GetRow(3)[0].Style, twin.StyleDefault.WithAttr(twin.AttrReverse))
+
+	// Select "b" and jump there
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 7, pager.lineIndex().Index())
+
+	// We should be able to go back to where we were
+	typeRunes(pager, "''")
+	assert.Equal(t, 11, pager.lineIndex().Index())
+}
+
+func TestMarkListCancel(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	typeRunes(pager, "2jma5jmb4j") // Mark lines 3 and 8, end up on line 12
+	assert.Equal(t, 11, pager.lineIndex().Index())
+
+	typeRunes(pager, "Mj")
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 11, pager.lineIndex().Index())
+}
+
+func TestMarkListDelete(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	typeRunes(pager, "2jma5jmb4j") // Mark lines 3 and 8, end up on line 12
+	assert.Equal(t, 11, pager.lineIndex().Index())
+
+	typeRunes(pager, "M")
+	pager.mode.onKey(twin.KeyDelete)
+	assert.Equal(t, "MarkList", modeName(pager))
+	assert.Equal(t, screenRows(pager)[4], "b  8: line 8")
+	_, found := pager.bookmarks['a']
+	assert.Assert(t, !found)
+
+	// Removing the last mark closes the list
+	pager.mode.onKey(twin.KeyDelete)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, len(pager.bookmarks), 0)
+}
+
+func TestMarkListNoMarks(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "M")
+	assert.Equal(t, "Info", modeName(pager))
+}
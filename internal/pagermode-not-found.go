@@ -1,6 +1,48 @@
 package internal
 
-import "github.com/walles/moor/v2/twin"
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/twin"
+)
+
+// How to tell the user that a search found nothing or wrapped around
+type SearchFeedback int
+
+const (
+	SearchFeedbackNone SearchFeedback = iota
+	SearchFeedbackBell
+	SearchFeedbackFlash
+)
+
+// What to do when searching again after reaching the end of the input
+type SearchWrap int
+
+const (
+	// Show "Not found", then searching again restarts from the other end
+	SearchWrapAuto SearchWrap = iota
+
+	// Show "Not found", and keep showing that if the user searches again
+	SearchWrapNever
+
+	// Ask the user whether to restart from the other end
+	SearchWrapConfirm
+)
+
+// Time to go back to viewing mode after showing "Not found", see
+// Pager.NotFoundTimeout
+type eventNotFoundTimeout struct {
+	generation int
+}
+
+// How long flashing keeps the screen inverted
+const flashDuration = 100 * time.Millisecond
+
+// Time to make the screen normal again after flashing it
+type eventFlashDone struct {
+	generation int
+}
 
 type PagerModeNotFound struct {
 	pager *Pager
@@ -16,18 +58,116 @@ func (m PagerModeNotFound) onKey(key twin.KeyCode) {
 }
 
 func (m PagerModeNotFound) onRune(char rune) {
-	switch char {
-
-	// Should match the pagermode-viewing.go next-search-hit bindings
-	case 'n':
+	// Searching again from here restarts the search from the other end, so
+	// those keys need handling here rather than in viewing mode
+	switch m.pager.KeyBindings.runes[char] {
+	case "searchNext":
 		m.pager.scrollToNextSearchHit()
 
-	// Should match the pagermode-viewing.go previous-search-hit bindings
-	case 'p', 'N':
+	case "searchPrevious":
 		m.pager.scrollToPreviousSearchHit()
 
+	case "searchAgain":
+		m.pager.repeatSearch(false)
+
+	case "searchAgainReversed":
+		m.pager.repeatSearch(true)
+
 	default:
 		m.pager.mode = PagerModeViewing(m)
 		m.pager.mode.onRune(char)
 	}
 }
+
+// Give up searching and tell the user
+func (p *Pager) setNotFound() {
+	p.mode = PagerModeNotFound{pager: p}
+	p.giveSearchFeedback()
+
+	// Any not-found timeout from before is for some older search
+	p.notFoundGeneration++
+	if p.NotFoundTimeout <= 0 {
+		// Sticky, wait for the user to do something
+		return
+	}
+
+	event := eventNotFoundTimeout{generation: p.notFoundGeneration}
+	events := p.screen.Events()
+	time.AfterFunc(p.NotFoundTimeout, func() {
+		select {
+		case events <- event:
+		default:
+			// Event queue full, "Not found" will stay until the user does
+			// something
+		}
+	})
+}
+
+// Go back to viewing mode, unless the user has already left not-found mode or
+// this timeout is for an earlier search
+func (p *Pager) onNotFoundTimeout(generation int) {
+	if generation != p.notFoundGeneration || !p.isNotFound() {
+		return
+	}
+
+	p.mode = PagerModeViewing{pager: p}
+}
+
+// There are no more search hits in this direction. Depending on SearchWrap,
+// either tell the user or ask about searching from the other end.
+func (p *Pager) searchReachedEnd(direction SearchDirection) {
+	if p.SearchWrap == SearchWrapConfirm {
+		p.mode = PagerModeConfirmWrap{pager: p, direction: direction}
+		p.giveSearchFeedback()
+		return
+	}
+
+	p.setNotFound()
+}
+
+// Ring the bell or flash the screen, depending on SearchFeedback
+func (p *Pager) giveSearchFeedback() {
+	switch p.SearchFeedback {
+	case SearchFeedbackNone:
+		// Just the footer then
+	case SearchFeedbackBell:
+		if beller, ok := p.screen.(twin.Beller); ok {
+			beller.Bell()
+		}
+	case SearchFeedbackFlash:
+		p.flash()
+	}
+}
+
+// Invert the screen, and have the main loop make it normal again after
+// flashDuration
+func (p *Pager) flash() {
+	flasher, ok := p.screen.(twin.Flasher)
+	if !ok {
+		return
+	}
+
+	flasher.Flash(true)
+
+	p.flashGeneration++
+	event := eventFlashDone{generation: p.flashGeneration}
+	events := p.screen.Events()
+	time.AfterFunc(flashDuration, func() {
+		select {
+		case events <- event:
+		default:
+			log.Warn("Unable to deliver eventFlashDone, event queue full")
+		}
+	})
+}
+
+// Make the screen normal again, unless there has been another flash since
+func (p *Pager) onFlashDone(generation int) {
+	if generation != p.flashGeneration {
+		return
+	}
+
+	if flasher, ok := p.screen.(twin.Flasher); ok {
+		flasher.Flash(false)
+	}
+}
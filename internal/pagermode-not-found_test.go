@@ -2,6 +2,7 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	"github.com/walles/moor/v2/internal/reader"
 	"github.com/walles/moor/v2/twin"
@@ -48,3 +49,132 @@ func TestWrapSearchBackwards(t *testing.T) {
 	assert.Equal(t, pager.scrollPosition.lineIndex(pager).Index(), 2)
 	assert.Assert(t, pager.isViewing())
 }
+
+func TestSearchFeedback(t *testing.T) {
+	for _, feedback := range []SearchFeedback{SearchFeedbackNone, SearchFeedbackBell, SearchFeedbackFlash} {
+		pager := createThreeLinesPager(t)
+		pager.SearchFeedback = feedback
+		screen := pager.screen.(*twin.FakeScreen)
+		pager.scrollToEnd()
+
+		// Search for "a", it's on the first line (ref createThreeLinesPager())
+		pager.searchString = "a"
+		pager.searchPattern = toPattern(pager.searchString)
+
+		// Not found
+		pager.scrollToNextSearchHit()
+		assert.Equal(t, "NotFound", modeName(pager))
+
+		expected := 0
+		if feedback != SearchFeedbackNone {
+			expected = 1
+		}
+		assert.Equal(t, expected, screen.GetBellCount()+screen.GetFlashCount(), "feedback=%d", feedback)
+
+		// Wrap
+		pager.scrollToNextSearchHit()
+		assert.Equal(t, "Viewing", modeName(pager))
+		assert.Equal(t, 2*expected, screen.GetBellCount()+screen.GetFlashCount(), "feedback=%d", feedback)
+
+		if feedback == SearchFeedbackBell {
+			assert.Equal(t, 0, screen.GetFlashCount())
+		}
+		if feedback == SearchFeedbackFlash {
+			assert.Equal(t, 0, screen.GetBellCount())
+		}
+	}
+}
+
+// Flashing should leave the screen inverted until the flash is done, and only
+// the latest flash should make it normal again
+func TestSearchFeedbackFlashRestores(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	screen := pager.screen.(*twin.FakeScreen)
+
+	pager.flash()
+	assert.Assert(t, screen.IsInverted())
+	firstFlash := pager.flashGeneration
+
+	pager.flash()
+	pager.onFlashDone(firstFlash)
+	assert.Assert(t, screen.IsInverted())
+
+	pager.onFlashDone(pager.flashGeneration)
+	assert.Assert(t, !screen.IsInverted())
+	assert.Equal(t, 2, screen.GetFlashCount())
+}
+
+// Wrapping around backwards, and finding nothing after wrapping, should give
+// feedback once each
+func TestSearchFeedbackBackwards(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.SearchFeedback = SearchFeedbackBell
+	screen := pager.screen.(*twin.FakeScreen)
+
+	pager.searchString = "x"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	// Already at the top
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+	assert.Equal(t, 1, screen.GetBellCount())
+
+	// Wraps, but there is nothing to find
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+	assert.Equal(t, 2, screen.GetBellCount())
+}
+
+func TestNotFoundStickyByDefault(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.setNotFound()
+	assert.Assert(t, pager.isNotFound())
+
+	// A stray timeout from somewhere shouldn't change anything
+	pager.onNotFoundTimeout(pager.notFoundGeneration - 1)
+	assert.Assert(t, pager.isNotFound())
+
+	// The next key press takes us back to viewing
+	pager.mode.onRune('j')
+	assert.Assert(t, pager.isViewing())
+}
+
+func TestNotFoundTimeout(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.NotFoundTimeout = time.Hour
+
+	pager.setNotFound()
+	assert.Assert(t, pager.isNotFound())
+
+	pager.onNotFoundTimeout(pager.notFoundGeneration)
+	assert.Assert(t, pager.isViewing())
+}
+
+func TestNotFoundTimeoutFromEarlierSearch(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.NotFoundTimeout = time.Hour
+
+	pager.setNotFound()
+	earlier := pager.notFoundGeneration
+	pager.setNotFound()
+
+	// The first timeout should not cut the second "Not found" short
+	pager.onNotFoundTimeout(earlier)
+	assert.Assert(t, pager.isNotFound())
+
+	pager.onNotFoundTimeout(pager.notFoundGeneration)
+	assert.Assert(t, pager.isViewing())
+}
+
+func TestNotFoundTimeoutAfterLeaving(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.NotFoundTimeout = time.Hour
+
+	pager.setNotFound()
+	pager.mode = NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+
+	// Timing out should not interrupt whatever the user is doing now
+	pager.onNotFoundTimeout(pager.notFoundGeneration)
+	_, isSearching := pager.mode.(*PagerModeSearch)
+	assert.Assert(t, isSearching)
+}
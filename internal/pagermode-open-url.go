@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"regexp"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Pick one of the URLs on screen to open in the browser. 'o' goes to the next
+// URL, RETURN opens it and anything else cancels.
+type PagerModeOpenUrl struct {
+	pager *Pager
+	urls  []string
+	index int
+
+	// The URL at index stands out, and all URLs are underlined. Prepared by
+	// withIndex() so that we don't compile regexps while rendering.
+	highlights []reader.PatternHighlight
+}
+
+// Start picking a URL, starting with the topmost one on screen
+func (p *Pager) startOpeningUrl() {
+	urls := p.visibleUrls()
+	if len(urls) == 0 {
+		p.setMessage("No URLs on screen")
+		return
+	}
+
+	p.mode = PagerModeOpenUrl{pager: p, urls: urls}.withIndex(0)
+}
+
+// Pick the URL at index
+func (m PagerModeOpenUrl) withIndex(index int) PagerModeOpenUrl {
+	m.index = index
+	m.highlights = []reader.PatternHighlight{
+		{
+			Pattern: regexp.MustCompile(regexp.QuoteMeta(m.urls[index])),
+			Style:   urlStyle().WithAttr(twin.AttrReverse),
+		},
+		{
+			Pattern: urlPattern,
+			Style:   urlStyle(),
+		},
+	}
+	return m
+}
+
+func (m PagerModeOpenUrl) drawFooter(_ string, _ string) {
+	m.pager.setFooter("Open "+m.urls[m.index]+"? RETURN opens, 'o' for the next URL, ESC cancels", "")
+}
+
+func (m PagerModeOpenUrl) onKey(key twin.KeyCode) {
+	p := m.pager
+	p.mode = PagerModeViewing{pager: p}
+
+	if key == twin.KeyEnter {
+		p.openUrl(m.urls[m.index])
+	}
+}
+
+func (m PagerModeOpenUrl) onRune(char rune) {
+	p := m.pager
+	if char != 'o' {
+		p.mode = PagerModeViewing{pager: p}
+		return
+	}
+
+	p.mode = m.withIndex((m.index + 1) % len(m.urls))
+}
@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Asks for a shell command to pipe the current input through, like '|' in
+// less. The command output is shown instead of the input until the user pipes
+// through an empty command or switches files.
+type PagerModePipe struct {
+	pager    *Pager
+	inputBox *InputBox
+}
+
+func NewPagerModePipe(p *Pager) *PagerModePipe {
+	return &PagerModePipe{
+		pager: p,
+		inputBox: &InputBox{
+			accept: INPUTBOX_ACCEPT_ALL,
+		},
+	}
+}
+
+func (m *PagerModePipe) drawFooter(_ string, _ string) {
+	help := "'ENTER' submits, 'ESC' cancels"
+	if m.pager.pipedReader != nil {
+		help = "Submit nothing to restore the original, " + help
+	}
+	m.inputBox.draw(m.pager.screen, help, "Pipe through: ")
+}
+
+func (m *PagerModePipe) onKey(key twin.KeyCode) {
+	if m.inputBox.handleKey(key) {
+		return
+	}
+
+	switch key {
+	case twin.KeyEnter:
+		m.pager.mode = PagerModeViewing{pager: m.pager}
+		command := strings.TrimSpace(m.inputBox.text)
+		if command == "" {
+			m.pager.restoreUnpiped()
+		} else {
+			m.pager.pipeThrough(command)
+		}
+
+	case twin.KeyEscape:
+		m.pager.mode = PagerModeViewing{pager: m.pager}
+
+	default:
+		log.Debugf("Unhandled pipe key event %v", key)
+	}
+}
+
+func (m *PagerModePipe) onRune(char rune) {
+	m.inputBox.handleRune(char)
+}
+
+// Shows that a pipe command is running, ESC cancels it. See pipeThrough().
+type PagerModePiping struct {
+	pager   *Pager
+	command string
+	cancel  context.CancelFunc
+
+	// Receives the result once the command is done
+	done chan pipeResult
+}
+
+type pipeResult struct {
+	reader *reader.ReaderImpl
+
+	// Set on failure, for the user to see
+	message string
+}
+
+// A pipe command is done, see PagerModePiping.onPipeDone()
+type eventPipeDone struct {
+	mode *PagerModePiping
+}
+
+func (m *PagerModePiping) drawFooter(_ string, _ string) {
+	m.pager.setFooter("Piping through: "+m.command, "'ESC' cancels")
+}
+
+func (m *PagerModePiping) onKey(key twin.KeyCode) {
+	if key != twin.KeyEscape {
+		log.Debugf("Ignoring key event %v while piping", key)
+		return
+	}
+
+	log.Info("Cancelling pipe command: ", m.command)
+	m.cancel()
+	m.pager.mode = PagerModeViewing{pager: m.pager}
+}
+
+func (m *PagerModePiping) onRune(char rune) {
+	log.Debugf("Ignoring rune '%c' while piping", char)
+}
+
+// Show the command output, or why it failed. Blocks until the command is done.
+func (m *PagerModePiping) onPipeDone() {
+	result := <-m.done
+	m.cancel()
+
+	if result.reader == nil {
+		m.pager.setMessage(m.command + ": " + result.message)
+		return
+	}
+
+	m.pager.mode = PagerModeViewing{pager: m.pager}
+	m.pager.setPipedReader(result.reader)
+}
+
+// Run the current input through a shell command and show the output instead.
+// On failure, the input stays and the user gets to see why.
+//
+// The command runs in the background, so that the user can cancel it.
+func (p *Pager) pipeThrough(command string) {
+	if os.Getenv("LESSSECURE") == "1" {
+		p.setMessage("Not running commands since LESSSECURE=1 is set in the environment")
+		return
+	}
+
+	// Piping again pipes what's on screen, not the original input
+	source := p.pipedReader
+	if source == nil {
+		p.readerLock.Lock()
+		source = p.readers[p.currentReader]
+		p.readerLock.Unlock()
+	}
+
+	var input strings.Builder
+	for _, line := range source.GetLines(linemetadata.Index{}, math.MaxInt).Lines {
+		input.WriteString(line.Line.Unformatted())
+		input.WriteString("\n")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mode := &PagerModePiping{
+		pager:   p,
+		command: command,
+		cancel:  cancel,
+		done:    make(chan pipeResult, 1),
+	}
+	p.mode = mode
+
+	events := p.screen.Events()
+	go func() {
+		defer func() {
+			PanicHandler("pipeThrough()", recover(), debug.Stack())
+		}()
+
+		mode.done <- runPipeCommand(ctx, command, input.String())
+		events <- eventPipeDone{mode: mode}
+	}()
+}
+
+func runPipeCommand(ctx context.Context, command string, input string) pipeResult {
+	shell := []string{"sh", "-c"}
+	if runtime.GOOS == "windows" {
+		shell = []string{"cmd", "/C"}
+	}
+
+	log.Info("Piping input through: ", command)
+	cmd := exec.CommandContext(ctx, shell[0], append(shell[1:], command)...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		log.Info("Pipe command failed: ", err, ", stderr: ", stderr.String())
+
+		// The first line of stderr is usually the most helpful one
+		message := strings.TrimSpace(stderr.String())
+		message, _, _ = strings.Cut(message, "\n")
+		if message == "" {
+			message = err.Error()
+		}
+		return pipeResult{message: message}
+	}
+
+	// Nobody will ask this reader for more lines, so it must read everything
+	// without pausing
+	pauseAfterLines := math.MaxInt
+	pipedReader, err := reader.NewFromStream("| "+command, &stdout, nil, reader.ReaderOptions{
+		PauseAfterLines: &pauseAfterLines,
+		NoHighlighting:  true,
+	})
+	if err != nil {
+		return pipeResult{message: err.Error()}
+	}
+
+	for !pipedReader.ReadingDone.Load() || !pipedReader.HighlightingDone.Load() {
+		<-pipedReader.MaybeDone
+	}
+
+	return pipeResult{reader: pipedReader}
+}
+
+// Go back to showing the input from before piping
+func (p *Pager) restoreUnpiped() {
+	if p.pipedReader == nil {
+		return
+	}
+
+	p.setPipedReader(nil)
+}
+
+// Show pipedReader instead of the current input, or the input again if nil
+func (p *Pager) setPipedReader(pipedReader *reader.ReaderImpl) {
+	p.pipedReader = pipedReader
+
+	// Filters, marks and positions are about the previous contents
+	p.filterPattern = nil
+	p.bookmarks = make(map[rune]scrollPosition)
+	p.scrollPosition = newScrollPosition("setPipedReader")
+	p.handleScrolledUp()
+
+	if pipedReader != nil {
+		p.filteringReader.SetBackingReader(pipedReader)
+		return
+	}
+
+	p.readerLock.Lock()
+	p.filteringReader.SetBackingReader(p.readers[p.currentReader])
+	p.readerLock.Unlock()
+}
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func pipeThrough(pager *Pager, command string) {
+	pager.mode.onRune('|')
+	typeRunes(pager, command)
+	pager.mode.onKey(twin.KeyEnter)
+
+	if piping, ok := pager.mode.(*PagerModePiping); ok {
+		// Wait for the command to finish
+		piping.onPipeDone()
+	}
+}
+
+func TestPipeThrough(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "charlie", "alfa", "bravo")
+
+	pipeThrough(pager, "sort")
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.DeepEqual(t, []string{"alfa", "bravo", "charlie"}, screenRows(pager)[:3])
+
+	// Piping again pipes what's on screen
+	pipeThrough(pager, "head -n 1")
+	assert.DeepEqual(t, []string{"alfa", "---"}, screenRows(pager)[:2])
+
+	// An empty command restores the original
+	pipeThrough(pager, "")
+	assert.DeepEqual(t, []string{"charlie", "alfa", "bravo"}, screenRows(pager)[:3])
+}
+
+func TestPipeThroughFailure(t *testing.T) {
+	pager := createLinesPager(t, 40, 4, "charlie", "alfa", "bravo")
+
+	pipeThrough(pager, "echo oops >&2; exit 1")
+	assert.Equal(t, "echo oops >&2; exit 1: oops", pager.mode.(*PagerModeInfo).Text)
+	assert.DeepEqual(t, []string{"charlie", "alfa", "bravo"}, screenRows(pager)[:3])
+}
+
+func TestPipeThroughSecure(t *testing.T) {
+	t.Setenv("LESSSECURE", "1")
+	pager := createLinesPager(t, 20, 4, "charlie", "alfa", "bravo")
+
+	pipeThrough(pager, "sort")
+	assert.Equal(t, "Info", modeName(pager))
+	assert.DeepEqual(t, []string{"charlie", "alfa", "bravo"}, screenRows(pager)[:3])
+}
+
+// Tabs and other control characters must reach the command as they are
+func TestPipeThroughRaw(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "a\tb", "\x1b[1mbold\x1b[0m")
+
+	pipeThrough(pager, "tr '\\t' X")
+	assert.DeepEqual(t, []string{"aXb", "bold"}, screenRows(pager)[:2])
+}
+
+func TestPipeThroughCancel(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "charlie", "alfa", "bravo")
+
+	pager.mode.onRune('|')
+	typeRunes(pager, "sleep 10; sort")
+	pager.mode.onKey(twin.KeyEnter)
+	piping := pager.mode.(*PagerModePiping)
+
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// The result of the cancelled command must not show up
+	result := <-piping.done
+	assert.Assert(t, result.reader == nil)
+	assert.DeepEqual(t, []string{"charlie", "alfa", "bravo"}, screenRows(pager)[:3])
+}
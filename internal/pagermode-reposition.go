@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Move the current line to the top, center or bottom of the screen, like Vim's
+// zt, zz and zb.
+//
+// The current line is the line number typed before 'z', as in "15zz". If no
+// number was typed it's the last search hit jumped to if that's still on
+// screen, otherwise the top line.
+type PagerModeReposition struct {
+	pager *Pager
+
+	// Line number typed before 'z', 0 if none
+	count int
+}
+
+func (m PagerModeReposition) drawFooter(_ string, _ string) {
+	p := m.pager
+
+	_, height := p.screen.Size()
+
+	pos := 0
+	for _, token := range "Press 't' for top, 'z' for center or 'b' for bottom: " {
+		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(token, twin.StyleDefault))
+	}
+
+	// Add a cursor
+	p.screen.SetCell(pos, height-1, twin.NewStyledRune(' ', twin.StyleDefault.WithAttr(twin.AttrReverse)))
+}
+
+func (m PagerModeReposition) onKey(key twin.KeyCode) {
+	p := m.pager
+
+	switch key {
+	case twin.KeyEnter, twin.KeyEscape:
+		// Never mind I
+		p.mode = PagerModeViewing{pager: p}
+
+	default:
+		// Never mind II
+		p.mode = PagerModeViewing{pager: p}
+		p.mode.onKey(key)
+	}
+}
+
+func (m PagerModeReposition) onRune(char rune) {
+	p := m.pager
+	p.mode = PagerModeViewing{pager: p}
+
+	switch char {
+	case 't':
+		p.repositionCurrentLine(m.count, 0)
+	case 'z':
+		p.repositionCurrentLine(m.count, 1)
+	case 'b':
+		p.repositionCurrentLine(m.count, 2)
+	default:
+		// Never mind III, anything else cancels and does its usual thing
+		p.mode.onRune(char)
+	}
+}
+
+// The line zt, zz and zb move around when no line number was typed
+func (p *Pager) currentLine() *linemetadata.Index {
+	if p.currentSearchHit != nil {
+		for _, line := range p.renderLines().lines {
+			if line.inputLineIndex == *p.currentSearchHit {
+				return p.currentSearchHit
+			}
+		}
+	}
+
+	return p.lineIndex()
+}
+
+// Put a line at the top (halves == 0), center (halves == 1) or bottom (halves
+// == 2) of the screen. A count of 0 means the current line, otherwise it's a
+// one based line number.
+func (p *Pager) repositionCurrentLine(count int, halves int) {
+	var index linemetadata.Index
+	if count > 0 {
+		index = linemetadata.IndexFromOneBased(count)
+	} else {
+		current := p.currentLine()
+		if current == nil {
+			// No lines
+			return
+		}
+		index = *current
+	}
+
+	// Put the line at the top, then see how many screen lines it needs
+	p.scrollPosition = NewScrollPositionFromIndex(index, "repositionCurrentLine")
+	p.setTargetLine(nil)
+
+	height := 0
+	for _, line := range p.renderLines().lines {
+		if line.inputLineIndex == index {
+			height++
+		}
+	}
+	height = max(1, height)
+
+	// Near the top or bottom of the input, canonicalization will clamp this
+	// for us
+	rowsAbove := max(0, p.visibleHeight()-height) * halves / 2
+	p.scrollPosition = p.scrollPosition.PreviousLine(rowsAbove)
+	p.applyScrollOff(index)
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+// 20 lines, five of them visible at a time
+func TestRepositionCenter(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	typeRunes(pager, "10j")
+	assert.Equal(t, "line 11", screenRows(pager)[0])
+
+	typeRunes(pager, "zz")
+
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "line 11", screenRows(pager)[2])
+}
+
+func TestRepositionTopAndBottom(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "10zb")
+	assert.Equal(t, "line 10", screenRows(pager)[4])
+
+	typeRunes(pager, "zt")
+	assert.Equal(t, "line 6", screenRows(pager)[0], "Top line should have been current")
+
+	typeRunes(pager, "12zt")
+	assert.Equal(t, "line 12", screenRows(pager)[0])
+
+	typeRunes(pager, "12zz")
+	assert.Equal(t, "line 12", screenRows(pager)[2])
+}
+
+func TestRepositionClampsAtEnds(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "zz")
+	assert.Equal(t, "line 1", screenRows(pager)[0])
+
+	typeRunes(pager, "19zt")
+	assert.Equal(t, "line 16", screenRows(pager)[0])
+	assert.Equal(t, "line 20", screenRows(pager)[4])
+}
+
+func TestRepositionSearchHit(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.searchString = "line 15"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.scrollToNextSearchHit()
+
+	typeRunes(pager, "zt")
+	assert.Equal(t, 14, pager.lineIndex().Index())
+}
+
+func TestRepositionCancel(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	typeRunes(pager, "10j")
+
+	pager.mode.onRune('z')
+	assert.Equal(t, "Reposition", modeName(pager))
+
+	// Anything but t, z or b cancels, and then does what it usually does
+	pager.mode.onRune('j')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "line 12", screenRows(pager)[0])
+
+	// Same thing for keys
+	pager.mode.onRune('z')
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "line 13", screenRows(pager)[0])
+
+	// Except for ESC, which just cancels
+	pager.mode.onRune('z')
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "line 13", screenRows(pager)[0])
+}
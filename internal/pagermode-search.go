@@ -2,6 +2,7 @@ package internal
 
 import (
 	"regexp"
+	"time"
 	"unicode"
 
 	log "github.com/sirupsen/logrus"
@@ -15,6 +16,19 @@ const (
 	SearchDirectionBackward SearchDirection = true
 )
 
+// With at least this many lines, we wait for the user to stop typing before
+// searching. A var rather than a const so that tests can change it.
+var searchDebounceMinLines = 100_000
+
+// How long the user has to stop typing before we search a large input
+const searchDebounceDelay = 150 * time.Millisecond
+
+// A debounced search is due, see PagerModeSearch.scheduleSearch()
+type eventSearchDebounced struct {
+	mode       *PagerModeSearch
+	generation int
+}
+
 type PagerModeSearch struct {
 	pager                 *Pager
 	initialScrollPosition scrollPosition // Pager position before search started
@@ -22,6 +36,19 @@ type PagerModeSearch struct {
 	inputBox              *InputBox
 	searchHistoryIndex    int
 	userEditedText        string
+
+	// Text waiting for the user to stop typing before we search for it, nil
+	// if no search is pending
+	pendingSearch *string
+	debounceTimer *time.Timer
+
+	// Bumped whenever a pending search is replaced or cancelled, so that we
+	// can ignore events from timers that fired anyway
+	searchGeneration int
+
+	// Set while the search text is not a valid regexp. The pager keeps
+	// showing the hits for the last valid one meanwhile.
+	badPattern bool
 }
 
 func NewPagerModeSearch(p *Pager, direction SearchDirection, initialScrollPosition scrollPosition) *PagerModeSearch {
@@ -34,23 +61,108 @@ func NewPagerModeSearch(p *Pager, direction SearchDirection, initialScrollPositi
 	m.inputBox = &InputBox{
 		accept: INPUTBOX_ACCEPT_ALL,
 		onTextChanged: func(text string) {
-			m.updateSearchPattern(text)
+			m.scheduleSearch(text)
 		},
 	}
 	return m
 }
 
 func (m PagerModeSearch) drawFooter(_ string, _ string) {
-	prompt := "Search: "
+	prompt := "Search"
 	if m.direction == SearchDirectionBackward {
-		prompt = "Search backwards: "
+		prompt = "Search backwards"
+	}
+	if m.pager.searchCaseSensitive != nil {
+		if *m.pager.searchCaseSensitive {
+			prompt += " [case]"
+		} else {
+			prompt += " [nocase]"
+		}
+	}
+	if m.pager.searchLiteral {
+		prompt += " [literal]"
+	}
+	if m.pager.searchWholeWords {
+		prompt += " [words]"
+	}
+	if m.badPattern {
+		prompt += " [bad pattern]"
+	}
+	prompt += ": "
+
+	if m.pager.SearchPreview {
+		m.pager.drawSearchPreview()
+	}
+
+	m.inputBox.draw(m.pager.screen, "Type to search, 'ENTER' submits, 'ESC' cancels, '↑↓' navigate history, 'CTRL-t' toggles case, 'CTRL-r' toggles regexp, 'CTRL-w' toggles whole words, 'CTRL-p' toggles preview", prompt)
+}
+
+// Search right away for small inputs. For large inputs, wait until the user
+// stops typing, so that we don't scan the whole input on every keystroke.
+func (m *PagerModeSearch) scheduleSearch(text string) {
+	if m.pager.Reader().GetLineCount() < searchDebounceMinLines {
+		m.updateSearchPattern(text)
+		return
+	}
+
+	m.cancelPendingSearch()
+	m.pendingSearch = &text
+
+	event := eventSearchDebounced{mode: m, generation: m.searchGeneration}
+	events := m.pager.screen.Events()
+	m.debounceTimer = time.AfterFunc(searchDebounceDelay, func() {
+		select {
+		case events <- event:
+		default:
+			// Event queue full, the user is typing faster than we can keep
+			// up with. Some later keypress will search.
+		}
+	})
+}
+
+func (m *PagerModeSearch) cancelPendingSearch() {
+	if m.debounceTimer != nil {
+		m.debounceTimer.Stop()
+		m.debounceTimer = nil
+	}
+	m.pendingSearch = nil
+	m.searchGeneration++
+}
+
+// The user stopped typing, do any search that's still relevant
+func (m *PagerModeSearch) onSearchDebounced(generation int) {
+	if generation != m.searchGeneration || m.pendingSearch == nil {
+		// Replaced or cancelled since
+		return
+	}
+
+	m.updateSearchPattern(*m.pendingSearch)
+}
+
+// Do any pending search right away
+func (m *PagerModeSearch) flushPendingSearch() {
+	if m.pendingSearch != nil {
+		m.updateSearchPattern(*m.pendingSearch)
 	}
-	m.inputBox.draw(m.pager.screen, "Type to search, 'ENTER' submits, 'ESC' cancels, '↑↓' navigate history", prompt)
 }
 
 func (m *PagerModeSearch) updateSearchPattern(text string) {
+	m.cancelPendingSearch()
+
+	m.badPattern = !m.pager.searchLiteral && !isValidRegexp(text)
+	if m.badPattern {
+		// Probably a half typed regexp like "foo(", don't lose the current
+		// hits before the user is done typing
+		return
+	}
+
+	m.search(text)
+}
+
+func (m *PagerModeSearch) search(text string) {
 	m.pager.searchString = text
-	m.pager.searchPattern = toPattern(text)
+	m.pager.searchPattern = m.pager.toSearchPattern(text)
+	m.pager.currentSearchHit = nil
 
 	switch m.direction {
 	case SearchDirectionBackward:
@@ -60,6 +172,73 @@ func (m *PagerModeSearch) updateSearchPattern(text string) {
 	}
 }
 
+// Flip case sensitivity for the current search, overriding smart case until
+// the search is dismissed.
+func (m *PagerModeSearch) toggleCaseSensitivity() {
+	caseSensitive := !isSmartCaseSensitive(m.pager.searchString)
+	if m.pager.searchCaseSensitive != nil {
+		caseSensitive = !*m.pager.searchCaseSensitive
+	}
+	m.pager.searchCaseSensitive = &caseSensitive
+
+	m.updateSearchPattern(m.inputBox.text)
+}
+
+// Switch between interpreting the search string as a regexp and matching it
+// verbatim.
+func (m *PagerModeSearch) toggleLiteral() {
+	m.pager.searchLiteral = !m.pager.searchLiteral
+	m.updateSearchPattern(m.inputBox.text)
+}
+
+// Switch between matching anywhere and only matching whole words.
+func (m *PagerModeSearch) toggleWholeWords() {
+	m.pager.searchWholeWords = !m.pager.searchWholeWords
+	m.updateSearchPattern(m.inputBox.text)
+}
+
+// Leave search mode. Any case sensitivity override is dropped, but the current
+// search pattern and direction are kept for 'n' / 'N' / 'p'.
+func (m *PagerModeSearch) dismiss() {
+	m.flushPendingSearch()
+	if m.badPattern {
+		// Not a regexp, search for the text verbatim like toPattern() does
+		m.search(m.inputBox.text)
+	}
+	m.pager.searchHistory.addEntry(m.inputBox.text)
+	m.pager.searchCaseSensitive = nil
+	m.pager.searchDirection = m.direction
+	m.pager.mode = PagerModeViewing{pager: m.pager}
+}
+
+// Compile a search string into a pattern, honoring any case sensitivity
+// override, literal mode and whole words mode the user has set while searching.
+func (p *Pager) toSearchPattern(compileMe string) *regexp.Regexp {
+	caseSensitive := isSmartCaseSensitive(compileMe)
+	if p.searchCaseSensitive != nil {
+		caseSensitive = *p.searchCaseSensitive
+	}
+
+	return toPatternWithOptions(compileMe, caseSensitive, p.searchLiteral, p.searchWholeWords)
+}
+
+func isValidRegexp(text string) bool {
+	_, err := regexp.Compile(text)
+	return err == nil
+}
+
+// Smart case; be case insensitive unless there are upper case chars in the
+// search string
+func isSmartCaseSensitive(searchString string) bool {
+	for _, char := range searchString {
+		if unicode.IsUpper(char) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // toPattern compiles a search string into a pattern.
 //
 // If the string contains only lower-case letter the pattern will be case insensitive.
@@ -68,31 +247,42 @@ func (m *PagerModeSearch) updateSearchPattern(text string) {
 //
 // If the string does not compile into a regexp the pattern will match the string verbatim
 func toPattern(compileMe string) *regexp.Regexp {
+	return toPatternWithOptions(compileMe, isSmartCaseSensitive(compileMe), false, false)
+}
+
+// Like toPattern(), but with case sensitivity decided by the caller rather than
+// by smart case.
+//
+// If literal is set, the search string will always be matched verbatim, even if
+// it is a valid regexp.
+//
+// If wholeWords is set, the pattern will only match at word boundaries.
+func toPatternWithOptions(compileMe string, caseSensitive bool, literal bool, wholeWords bool) *regexp.Regexp {
 	if len(compileMe) == 0 {
 		return nil
 	}
 
-	hasUppercase := false
-	for _, char := range compileMe {
-		if unicode.IsUpper(char) {
-			hasUppercase = true
-		}
-	}
-
-	// Smart case; be case insensitive unless there are upper case chars
-	// in the search string
 	prefix := "(?i)"
-	if hasUppercase {
+	if caseSensitive {
 		prefix = ""
 	}
 
-	pattern, err := regexp.Compile(prefix + compileMe)
-	if err == nil {
-		// Search string is a regexp
-		return pattern
+	wordsPrefix := ""
+	wordsSuffix := ""
+	if wholeWords {
+		wordsPrefix = `\b(?:`
+		wordsSuffix = `)\b`
 	}
 
-	pattern, err = regexp.Compile(prefix + regexp.QuoteMeta(compileMe))
+	if !literal {
+		pattern, err := regexp.Compile(prefix + wordsPrefix + compileMe + wordsSuffix)
+		if err == nil {
+			// Search string is a regexp
+			return pattern
+		}
+	}
+
+	pattern, err := regexp.Compile(prefix + wordsPrefix + regexp.QuoteMeta(compileMe) + wordsSuffix)
 	if err == nil {
 		// Pattern matching the string exactly
 		return pattern
@@ -133,17 +323,17 @@ func (m *PagerModeSearch) onKey(key twin.KeyCode) {
 
 	switch key {
 	case twin.KeyEnter:
-		m.pager.searchHistory.addEntry(m.inputBox.text)
-		m.pager.mode = PagerModeViewing{pager: m.pager}
+		m.dismiss()
+		m.pager.bookmarks['\''] = m.initialScrollPosition
 
 	case twin.KeyEscape:
-		m.pager.searchHistory.addEntry(m.inputBox.text)
-		m.pager.mode = PagerModeViewing{pager: m.pager}
+		// No point in searching, we're going back to where we were anyway
+		m.cancelPendingSearch()
+		m.dismiss()
 		m.pager.scrollPosition = m.initialScrollPosition
 
 	case twin.KeyPgUp, twin.KeyPgDown:
-		m.pager.searchHistory.addEntry(m.inputBox.text)
-		m.pager.mode = PagerModeViewing{pager: m.pager}
+		m.dismiss()
 		m.pager.mode.onKey(key)
 
 	case twin.KeyUp:
@@ -158,6 +348,23 @@ func (m *PagerModeSearch) onKey(key twin.KeyCode) {
 }
 
 func (m *PagerModeSearch) onRune(char rune) {
+	if char == '\x14' { // CTRL-t
+		m.toggleCaseSensitivity()
+		return
+	}
+	if char == '\x12' { // CTRL-r
+		m.toggleLiteral()
+		return
+	}
+	if char == '\x17' { // CTRL-w
+		m.toggleWholeWords()
+		return
+	}
+	if char == '\x10' { // CTRL-p
+		m.pager.SearchPreview = !m.pager.SearchPreview
+		return
+	}
+
 	m.searchHistoryIndex = len(m.pager.searchHistory.entries) // Reset history index when user types
 	m.inputBox.handleRune(char)
 	m.userEditedText = m.inputBox.text
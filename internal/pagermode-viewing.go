@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/linemetadata"
 	"github.com/walles/moor/v2/internal/textstyles"
 	"github.com/walles/moor/v2/twin"
 )
@@ -17,14 +18,19 @@ func (m PagerModeViewing) drawFooter(statusText string, spinner string) {
 	colonHelp := ""
 	m.pager.readerLock.Lock()
 	if len(m.pager.readers) > 1 {
-		prefix = fmt.Sprintf("[%d/%d] ", m.pager.currentReader+1, len(m.pager.readers))
+		prefix = fmt.Sprintf("(file %d of %d) ", m.pager.currentReader+1, len(m.pager.readers))
 		colonHelp = "':' to switch, "
 	}
 	m.pager.readerLock.Unlock()
 
+	if sourceName := m.pager.topLineSourceName(); sourceName != "" && m.pager.StatusBarFormat == "" {
+		// With a custom format, the user decides whether to show this, see %f
+		prefix += sourceName + ": "
+	}
+
 	searchHelp := "'/' to search"
 	if len(m.pager.searchString) > 0 {
-		searchHelp = "'n'/'p' to search next/previous"
+		searchHelp = "'n'/'N' to search same/opposite direction"
 	}
 	helpText := "Press 'ESC' / 'q' to exit, " + colonHelp + searchHelp + ", '&' to filter, 'h' for help"
 
@@ -33,6 +39,15 @@ func (m PagerModeViewing) drawFooter(statusText string, spinner string) {
 		prefix = ""
 	}
 
+	if hitCount := m.pager.searchHitCountText(); hitCount != "" && m.pager.StatusBarFormat == "" {
+		statusText += "  " + hitCount
+	}
+
+	if m.pager.WrapLongLines && m.pager.StatusBarFormat == "" {
+		// With a custom format, the user decides whether to show this
+		statusText += "  [wrap]"
+	}
+
 	if m.pager.ShowStatusBar {
 		if len(spinner) > 0 {
 			spinner = "  " + spinner
@@ -44,191 +59,147 @@ func (m PagerModeViewing) drawFooter(statusText string, spinner string) {
 func (m PagerModeViewing) onKey(keyCode twin.KeyCode) {
 	p := m.pager
 
-	switch keyCode {
-	case twin.KeyEscape:
-		p.Quit()
+	count := p.countPrefix
+	p.countPrefix = 0
 
-	case twin.KeyUp:
-		// Clipping is done in _Redraw()
-		p.scrollPosition = p.scrollPosition.PreviousLine(1)
-		p.handleScrolledUp()
+	if keyCode == twin.KeyEscape && count > 0 {
+		// Just drop the count, don't quit
+		return
+	}
 
-	case twin.KeyDown, twin.KeyEnter:
-		// Clipping is done in _Redraw()
-		p.scrollPosition = p.scrollPosition.NextLine(1)
-		p.handleScrolledDown()
+	action := p.KeyBindings.keyAction(keyCode)
+	if action == nil {
+		log.Debugf("Unhandled key event %v", keyCode)
+		return
+	}
 
-	case twin.KeyRight:
-		p.moveRight(p.SideScrollAmount)
+	action(p, count)
+}
 
-	case twin.KeyLeft:
-		p.moveRight(-p.SideScrollAmount)
+func (m PagerModeViewing) onRune(char rune) {
+	p := m.pager
 
-	case twin.KeyAltRight:
-		p.moveRight(1)
+	if char >= '0' && char <= '9' {
+		p.addCountDigit(char)
+		return
+	}
 
-	case twin.KeyAltLeft:
-		p.moveRight(-1)
+	// Any non-digit key consumes the count, whether it uses it or not
+	count := p.countPrefix
+	p.countPrefix = 0
 
-	case twin.KeyHome:
-		p.scrollPosition = newScrollPosition("Pager scroll position")
-		p.handleScrolledUp()
+	action := p.KeyBindings.runeAction(char)
+	if action == nil {
+		log.Debugf("Unhandled rune keypress '%s'/0x%08x", string(char), int32(char))
+		return
+	}
 
-	case twin.KeyEnd:
-		p.scrollToEnd()
+	action(p, count)
+}
 
-	case twin.KeyPgUp:
-		p.scrollPosition = p.scrollPosition.PreviousLine(p.visibleHeight())
-		p.handleScrolledUp()
+// Add another digit to the count typed before a motion key, as in "10j"
+func (p *Pager) addCountDigit(digit rune) {
+	if p.countPrefix > 1_000_000 {
+		// Don't overflow, nobody needs counts this large anyway
+		return
+	}
 
-	case twin.KeyPgDown:
-		p.scrollPosition = p.scrollPosition.NextLine(p.visibleHeight())
-		p.handleScrolledDown()
+	p.countPrefix = p.countPrefix*10 + int(digit-'0')
+}
 
-	default:
-		log.Debugf("Unhandled key event %v", keyCode)
-	}
+// How many screen lines to move when scrolling a full page. PageOverlap lines
+// from the old page are kept on screen for context.
+func (p *Pager) pageScrollDistance() int {
+	return max(1, p.visibleHeight()-max(0, p.PageOverlap))
 }
 
-func (m PagerModeViewing) onRune(char rune) {
-	p := m.pager
+// How many screen lines to move when scrolling half a page
+func (p *Pager) halfPageScrollDistance() int {
+	return max(1, p.visibleHeight()/2)
+}
 
-	switch char {
-	case 'q':
-		p.Quit()
+// Scroll so that the given line is at the top of the screen
+func (p *Pager) goToLine(index linemetadata.Index) {
+	p.scrollPosition = NewScrollPositionFromIndex(index, "goToLine")
+	p.applyScrollOff(index)
+	p.setTargetLine(&index)
+}
 
-	case 'v':
-		handleEditingRequest(p)
+func (p *Pager) showHelp() {
+	if p.isShowingHelp {
+		return
+	}
 
-	case 'h':
-		if p.isShowingHelp {
-			break
-		}
+	p.preHelpState = &_PreHelpState{
+		scrollPosition:      p.scrollPosition,
+		leftColumnZeroBased: p.leftColumnZeroBased,
+		targetLine:          p.TargetLine,
+	}
+	p.scrollPosition = newScrollPosition("Pager scroll position")
+	p.leftColumnZeroBased = 0
+	p.setTargetLine(nil)
+	p.isShowingHelp = true
+}
 
-		p.preHelpState = &_PreHelpState{
-			scrollPosition:      p.scrollPosition,
-			leftColumnZeroBased: p.leftColumnZeroBased,
-			targetLine:          p.TargetLine,
-		}
-		p.scrollPosition = newScrollPosition("Pager scroll position")
-		p.leftColumnZeroBased = 0
-		p.setTargetLine(nil)
-		p.isShowingHelp = true
-
-	case '=':
-		p.ShowStatusBar = !p.ShowStatusBar
-
-	// '\x10' = CTRL-p, should scroll up one line.
-	// Ref: https://github.com/walles/moor/issues/107#issuecomment-1328354080
-	case 'k', 'y', '\x10':
-		// Clipping is done in _Redraw()
-		p.scrollPosition = p.scrollPosition.PreviousLine(1)
-		p.handleScrolledUp()
-
-	// '\x0e' = CTRL-n, should scroll down one line.
-	// Ref: https://github.com/walles/moor/issues/107#issuecomment-1328354080
-	case 'j', 'e', '\x0e':
-		// Clipping is done in _Redraw()
-		p.scrollPosition = p.scrollPosition.NextLine(1)
-		p.handleScrolledDown()
-
-	case '<':
-		p.scrollPosition = newScrollPosition("Pager scroll position")
-		p.handleScrolledUp()
-
-	case '>', 'G':
-		p.scrollToEnd()
-
-	case 'f', ' ':
-		p.scrollPosition = p.scrollPosition.NextLine(p.visibleHeight())
-		p.handleScrolledDown()
-
-	case 'b':
-		p.scrollPosition = p.scrollPosition.PreviousLine(p.visibleHeight())
-		p.handleScrolledUp()
-
-	// '\x15' = CTRL-u, should work like just 'u'.
-	// Ref: https://github.com/walles/moor/issues/90
-	case 'u', '\x15':
-		p.scrollPosition = p.scrollPosition.PreviousLine(p.visibleHeight() / 2)
-		p.handleScrolledUp()
-
-	// '\x04' = CTRL-d, should work like just 'd'.
-	// Ref: https://github.com/walles/moor/issues/90
-	case 'd', '\x04':
-		p.scrollPosition = p.scrollPosition.NextLine(p.visibleHeight() / 2)
-		p.handleScrolledDown()
-
-	case '/':
-		p.mode = NewPagerModeSearch(p, SearchDirectionForward, p.scrollPosition)
-		p.setTargetLine(nil)
-		p.searchString = ""
-		p.searchPattern = nil
-
-	case '?':
-		p.mode = NewPagerModeSearch(p, SearchDirectionBackward, p.scrollPosition)
-		p.setTargetLine(nil)
-		p.searchString = ""
-		p.searchPattern = nil
-
-	case '&':
-		if !p.isShowingHelp {
-			// Filtering the help text is not supported. Feel free to work on
-			// that if you feel that's time well spent.
-			p.mode = NewPagerModeFilter(p)
-			p.searchString = ""
-			p.searchPattern = nil
-			p.filterPattern = nil
-		}
+func (p *Pager) toggleWrapLongLines() {
+	// Keep the same input line at the top of the screen. Just flipping the
+	// flag would have us keep the same number of screen lines into the top
+	// line, which could take us to some other line after re-wrapping.
+	lineIndex := p.lineIndex()
 
-	case 'g':
-		p.mode = NewPagerModeGotoLine(p)
-		p.setTargetLine(nil)
+	p.WrapLongLines = !p.WrapLongLines
+	if lineIndex != nil {
+		p.scrollPosition = NewScrollPositionFromIndex(*lineIndex, "toggleWrapLongLines")
+	}
 
-	case ':':
-		if len(p.readers) > 1 {
-			p.mode = &PagerModeColonCommand{pager: p}
-			p.setTargetLine(nil)
-		} else {
-			p.mode = &PagerModeInfo{Pager: p, Text: "Pass more files on the command line to be able to switch between them."}
-		}
+	if p.WrapLongLines {
+		p.setMessage("Word wrapping enabled")
+	} else {
+		p.setMessage("Word wrapping disabled")
+	}
+}
 
-	// Should match the pagermode-not-found.go previous-search-hit bindings
-	case 'n':
-		p.scrollToNextSearchHit()
+func (p *Pager) toggleSqueezeBlankLines() {
+	// Keep the same input line at the top of the screen. If that line gets
+	// squeezed away, we'll end up at the next visible line instead.
+	lineIndex := p.lineIndex()
 
-	// Should match the pagermode-not-found.go next-search-hit bindings
-	case 'p', 'N':
-		p.scrollToPreviousSearchHit()
+	p.SqueezeBlankLines = !p.SqueezeBlankLines
+	if lineIndex != nil {
+		p.scrollPosition = NewScrollPositionFromIndex(*lineIndex, "toggleSqueezeBlankLines")
+	}
 
-	case 'm':
-		p.mode = PagerModeMark{pager: p}
-		p.setTargetLine(nil)
+	if p.SqueezeBlankLines {
+		p.setMessage("Squeezing blank lines")
+	} else {
+		p.setMessage("Showing all blank lines")
+	}
+}
 
-	case '\'':
-		p.mode = PagerModeJumpToMark{pager: p}
-		p.setTargetLine(nil)
+func (p *Pager) toggleDimNonMatchingLines() {
+	p.DimNonMatchingLines = !p.DimNonMatchingLines
 
-	case 'w':
-		p.WrapLongLines = !p.WrapLongLines
-		if p.WrapLongLines {
-			p.mode = &PagerModeInfo{Pager: p, Text: "Word wrapping enabled"}
-		} else {
-			p.mode = &PagerModeInfo{Pager: p, Text: "Word wrapping disabled"}
-		}
+	if p.DimNonMatchingLines {
+		p.setMessage("Dimming lines without search hits")
+	} else {
+		p.setMessage("Not dimming any lines")
+	}
+}
 
-	case '\x14': // CTRL-t
-		p.cycleTabSize()
+func (p *Pager) toggleShowEscapes() {
+	// Showing escape codes makes lines longer, keep the same input line at the
+	// top of the screen even if wrapping changes
+	lineIndex := p.lineIndex()
 
-	case '\x01': // CTRL-a
-		p.leftColumnZeroBased = 0
-		if !p.showLineNumbers {
-			// Line numbers not visible, turn them on if the user wants them.
-			p.showLineNumbers = p.ShowLineNumbers
-		}
+	p.ShowEscapes = !p.ShowEscapes
+	if lineIndex != nil {
+		p.scrollPosition = NewScrollPositionFromIndex(*lineIndex, "toggleShowEscapes")
+	}
 
-	default:
-		log.Debugf("Unhandled rune keypress '%s'/0x%08x", string(char), int32(char))
+	if p.ShowEscapes {
+		p.setMessage("Showing escape codes as text")
+	} else {
+		p.setMessage("Interpreting escape codes")
 	}
 }
 
@@ -243,5 +214,5 @@ func (p *Pager) cycleTabSize() {
 	}
 	textstyles.TabSize = p.TabSize
 
-	p.mode = &PagerModeInfo{Pager: p, Text: fmt.Sprintf("Tab size set to %d", p.TabSize)}
+	p.setMessage(fmt.Sprintf("Tab size set to %d", p.TabSize))
 }
@@ -2,7 +2,11 @@ package internal
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
 )
 
 func TestErrUnlessExecutable_yes(t *testing.T) {
@@ -30,3 +34,232 @@ func TestErrUnlessExecutable_no(t *testing.T) {
 		t.Fatal("Expected error, got nil")
 	}
 }
+
+func typeRunes(pager *Pager, runes string) {
+	for _, char := range runes {
+		pager.mode.onRune(char)
+	}
+}
+
+func TestScrollStepLines(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.ScrollStepLines = 3
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	// Counts are in steps
+	typeRunes(pager, "2j")
+	assert.Equal(t, 9, pager.lineIndex().Index())
+
+	pager.mode.onKey(twin.KeyUp)
+	assert.Equal(t, 6, pager.lineIndex().Index())
+
+	// Clamp at the edges
+	typeRunes(pager, "5k")
+	assert.Equal(t, 0, pager.lineIndex().Index())
+	typeRunes(pager, "10j")
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestCountPrefixScrollsLines(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "10j")
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	typeRunes(pager, "3k")
+	assert.Equal(t, 7, pager.lineIndex().Index())
+
+	// No count means one line
+	typeRunes(pager, "j")
+	assert.Equal(t, 8, pager.lineIndex().Index())
+}
+
+func TestCountPrefixArrowKeys(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "4")
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
+
+func TestCountPrefixGoToLine(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "5G")
+	assert.Equal(t, 4, pager.lineIndex().Index())
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	typeRunes(pager, "12g")
+	assert.Equal(t, 11, pager.lineIndex().Index())
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// The mark should take us back to line 5
+	typeRunes(pager, "''")
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
+
+func TestNoCountPrefix(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	// G with no count goes to the end...
+	typeRunes(pager, "G")
+	assert.Equal(t, 15, pager.lineIndex().Index())
+
+	// ... and g with no count prompts for a line number
+	typeRunes(pager, "g")
+	assert.Equal(t, "GotoLine", modeName(pager))
+}
+
+func TestCountPrefixSearchHits(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	offset := 0
+	pager.SearchJumpOffset = &offset
+
+	// Far enough apart that only one hit is visible at a time
+	pager.searchString = "^line (6|11|16)$"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	typeRunes(pager, "3n")
+	assert.Equal(t, "line 16", pager.Reader().GetLine(*pager.lineIndex()).Plain())
+}
+
+func TestCountPrefixResetByOtherKey(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	// '=' doesn't take a count, so the count should be dropped
+	typeRunes(pager, "5=j")
+	assert.Equal(t, 1, pager.lineIndex().Index())
+}
+
+func TestCountPrefixResetByEscape(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	typeRunes(pager, "5")
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Assert(t, !pager.quit, "ESC should only drop the count")
+
+	typeRunes(pager, "j")
+	assert.Equal(t, 1, pager.lineIndex().Index())
+}
+
+func TestToggleWrapKeepsTopLine(t *testing.T) {
+	pager := createLinesPager(t, 10, 4,
+		"short",
+		"a long line that wraps into several screen lines",
+		"short again",
+		"x", "y", "z")
+	pager.WrapLongLines = true
+
+	// Scroll into the middle of the long line
+	typeRunes(pager, "3j")
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	assert.Equal(t, 2, pager.deltaScreenLines())
+
+	typeRunes(pager, "w")
+	assert.Assert(t, !pager.WrapLongLines)
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	assert.Equal(t, 0, pager.deltaScreenLines())
+
+	typeRunes(pager, "w")
+	assert.Assert(t, pager.WrapLongLines)
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	assert.Equal(t, 0, pager.deltaScreenLines())
+}
+
+func TestWrapStateInStatusBar(t *testing.T) {
+	pager := createLinesPager(t, 80, 4, "a", "b", "c", "d", "e")
+
+	rows := screenRows(pager)
+	assert.Assert(t, !strings.Contains(rows[len(rows)-1], "[wrap]"))
+
+	// Toggle wrapping on, then get rid of the info message
+	typeRunes(pager, "w")
+	pager.mode = PagerModeViewing{pager: pager}
+
+	rows = screenRows(pager)
+	assert.Assert(t, strings.Contains(rows[len(rows)-1], "[wrap]"), rows[len(rows)-1])
+}
+
+func TestFullPageScrollingOverlap(t *testing.T) {
+	pager := createLinesPager(t, 20, 11, numberedLines("line ", 1, 50)...)
+
+	for _, key := range []rune{'f', ' ', '\x06'} {
+		pager.scrollPosition = newScrollPosition("test")
+		pager.mode.onRune(key)
+
+		// One line of overlap with the previous page
+		assert.Equal(t, 9, pager.lineIndex().Index(), "key %q", key)
+		assert.Equal(t, "line 10", screenRows(pager)[0], "key %q", key)
+	}
+
+	pager.mode.onKey(twin.KeyPgDown)
+	assert.Equal(t, 18, pager.lineIndex().Index())
+
+	pager.mode.onRune('b')
+	assert.Equal(t, 9, pager.lineIndex().Index())
+
+	pager.mode.onRune('\x02') // CTRL-b
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	pager.mode.onKey(twin.KeyPgDown)
+	pager.mode.onKey(twin.KeyPgUp)
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestFullPageScrollingConfiguredOverlap(t *testing.T) {
+	pager := createLinesPager(t, 20, 11, numberedLines("line ", 1, 50)...)
+
+	pager.PageOverlap = 0
+	pager.mode.onRune('f')
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	pager.PageOverlap = 3
+	pager.mode.onRune('f')
+	assert.Equal(t, 17, pager.lineIndex().Index())
+
+	// An overlap bigger than the screen should still move us
+	pager.PageOverlap = 100
+	pager.mode.onRune('b')
+	assert.Equal(t, 16, pager.lineIndex().Index())
+}
+
+func TestHalfPageScrolling(t *testing.T) {
+	pager := createLinesPager(t, 20, 11, numberedLines("line ", 1, 50)...)
+
+	pager.mode.onRune('\x04') // CTRL-d
+	assert.Equal(t, 5, pager.lineIndex().Index())
+
+	pager.mode.onRune('d')
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	pager.mode.onRune('\x15') // CTRL-u
+	assert.Equal(t, 5, pager.lineIndex().Index())
+
+	// The overlap setting is for full pages only
+	pager.PageOverlap = 3
+	pager.mode.onRune('u')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestPageScrollingClampsAtEnds(t *testing.T) {
+	pager := createLinesPager(t, 20, 11, numberedLines("line ", 1, 50)...)
+
+	pager.mode.onRune('b')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+	pager.mode.onRune('u')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	for range 10 {
+		pager.mode.onRune('f')
+	}
+	assert.Equal(t, 50-10, pager.lineIndex().Index())
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	pager.mode.onRune('d')
+	assert.Equal(t, 50-10, pager.lineIndex().Index())
+
+	pager.mode.onRune('b')
+	assert.Equal(t, 50-10-9, pager.lineIndex().Index())
+}
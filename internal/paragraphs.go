@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+// Paragraphs are separated by blank lines, like in vi. Whitespace-only lines
+// count as blank.
+func (p *Pager) isBlankLine(index linemetadata.Index) bool {
+	line := p.Reader().GetLine(index)
+	return line != nil && strings.TrimSpace(line.Plain()) == ""
+}
+
+// Starting after from, skip any blank lines, then return the first blank line
+// after those. Returns nil if we hit the start or end of the input first.
+func (p *Pager) findParagraphBoundary(from linemetadata.Index, direction SearchDirection) *linemetadata.Index {
+	step := 1
+	if direction == SearchDirectionBackward {
+		step = -1
+	}
+
+	lineCount := p.Reader().GetLineCount()
+	index := from.Index() + step
+
+	// Skip blank lines between us and the paragraph
+	for index >= 0 && index < lineCount && p.isBlankLine(linemetadata.IndexFromZeroBased(index)) {
+		index += step
+	}
+
+	for ; index >= 0 && index < lineCount; index += step {
+		candidate := linemetadata.IndexFromZeroBased(index)
+		if p.isBlankLine(candidate) {
+			return &candidate
+		}
+	}
+
+	return nil
+}
+
+// Put the blank line after the next paragraph at the top of the screen. At the
+// end of the input, go to the end and tell the user.
+func (p *Pager) scrollToNextParagraph() {
+	current := p.lineIndex()
+	if current == nil {
+		// No lines
+		return
+	}
+
+	boundary := p.findParagraphBoundary(*current, SearchDirectionForward)
+	p.rememberPosition()
+	if boundary == nil {
+		p.scrollToEnd()
+		p.setMessage("No more paragraphs below")
+		return
+	}
+
+	p.scrollPosition = NewScrollPositionFromIndex(*boundary, "scrollToNextParagraph")
+	p.handleScrolledDown()
+}
+
+// Put the blank line before the previous paragraph at the top of the screen.
+// At the start of the input, go to the start and tell the user.
+func (p *Pager) scrollToPreviousParagraph() {
+	current := p.lineIndex()
+	if current == nil {
+		// No lines
+		return
+	}
+
+	boundary := p.findParagraphBoundary(*current, SearchDirectionBackward)
+	p.rememberPosition()
+	if boundary == nil {
+		p.scrollPosition = newScrollPosition("scrollToPreviousParagraph")
+		p.handleScrolledUp()
+		p.setMessage("No more paragraphs above")
+		return
+	}
+
+	p.scrollPosition = NewScrollPositionFromIndex(*boundary, "scrollToPreviousParagraph")
+	p.handleScrolledUp()
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var paragraphLines = []string{
+	"first 1",  // 0
+	"first 2",  // 1
+	"",         // 2
+	"  ",       // 3, whitespace only counts as blank
+	"second 1", // 4
+	"second 2", // 5
+	"\t",       // 6
+	"third 1",  // 7
+	"third 2",  // 8
+	"third 3",  // 9
+	"",         // 10
+	"fourth 1", // 11
+	"fourth 2", // 12
+	"fourth 3", // 13
+	"fourth 4", // 14
+	"fourth 5", // 15
+}
+
+func TestNextParagraph(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, paragraphLines...)
+
+	pager.mode.onRune('}')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	// Skips the second blank line
+	pager.mode.onRune('}')
+	assert.Equal(t, 6, pager.lineIndex().Index())
+
+	// With a count
+	typeRunes(pager, "1}")
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	// Past the last paragraph, we should go to the end and say so
+	pager.mode.onRune('}')
+	assert.Equal(t, "fourth 5", screenRows(pager)[1])
+	assert.Equal(t, "No more paragraphs below", pager.mode.(*PagerModeInfo).Text)
+}
+
+func TestPreviousParagraph(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, paragraphLines...)
+	pager.scrollToEnd()
+
+	pager.mode.onRune('{')
+	assert.Equal(t, 10, pager.lineIndex().Index())
+
+	typeRunes(pager, "2{")
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	// Past the first paragraph, we should go to the start and say so
+	pager.mode.onRune('{')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+	assert.Equal(t, "No more paragraphs above", pager.mode.(*PagerModeInfo).Text)
+}
+
+// Jumping back with two single quotes should undo paragraph jumps
+func TestParagraphJumpsRemembered(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, paragraphLines...)
+
+	pager.mode.onRune('}')
+	pager.mode.onRune('}')
+	assert.Equal(t, 6, pager.lineIndex().Index())
+
+	pager.mode.onRune('\'')
+	pager.mode.onRune('\'')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	// From 2 there are no more paragraphs above, so this goes to the top
+	pager.mode.onRune('{')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+	pager.mode.onRune('\'')
+	pager.mode.onRune('\'')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adrg/xdg"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+// Where the user was in each file when quitting, for going back there the next
+// time the file is opened. See Pager.RememberPositions.
+type PositionHistory struct {
+	// Empty means no positions file. Set by BootPositionHistory().
+	absFileName string
+
+	// Oldest first
+	entries []positionHistoryEntry
+}
+
+type positionHistoryEntry struct {
+	absFileName string
+	lineIndex   linemetadata.Index
+}
+
+// Should be plenty
+const maxPositionHistoryEntries = 640
+
+// Same file name semantics as BootSearchHistory(), but the default file is
+// moor/positions in the XDG data directory.
+func BootPositionHistory(fileName string) PositionHistory {
+	absFileName := resolvePositionsFilePath(fileName)
+	if absFileName == "" {
+		return PositionHistory{}
+	}
+
+	entries, err := loadPositionHistory(absFileName)
+	if err != nil {
+		log.Infof("Could not load positions from %s: %v", absFileName, err)
+		// IO Error, give up
+		return PositionHistory{}
+	}
+
+	log.Debugf("Loaded %d positions from %s", len(entries), absFileName)
+	return PositionHistory{
+		absFileName: absFileName,
+		entries:     entries,
+	}
+}
+
+func resolvePositionsFilePath(fileName string) string {
+	if fileName != "" {
+		return resolveHistoryFilePath(fileName)
+	}
+
+	xdgPath, err := xdg.DataFile("moor/positions")
+	if err != nil {
+		log.Infof("Could not resolve XDG data file path for positions: %v", err)
+		return ""
+	}
+	return xdgPath
+}
+
+// Each line is a one based line number, a space and an absolute file name. A
+// missing file means no positions.
+func loadPositionHistory(absFileName string) ([]positionHistoryEntry, error) {
+	entries := []positionHistoryEntry{}
+	err := iterateFileByLines(absFileName, func(line string) {
+		numberString, fileName, found := strings.Cut(line, " ")
+		if !found || !filepath.IsAbs(fileName) {
+			log.Debugf("Ignoring malformed positions line: %s", line)
+			return
+		}
+
+		number, err := strconv.Atoi(numberString)
+		if err != nil || number < 1 {
+			log.Debugf("Ignoring malformed positions line: %s", line)
+			return
+		}
+
+		entries = append(entries, positionHistoryEntry{
+			absFileName: fileName,
+			lineIndex:   linemetadata.IndexFromOneBased(number),
+		})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return []positionHistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// The remembered position for this file, or nil if we don't have one
+func (h *PositionHistory) get(fileName string) *linemetadata.Index {
+	absFileName, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil
+	}
+
+	// Iterate backwards in case there are duplicates, the last one wins
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].absFileName == absFileName {
+			lineIndex := h.entries[i].lineIndex
+			return &lineIndex
+		}
+	}
+
+	return nil
+}
+
+// Remember a position for this file, forgetting the oldest positions if we
+// have too many. Call save() to write the positions to disk.
+func (h *PositionHistory) set(fileName string, lineIndex linemetadata.Index) {
+	absFileName, err := filepath.Abs(fileName)
+	if err != nil {
+		log.Infof("Not remembering position in %s: %v", fileName, err)
+		return
+	}
+
+	entries := make([]positionHistoryEntry, 0, len(h.entries)+1)
+	for _, entry := range h.entries {
+		if entry.absFileName != absFileName {
+			entries = append(entries, entry)
+		}
+	}
+	entries = append(entries, positionHistoryEntry{absFileName: absFileName, lineIndex: lineIndex})
+
+	h.entries = entries[max(0, len(entries)-maxPositionHistoryEntries):]
+}
+
+func (h *PositionHistory) save() {
+	if os.Getenv("LESSSECURE") == "1" {
+		// LESSSECURE=1 means not writing anything to disk
+		return
+	}
+
+	if h.absFileName == "" {
+		// No positions file configured
+		return
+	}
+
+	lines := make([]string, 0, len(h.entries))
+	for _, entry := range h.entries {
+		lines = append(lines, fmt.Sprintf("%d %s", entry.lineIndex.Index()+1, entry.absFileName))
+	}
+	writeHistoryFile(h.absFileName, lines)
+}
+
+// If the user wants it, go back to where the user was when last viewing the
+// current file. Should be called before applyStartupPosition().
+func (p *Pager) restorePosition() {
+	if !p.RememberPositions || p.TargetLine != nil || p.StartAtEnd || p.InitialSearch != "" {
+		// Nothing to do, or the user asked to start somewhere else
+		return
+	}
+
+	if p.positionHistory == nil {
+		positionHistory := BootPositionHistory("")
+		p.positionHistory = &positionHistory
+	}
+
+	p.readerLock.Lock()
+	fileName := p.readers[p.currentReader].FileName
+	p.readerLock.Unlock()
+	if fileName == nil {
+		// Not a file, nothing to restore
+		return
+	}
+
+	p.TargetLine = p.positionHistory.get(*fileName)
+}
+
+// Remember the current position in each open file for the next time
+func (p *Pager) savePositions() {
+	if !p.RememberPositions || p.positionHistory == nil {
+		return
+	}
+
+	// With a filter, lineIndex() is into the filtered lines. The line number
+	// is the same as in the unfiltered input though.
+	var currentLineIndex *linemetadata.Index
+	if index := p.lineIndex(); index != nil {
+		if line := p.Reader().GetLine(*index); line != nil {
+			original := linemetadata.IndexFromZeroBased(line.Number.AsZeroBased())
+			currentLineIndex = &original
+		}
+	}
+
+	p.readerLock.Lock()
+	defer p.readerLock.Unlock()
+
+	for i, input := range p.readers {
+		if input.FileName == nil {
+			// Not a file, nothing to remember
+			continue
+		}
+
+		lineIndex := currentLineIndex
+		if i != p.currentReader {
+			lineIndex = nil
+			if position, found := p.scrollPositions[i]; found {
+				lineIndex = position.internalDontTouch.lineIndex
+			}
+		}
+
+		if lineIndex == nil {
+			continue
+		}
+		p.positionHistory.set(*input.FileName, *lineIndex)
+	}
+
+	p.positionHistory.save()
+}
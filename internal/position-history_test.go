@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestPositionHistoryRoundTrip(t *testing.T) {
+	t.Setenv("LESSSECURE", "")
+	positionsFile := filepath.Join(t.TempDir(), "positions")
+
+	history := BootPositionHistory(positionsFile)
+	assert.Assert(t, history.get("/some/file.txt") == nil)
+
+	history.set("/some/file.txt", linemetadata.IndexFromOneBased(42))
+	history.set("/other file.txt", linemetadata.IndexFromOneBased(7))
+	history.set("/some/file.txt", linemetadata.IndexFromOneBased(43))
+	history.save()
+
+	contents, err := os.ReadFile(positionsFile)
+	assert.NilError(t, err)
+	assert.Equal(t, "7 /other file.txt\n43 /some/file.txt\n", string(contents))
+
+	reloaded := BootPositionHistory(positionsFile)
+	assert.Equal(t, linemetadata.IndexFromOneBased(43), *reloaded.get("/some/file.txt"))
+	assert.Equal(t, linemetadata.IndexFromOneBased(7), *reloaded.get("/other file.txt"))
+	assert.Assert(t, reloaded.get("/third.txt") == nil)
+}
+
+func TestPositionHistoryIgnoresBrokenLines(t *testing.T) {
+	positionsFile := filepath.Join(t.TempDir(), "positions")
+	err := os.WriteFile(positionsFile, []byte("junk\n0 /zero.txt\n5 relative.txt\n12 /ok.txt\n"), 0o600)
+	assert.NilError(t, err)
+
+	history := BootPositionHistory(positionsFile)
+	assert.Equal(t, 1, len(history.entries))
+	assert.Equal(t, linemetadata.IndexFromOneBased(12), *history.get("/ok.txt"))
+}
+
+func TestPositionHistoryLessSecure(t *testing.T) {
+	t.Setenv("LESSSECURE", "1")
+	positionsFile := filepath.Join(t.TempDir(), "positions")
+
+	history := BootPositionHistory(positionsFile)
+	history.set("/some/file.txt", linemetadata.IndexFromOneBased(42))
+	history.save()
+
+	_, err := os.Stat(positionsFile)
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestPositionHistoryLimit(t *testing.T) {
+	history := PositionHistory{}
+	for i := range maxPositionHistoryEntries + 1 {
+		history.set(fmt.Sprintf("/file%d.txt", i), linemetadata.Index{})
+	}
+
+	assert.Equal(t, maxPositionHistoryEntries, len(history.entries))
+	assert.Assert(t, history.get("/file0.txt") == nil)
+	assert.Assert(t, history.get("/file1.txt") != nil)
+}
+
+func TestRememberPositions(t *testing.T) {
+	t.Setenv("LESSSECURE", "")
+	positionsFile := filepath.Join(t.TempDir(), "positions")
+
+	fileName := filepath.Join(t.TempDir(), "file.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte(numberedText("line ", 30)), 0o600))
+
+	r, err := reader.NewFromFilename(fileName, nil, reader.ReaderOptions{NoHighlighting: true})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.RememberPositions = true
+	history := BootPositionHistory(positionsFile)
+	pager.positionHistory = &history
+
+	pager.restorePosition()
+	assert.Assert(t, pager.TargetLine == nil)
+
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromOneBased(12), "test")
+	pager.savePositions()
+
+	reloaded := BootPositionHistory(positionsFile)
+	assert.Equal(t, linemetadata.IndexFromOneBased(12), *reloaded.get(fileName))
+
+	// Open the same file again
+	r, err = reader.NewFromFilename(fileName, nil, reader.ReaderOptions{NoHighlighting: true})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+	pager = NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.RememberPositions = true
+	pager.positionHistory = &reloaded
+
+	pager.restorePosition()
+	assert.Equal(t, linemetadata.IndexFromOneBased(12), *pager.TargetLine)
+	pager.handleMoreLinesAvailable()
+	assert.Equal(t, 11, pager.lineIndex().Index())
+}
+
+func TestRememberPositionsStartAtEnd(t *testing.T) {
+	positionsFile := filepath.Join(t.TempDir(), "positions")
+	fileName := filepath.Join(t.TempDir(), "file.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte(numberedText("line ", 30)), 0o600))
+
+	r, err := reader.NewFromFilename(fileName, nil, reader.ReaderOptions{NoHighlighting: true})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.RememberPositions = true
+	history := BootPositionHistory(positionsFile)
+	pager.positionHistory = &history
+	pager.positionHistory.set(fileName, linemetadata.IndexFromOneBased(12))
+
+	// The command line wins
+	pager.StartAtEnd = true
+	pager.restorePosition()
+	assert.Assert(t, pager.TargetLine == nil)
+}
+
+func TestRememberPositionsFiltered(t *testing.T) {
+	t.Setenv("LESSSECURE", "")
+	positionsFile := filepath.Join(t.TempDir(), "positions")
+	fileName := filepath.Join(t.TempDir(), "file.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte(numberedText("line ", 30)), 0o600))
+
+	r, err := reader.NewFromFilename(fileName, nil, reader.ReaderOptions{NoHighlighting: true})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Wait())
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.RememberPositions = true
+	history := BootPositionHistory(positionsFile)
+	pager.positionHistory = &history
+	pager.mode = PagerModeViewing{pager: pager}
+
+	// Only lines with "2" in them, "line 20" is the third one
+	typeFilter(pager, "2")
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(2), "test")
+	assert.Equal(t, "line 20", pager.Reader().GetLine(*pager.lineIndex()).Plain())
+
+	pager.savePositions()
+	assert.Equal(t, linemetadata.IndexFromOneBased(20), *pager.positionHistory.get(fileName))
+}
@@ -0,0 +1,204 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+// One of the inputs to a concatenated reader
+type concatSource struct {
+	name   string
+	stream io.Reader
+}
+
+// Where in the concatenated lines some source starts
+type concatSourceStart struct {
+	name      string
+	lineIndex int
+}
+
+// Reads all sources one after the other, like "cat" does, keeping track of
+// which line each source starts on.
+type concatStream struct {
+	sources []concatSource
+
+	// If set, each source is preceded by a "==> name <==" line, like "head"
+	// and "tail" do with multiple files
+	separators bool
+
+	// The source we're currently reading from
+	current int
+
+	// Whether we have started reading from the current source
+	started bool
+
+	// Bytes to return before reading any more from the current source
+	pending []byte
+
+	// Used for finding out whether we need to add a newline between sources
+	bytesCount int64
+	lastByte   byte
+
+	lock sync.Mutex
+
+	// The number of lines we have returned so far
+	newlinesCount int
+
+	// In line index order
+	starts []concatSourceStart
+}
+
+func (s *concatStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.current >= len(s.sources) {
+			return 0, io.EOF
+		}
+
+		if !s.started {
+			s.startSource()
+			continue
+		}
+
+		n, err := s.sources[s.current].stream.Read(p)
+		if n > 0 {
+			s.count(p[:n])
+			return n, nil
+		}
+		if err == io.EOF {
+			s.current++
+			s.started = false
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.pending)
+	s.count(s.pending[:n])
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func (s *concatStream) startSource() {
+	s.started = true
+
+	if s.bytesCount > 0 && s.lastByte != '\n' {
+		// Don't join the last line of the previous source with the first line
+		// of this one
+		s.pending = append(s.pending, '\n')
+	}
+
+	name := s.sources[s.current].name
+
+	s.lock.Lock()
+	s.starts = append(s.starts, concatSourceStart{
+		name:      name,
+		lineIndex: s.newlinesCount + bytes.Count(s.pending, []byte{'\n'}),
+	})
+	s.lock.Unlock()
+
+	if s.separators {
+		s.pending = append(s.pending, fmt.Sprintf("==> %s <==\n", name)...)
+	}
+}
+
+// Keep track of what we have returned to the reader
+func (s *concatStream) count(returned []byte) {
+	if len(returned) == 0 {
+		return
+	}
+
+	s.bytesCount += int64(len(returned))
+	s.lastByte = returned[len(returned)-1]
+
+	s.lock.Lock()
+	s.newlinesCount += bytes.Count(returned, []byte{'\n'})
+	s.lock.Unlock()
+}
+
+// The name of the source the line at index came from, or "" if we don't know
+func (s *concatStream) sourceName(index linemetadata.Index) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Find the last source starting at or before index
+	i := sort.Search(len(s.starts), func(i int) bool {
+		return s.starts[i].lineIndex > index.Index()
+	})
+	if i == 0 {
+		return ""
+	}
+	return s.starts[i-1].name
+}
+
+// NewFromFilenames creates a reader showing all files as one long input, like
+// "cat file1 file2 | moor" would.
+//
+// If separators is set, each file's contents will be preceded by a line with
+// the file name on it.
+//
+// Files are decompressed as needed, but never highlighted since they can be
+// of different types. Unless options.Lexer is set, then that is used for all
+// of them.
+//
+// Note that you must call reader.SetStyleForHighlighting() after this to get
+// any highlighting.
+func NewFromFilenames(filenames []string, separators bool, formatter chroma.Formatter, options ReaderOptions) (*ReaderImpl, error) {
+	sources := make([]concatSource, 0, len(filenames))
+	for _, filename := range filenames {
+		fileError := TryOpen(filename)
+		if fileError != nil {
+			return nil, fileError
+		}
+
+		stream, _, err := ZOpen(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, concatSource{
+			name:   filepath.Base(filename),
+			stream: stream,
+		})
+	}
+
+	return newConcatenated(sources, separators, formatter, options), nil
+}
+
+func newConcatenated(sources []concatSource, separators bool, formatter chroma.Formatter, options ReaderOptions) *ReaderImpl {
+	stream := &concatStream{
+		sources:    sources,
+		separators: separators,
+	}
+
+	returnMe := newReaderFromStream(stream, nil, formatter, options)
+	returnMe.concatenated = stream
+
+	if options.Lexer == nil {
+		returnMe.HighlightingDone.Store(true)
+	}
+
+	if options.Style != nil {
+		returnMe.SetStyleForHighlighting(*options.Style)
+	}
+
+	return returnMe
+}
+
+// SourceName returns the name of the file the line at index came from, for
+// readers made by NewFromFilenames(). For other readers, this returns "".
+func (reader *ReaderImpl) SourceName(index linemetadata.Index) string {
+	if reader.concatenated == nil {
+		return ""
+	}
+
+	return reader.concatenated.sourceName(index)
+}
@@ -0,0 +1,92 @@
+package reader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+	"gotest.tools/v3/assert"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+func newTestConcatenated(separators bool) *ReaderImpl {
+	return newConcatenated([]concatSource{
+		{name: "first.txt", stream: strings.NewReader("a\nb\n")},
+
+		// No trailing newline, this should still end up on a line of its own
+		{name: "second.txt", stream: strings.NewReader("c\nd")},
+
+		{name: "third.txt", stream: strings.NewReader("e\n")},
+	}, separators, nil, ReaderOptions{Style: &chroma.Style{}})
+}
+
+func TestConcatenated(t *testing.T) {
+	reader := newTestConcatenated(false)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "a", "b", "c", "d", "e")
+	assert.Assert(t, reader.DisplayName == nil)
+
+	assert.Equal(t, "first.txt", reader.SourceName(linemetadata.IndexFromZeroBased(0)))
+	assert.Equal(t, "first.txt", reader.SourceName(linemetadata.IndexFromZeroBased(1)))
+	assert.Equal(t, "second.txt", reader.SourceName(linemetadata.IndexFromZeroBased(2)))
+	assert.Equal(t, "second.txt", reader.SourceName(linemetadata.IndexFromZeroBased(3)))
+	assert.Equal(t, "third.txt", reader.SourceName(linemetadata.IndexFromZeroBased(4)))
+}
+
+func TestConcatenatedWithSeparators(t *testing.T) {
+	reader := newTestConcatenated(true)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader,
+		"==> first.txt <==", "a", "b",
+		"==> second.txt <==", "c", "d",
+		"==> third.txt <==", "e")
+
+	// Separators belong to the source they are introducing
+	assert.Equal(t, "first.txt", reader.SourceName(linemetadata.IndexFromZeroBased(0)))
+	assert.Equal(t, "first.txt", reader.SourceName(linemetadata.IndexFromZeroBased(2)))
+	assert.Equal(t, "second.txt", reader.SourceName(linemetadata.IndexFromZeroBased(3)))
+	assert.Equal(t, "second.txt", reader.SourceName(linemetadata.IndexFromZeroBased(5)))
+	assert.Equal(t, "third.txt", reader.SourceName(linemetadata.IndexFromZeroBased(6)))
+}
+
+func TestConcatenatedEmptySource(t *testing.T) {
+	reader := newConcatenated([]concatSource{
+		{name: "empty.txt", stream: strings.NewReader("")},
+		{name: "full.txt", stream: strings.NewReader("x\n")},
+	}, true, nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "==> empty.txt <==", "==> full.txt <==", "x")
+	assert.Equal(t, "empty.txt", reader.SourceName(linemetadata.IndexFromZeroBased(0)))
+	assert.Equal(t, "full.txt", reader.SourceName(linemetadata.IndexFromZeroBased(1)))
+}
+
+func TestNewFromFilenames(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	assert.NilError(t, os.WriteFile(first, []byte("one\n"), 0o600))
+	assert.NilError(t, os.WriteFile(second, []byte("two\n"), 0o600))
+
+	reader, err := NewFromFilenames([]string{first, second}, false, nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "one", "two")
+	assert.Equal(t, "second.txt", reader.SourceName(linemetadata.IndexFromZeroBased(1)))
+}
+
+func TestNewFromFilenamesMissingFile(t *testing.T) {
+	_, err := NewFromFilenames([]string{filepath.Join(t.TempDir(), "missing.txt")}, false, nil, ReaderOptions{})
+	assert.Assert(t, err != nil)
+}
+
+func TestSourceNameNotConcatenated(t *testing.T) {
+	reader := NewFromTextForTesting("name", "a\nb")
+	assert.Equal(t, "", reader.SourceName(linemetadata.Index{}))
+}
@@ -2,6 +2,7 @@ package reader
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/walles/moor/v2/internal/linemetadata"
 	"github.com/walles/moor/v2/internal/textstyles"
@@ -14,33 +15,63 @@ type Line struct {
 	plain string
 }
 
+// A pattern to highlight with its own style, in addition to any search hits
+type PatternHighlight struct {
+	Pattern *regexp.Regexp
+	Style   twin.Style
+}
+
 // Returns a representation of the string split into styled tokens. Any regexp
 // matches are highlighted. A nil regexp means no highlighting.
+//
+// Search hits take precedence over highlights, and earlier highlights take
+// precedence over later ones.
 func (line *Line) HighlightedTokens(
 	plainTextStyle twin.Style,
 	searchHitStyle twin.Style,
 	search *regexp.Regexp,
 	lineIndex *linemetadata.Index,
+	highlights ...PatternHighlight,
 ) textstyles.StyledRunesWithTrailer {
 	matchRanges := getMatchRanges(line.Plain(), search)
 
+	highlightRanges := make([]*MatchRanges, len(highlights))
+	for i, highlight := range highlights {
+		highlightRanges[i] = getMatchRanges(line.Plain(), highlight.Pattern)
+	}
+
 	fromString := textstyles.StyledRunesFromString(plainTextStyle, line.raw, lineIndex)
+
+	// A search hit style with nothing but a background color will only
+	// override the background of the hits
+	backgroundOnly := searchHitStyle.Background() != twin.ColorDefault &&
+		searchHitStyle.Equal(twin.StyleDefault.WithBackground(searchHitStyle.Background()))
+
 	returnRunes := make([]textstyles.CellWithMetadata, 0, len(fromString.StyledRunes))
 	lastWasSearchHit := false
 	for _, token := range fromString.StyledRunes {
 		style := token.Style
 		searchHit := matchRanges.InRange(len(returnRunes))
-		if searchHit {
+		if searchHit && backgroundOnly {
+			// Keep any colors from the input, just change the background
+			style = style.WithBackground(searchHitStyle.Background())
+		} else if searchHit {
 			// Highlight the search hit
 			style = searchHitStyle
+		} else {
+			for i, ranges := range highlightRanges {
+				if ranges.InRange(len(returnRunes)) {
+					style = highlights[i].Style
+					break
+				}
+			}
 		}
 
-		returnRunes = append(returnRunes, textstyles.CellWithMetadata{
-			Rune:            token.Rune,
-			Style:           style,
-			IsSearchHit:     searchHit,
-			StartsSearchHit: searchHit && !lastWasSearchHit,
-		})
+		cell := token
+		cell.Style = style
+		cell.IsSearchHit = searchHit
+		cell.StartsSearchHit = searchHit && !lastWasSearchHit
+		returnRunes = append(returnRunes, cell)
 		lastWasSearchHit = searchHit
 	}
 
@@ -51,11 +82,35 @@ func (line *Line) HighlightedTokens(
 	}
 }
 
+// A copy of this line with any escape codes and other control characters shown
+// as text rather than being interpreted, like "cat -v" does
+func (line *Line) WithEscapesShown() Line {
+	shown := textstyles.ShowEscapes(line.raw)
+	return Line{raw: shown, plain: textstyles.StripFormatting(shown, linemetadata.Index{})}
+}
+
 // Plain returns a plain text representation of the initial string
 func (line *Line) Plain() string {
 	return line.plain
 }
 
+// The line as it came in, minus any ANSI escape codes. Unlike Plain(), tabs
+// and other control characters are kept as they are.
+func (line *Line) Unformatted() string {
+	return textstyles.StripEscapeCodes(line.raw)
+}
+
 func (line *Line) HasManPageFormatting() bool {
 	return textstyles.HasManPageFormatting(line.raw)
 }
+
+// True if the line brings its own styling, through ANSI escape codes or man
+// page formatting. Syntax highlighted lines count as styled.
+func (line *Line) HasStyling() bool {
+	return strings.ContainsRune(line.raw, '\x1b') || line.HasManPageFormatting()
+}
+
+// True if the line has a form feed in it, which is a page break for printers
+func (line *Line) HasFormFeed() bool {
+	return strings.ContainsRune(line.raw, '\f')
+}
@@ -66,3 +66,71 @@ func TestSearchHitSpanningWrapBoundary(t *testing.T) {
 		}
 	}
 }
+
+// A search hit style with only a background color should keep the colors from
+// the input.
+func TestSearchHitBackgroundOnly(t *testing.T) {
+	line := NewFromTextForTesting("TestSearchHitBackgroundOnly", "a\x1b[31mbc\x1b[0md").GetLine(linemetadata.Index{}).Line
+	blue := twin.NewColor16(4)
+	searchHitStyle := twin.StyleDefault.WithBackground(blue)
+	highlighted := line.HighlightedTokens(twin.StyleDefault, searchHitStyle, regexp.MustCompile("b"), nil)
+
+	assert.Equal(t, highlighted.StyledRunes[1].Rune, 'b')
+	assert.Assert(t, highlighted.StyledRunes[1].Style.Equal(twin.StyleDefault.WithForeground(twin.NewColor16(1)).WithBackground(blue)))
+
+	assert.Equal(t, highlighted.StyledRunes[2].Rune, 'c')
+	assert.Assert(t, highlighted.StyledRunes[2].Style.Equal(twin.StyleDefault.WithForeground(twin.NewColor16(1))))
+}
+
+// Search hits should be found even if there are color codes in the middle of
+// them, and the highlight should go on the right cells.
+func TestSearchHitAcrossColorCodes(t *testing.T) {
+	line := NewFromTextForTesting("TestSearchHitAcrossColorCodes", "foo\x1b[31mba\x1b[0mr").GetLine(linemetadata.Index{}).Line
+	searchHitStyle := twin.StyleDefault.WithAttr(twin.AttrReverse)
+	highlighted := line.HighlightedTokens(twin.StyleDefault, searchHitStyle, regexp.MustCompile("bar"), nil)
+
+	assert.Assert(t, highlighted.ContainsSearchHit)
+	assert.Equal(t, len(highlighted.StyledRunes), len("foobar"))
+	for i, cell := range highlighted.StyledRunes {
+		assert.Equal(t, cell.Rune, rune("foobar"[i]))
+		assert.Equal(t, cell.IsSearchHit, i >= len("foo"), "Column %d", i)
+		assert.Equal(t, cell.StartsSearchHit, i == len("foo"), "Column %d", i)
+	}
+
+	assert.Assert(t, highlighted.StyledRunes[2].Style.Equal(twin.StyleDefault))
+	assert.Assert(t, highlighted.StyledRunes[3].Style.Equal(searchHitStyle))
+	assert.Assert(t, highlighted.StyledRunes[5].Style.Equal(searchHitStyle))
+}
+
+// With caret notation, one input rune becomes two cells. The highlight should
+// still go on the cells of the match, and only on those.
+func TestSearchHitAfterCaretNotation(t *testing.T) {
+	defer func() { textstyles.UnprintableStyle = textstyles.UnprintableStyleHighlight }()
+	textstyles.UnprintableStyle = textstyles.UnprintableStyleCaret
+
+	for _, input := range []string{"\x01bar!", "\x7fbar!"} {
+		line := NewFromTextForTesting("TestSearchHitAfterCaretNotation", input).GetLine(linemetadata.Index{}).Line
+		searchHitStyle := twin.StyleDefault.WithAttr(twin.AttrReverse)
+		highlighted := line.HighlightedTokens(twin.StyleDefault, searchHitStyle, regexp.MustCompile("bar"), nil)
+
+		assert.Equal(t, len(highlighted.StyledRunes), len("^xbar!"), "Input %q", input)
+		for i, cell := range highlighted.StyledRunes {
+			isHit := i >= 2 && i < 5
+			assert.Equal(t, cell.IsSearchHit, isHit, "Input %q, column %d", input, i)
+			assert.Equal(t, cell.Style.Equal(searchHitStyle), isHit, "Input %q, column %d", input, i)
+		}
+	}
+}
+
+// Search hits must be found in what's shown after a carriage return, not in
+// the overwritten text
+func TestSearchHitAfterCarriageReturn(t *testing.T) {
+	line := NewFromTextForTesting("TestSearchHitAfterCarriageReturn", "10%\r100%").GetLine(linemetadata.Index{}).Line
+	assert.Equal(t, "100%", line.Plain())
+
+	highlighted := line.HighlightedTokens(twin.StyleDefault, twin.StyleDefault.WithAttr(twin.AttrReverse), regexp.MustCompile("00"), nil)
+	assert.Equal(t, len(highlighted.StyledRunes), len("100%"))
+	for i, cell := range highlighted.StyledRunes {
+		assert.Equal(t, cell.IsSearchHit, i == 1 || i == 2, "Column %d", i)
+	}
+}
@@ -1,6 +1,11 @@
 package reader
 
-import "regexp"
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/walles/moor/v2/internal/textstyles"
+)
 
 // MatchRanges collects match indices
 type MatchRanges struct {
@@ -18,7 +23,8 @@ func getMatchRanges(String string, Pattern *regexp.Regexp) *MatchRanges {
 	}
 }
 
-// Convert byte indices to rune indices
+// Convert byte indices to cell indices. Each grapheme cluster gets one cell,
+// see textstyles.StyledRunesFromString().
 func toRunePositions(byteIndices [][]int, matchedString string) [][2]int {
 	var returnMe [][2]int
 	if len(byteIndices) == 0 {
@@ -26,28 +32,44 @@ func toRunePositions(byteIndices [][]int, matchedString string) [][2]int {
 		return returnMe
 	}
 
-	runeIndex := 0
-	byteIndicesToRuneIndices := make(map[int]int, 0)
-	for byteIndex := range matchedString {
-		byteIndicesToRuneIndices[byteIndex] = runeIndex
-
-		runeIndex++
+	// For the first byte of each rune, which cell is that rune part of?
+	cellIndex := -1
+	clusterStart := 0
+	byteIndicesToCellIndices := make(map[int]int, 0)
+	for byteIndex, runeValue := range matchedString {
+		if !textstyles.ContinuesGraphemeCluster(matchedString[clusterStart:byteIndex], runeValue) {
+			clusterStart = byteIndex
+			cellIndex++
+		}
+		byteIndicesToCellIndices[byteIndex] = cellIndex
 	}
 
 	// If a match touches the end of the string, that will be encoded as one
 	// byte past the end of the string. Therefore we must add a mapping for
 	// first-index-after-the-end.
-	byteIndicesToRuneIndices[len(matchedString)] = runeIndex
+	byteIndicesToCellIndices[len(matchedString)] = cellIndex + 1
 
 	for _, bytePair := range byteIndices {
-		fromRuneIndex := byteIndicesToRuneIndices[bytePair[0]]
-		toRuneIndex := byteIndicesToRuneIndices[bytePair[1]]
-		returnMe = append(returnMe, [2]int{fromRuneIndex, toRuneIndex})
+		fromCellIndex := byteIndicesToCellIndices[bytePair[0]]
+
+		// Matches ending inside of a cluster cover all of that cluster
+		toCellIndex := byteIndicesToCellIndices[bytePair[1]]
+		if bytePair[1] < len(matchedString) && bytePair[1] > 0 &&
+			byteIndicesToCellIndices[bytePair[1]] == byteIndicesToCellIndices[previousRuneStart(matchedString, bytePair[1])] {
+			toCellIndex++
+		}
+
+		returnMe = append(returnMe, [2]int{fromCellIndex, toCellIndex})
 	}
 
 	return returnMe
 }
 
+func previousRuneStart(s string, byteIndex int) int {
+	_, size := utf8.DecodeLastRuneInString(s[:byteIndex])
+	return byteIndex - size
+}
+
 // InRange says true if the index is part of a regexp match
 func (mr *MatchRanges) InRange(index int) bool {
 	if mr == nil {
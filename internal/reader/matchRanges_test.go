@@ -87,3 +87,23 @@ func TestRealWorldBug(t *testing.T) {
 	assert.DeepEqual(t, matchRanges.Matches[1][0], 2) // Second match starts at 2
 	assert.DeepEqual(t, matchRanges.Matches[1][1], 3) // And ends on 3 exclusive
 }
+
+// Match ranges are in screen cells, and a combining accent shares a cell with
+// the letter it modifies
+func TestCombiningAccentMatchRanges(t *testing.T) {
+	line := "cafe\u0301 x"
+	matchRanges := getMatchRanges(line, regexp.MustCompile("x"))
+	assert.DeepEqual(t, [][2]int{{5, 6}}, matchRanges.Matches)
+
+	// Matching the accented letter highlights its cell
+	matchRanges = getMatchRanges(line, regexp.MustCompile("e\u0301"))
+	assert.DeepEqual(t, [][2]int{{3, 4}}, matchRanges.Matches)
+
+	// Matching only the base letter still highlights the whole cell
+	matchRanges = getMatchRanges(line, regexp.MustCompile("e"))
+	assert.DeepEqual(t, [][2]int{{3, 4}}, matchRanges.Matches)
+
+	// Matching only the accent highlights the cell it's in
+	matchRanges = getMatchRanges(line, regexp.MustCompile("\u0301"))
+	assert.DeepEqual(t, [][2]int{{3, 4}}, matchRanges.Matches)
+}
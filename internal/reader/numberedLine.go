@@ -19,14 +19,10 @@ func (nl *NumberedLine) Plain() string {
 	return nl.Line.Plain()
 }
 
-func (nl *NumberedLine) HighlightedTokens(plainTextStyle twin.Style, searchHitStyle twin.Style, search *regexp.Regexp) textstyles.StyledRunesWithTrailer {
-	return nl.Line.HighlightedTokens(plainTextStyle, searchHitStyle, search, &nl.Index)
+func (nl *NumberedLine) HighlightedTokens(plainTextStyle twin.Style, searchHitStyle twin.Style, search *regexp.Regexp, highlights ...PatternHighlight) textstyles.StyledRunesWithTrailer {
+	return nl.Line.HighlightedTokens(plainTextStyle, searchHitStyle, search, &nl.Index, highlights...)
 }
 
 func (nl *NumberedLine) DisplayWidth() int {
-	width := 0
-	for _, r := range nl.Plain() {
-		width += uniseg.StringWidth(string(r))
-	}
-	return width
+	return uniseg.StringWidth(nl.Plain())
 }
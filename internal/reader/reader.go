@@ -43,12 +43,29 @@ type ReaderOptions struct {
 	// nil means 20k lines.
 	PauseAfterLines *int
 
+	// How often to check files for changes after reading them.
+	//
+	// nil means once per second.
+	TailInterval *time.Duration
+
 	// If this is nil, you must call reader.SetStyleForHighlighting() later if
 	// you want highlighting.
 	Style *chroma.Style
 
 	// If this is set, it will be used as the lexer for highlighting
 	Lexer chroma.Lexer
+
+	// Don't syntax highlight the input. Any ANSI formatting already in the
+	// input is still shown.
+	NoHighlighting bool
+
+	// Read at most this many lines, then stop reading as if the input ended
+	// there. 0 means no limit.
+	MaxLines int
+
+	// With MaxLines, keep reading but drop the oldest lines, so that only the
+	// last MaxLines lines are kept. Like "tail -n".
+	KeepLastLines bool
 }
 
 type Reader interface {
@@ -66,11 +83,19 @@ type Reader interface {
 	// When we're not paused, the number will be constantly changing, indicating
 	// that the counting is not done yet.
 	ShouldShowLineCount() bool
+
+	// True while more lines are being read. Until this goes false, the line
+	// count and anything based on it are just what we have so far.
+	IsLoading() bool
 }
 
 type line struct {
 	raw            string
 	plainTextCache *string // Use line.Plain() to access this field
+
+	// Tabs are expanded in the plain text, so the cache is only valid for the
+	// tab size it was made with
+	plainTextCacheTabSize int
 }
 
 // ReaderImpl reads a file into an array of strings.
@@ -98,6 +123,13 @@ type ReaderImpl struct {
 	// How many bytes have we read so far?
 	bytesCount int64
 
+	// Byte offset into the input where each line starts, in the same order as
+	// the lines. Nil if unknown, which happens if the input was reformatted.
+	lineOffsets []int64
+
+	// Byte offset just after the last line in lineOffsets
+	lineOffsetsEnd int64
+
 	endsWithNewline bool
 
 	Err error
@@ -120,6 +152,19 @@ type ReaderImpl struct {
 
 	MoreLinesAdded chan bool
 
+	// Signalled when a file we are tailing has been truncated or replaced, as
+	// with log rotation. All lines read before that are dropped, and the file
+	// is read again from the start.
+	Reloaded chan bool
+
+	tailInterval time.Duration
+
+	// Signalled by Reload(), makes tailFile() reload right away
+	reloadRequested chan bool
+
+	// Set when tailFile() gives up, after that there's nobody around to reload
+	tailingStopped atomic.Bool
+
 	// Because we don't want to consume infinitely.
 	//
 	// Ref: https://github.com/walles/moor/issues/296
@@ -129,8 +174,23 @@ type ReaderImpl struct {
 	// PauseStatus is true if the reader is paused, false if it is not
 	PauseStatus *atomic.Bool
 
+	// See ReaderOptions.MaxLines and ReaderOptions.KeepLastLines
+	maxLines      int
+	keepLastLines bool
+
+	// Set by SetDropOldLines(), for not moving lines under the user's feet
+	// with KeepLastLines
+	keepOldLines bool
+
 	// For benchmarking cold cache searches
 	disableCache bool
+
+	// Aborts downloading, set for readers created by NewFromUrl()
+	cancel func()
+
+	// Knows where each file starts, set for readers created by
+	// NewFromFilenames()
+	concatenated *concatStream
 }
 
 // InputLines contains a number of lines from the reader, plus metadata
@@ -171,6 +231,10 @@ func (reader *ReaderImpl) preAllocLines() {
 		return
 	}
 
+	if reader.maxLines > 0 {
+		lineCount = min(lineCount, uint64(reader.maxLines))
+	}
+
 	// We had no lines since before, this is the expected happy path.
 	reader.lines = make([]*line, 0, lineCount)
 }
@@ -186,9 +250,15 @@ func (reader *ReaderImpl) readStream(stream io.Reader, formatter chroma.Formatte
 	default:
 	}
 
+	reader.RLock()
+	limitReached := reader.isLineLimitReached()
+	reader.RUnlock()
+
 	t0 := time.Now()
-	style := <-reader.highlightingStyle
-	options.Style = &style
+	if !options.NoHighlighting {
+		style := <-reader.highlightingStyle
+		options.Style = &style
+	}
 	highlightFromMemory(reader, formatter, options)
 	log.Debug("highlightFromMemory() took ", time.Since(t0))
 
@@ -198,6 +268,13 @@ func (reader *ReaderImpl) readStream(stream io.Reader, formatter chroma.Formatte
 	default:
 	}
 
+	if limitReached {
+		// Tailing would only find lines after the ones we want
+		log.Info("Read --lines ", reader.maxLines, " lines, not tailing")
+		reader.tailingStopped.Store(true)
+		return
+	}
+
 	// Tail the file if the stream is coming from a file.
 	// Ref: https://github.com/walles/moor/issues/224
 	err := reader.tailFile()
@@ -225,6 +302,35 @@ func (reader *ReaderImpl) maybePause() {
 	}
 }
 
+// True if we have read all the lines we want, see ReaderOptions.MaxLines. The
+// reader must be RLock()ed before calling this.
+func (reader *ReaderImpl) isLineLimitReached() bool {
+	if reader.maxLines <= 0 || reader.keepLastLines {
+		return false
+	}
+
+	// An unterminated last line may still get more text added to it
+	return len(reader.lines) >= reader.maxLines && reader.endsWithNewline
+}
+
+// With ReaderOptions.KeepLastLines, drop the oldest lines until we have at most
+// MaxLines of them. The reader must be Lock()ed before calling this.
+func (reader *ReaderImpl) dropOldLines() {
+	if reader.maxLines <= 0 || !reader.keepLastLines || reader.keepOldLines {
+		return
+	}
+
+	dropCount := len(reader.lines) - reader.maxLines
+	if dropCount <= 0 {
+		return
+	}
+
+	reader.lines = reader.lines[dropCount:]
+	if len(reader.lineOffsets) >= dropCount {
+		reader.lineOffsets = reader.lineOffsets[dropCount:]
+	}
+}
+
 // This function will update the Reader struct. It is expected to run in a
 // goroutine.
 //
@@ -236,11 +342,30 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 	inspectionReader := inspectionReader{base: stream}
 	bufioReader := bufio.NewReader(&inspectionReader)
 	completeLine := make([]byte, 0)
+	var readErr error
+
+	// When tailing, we continue where we left off last time
+	reader.RLock()
+	baseOffset := reader.bytesCount
+	reader.RUnlock()
+	streamOffset := func() int64 {
+		return baseOffset + inspectionReader.bytesCount - int64(bufioReader.Buffered())
+	}
 
 	t0 := time.Now()
+	limitReached := false
 	for {
 		reader.maybePause()
 
+		reader.RLock()
+		limitReached = reader.isLineLimitReached()
+		reader.RUnlock()
+		if limitReached {
+			break
+		}
+
+		lineOffset := streamOffset()
+
 		keepReadingLine := true
 		eof := false
 
@@ -268,18 +393,31 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 				break
 			}
 
+			readErr = fmt.Errorf("error reading line from input stream: %w", err)
 			reader.Lock()
 			if reader.Err == nil {
 				// Store the error unless it overwrites one we already have
-				reader.Err = fmt.Errorf("error reading line from input stream: %w", err)
+				reader.Err = readErr
 			}
 			reader.Unlock()
+			break
 		}
 
 		if eof {
 			break
 		}
 
+		if readErr != nil {
+			// Show the problem to the user rather than just silently stopping.
+			// This can happen with truncated compressed input for example.
+			errorLine := line{raw: "ERROR: " + readErr.Error()}
+			reader.Lock()
+			reader.lines = append(reader.lines, &errorLine)
+			reader.lineOffsets = append(reader.lineOffsets, lineOffset)
+			reader.Unlock()
+			break
+		}
+
 		if reader.Err != nil {
 			break
 		}
@@ -295,8 +433,11 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 			reader.lines[len(reader.lines)-1] = &newLine
 		} else {
 			reader.lines = append(reader.lines, &newLine)
+			reader.lineOffsets = append(reader.lineOffsets, lineOffset)
 		}
 		reader.endsWithNewline = true
+		reader.lineOffsetsEnd = streamOffset()
+		reader.dropOldLines()
 
 		reader.Unlock()
 
@@ -326,12 +467,18 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 	default:
 	}
 
-	reader.endsWithNewline = inspectionReader.endedWithNewline
+	if !limitReached {
+		// When stopping early, the inspection reader may have read past the
+		// last line we kept
+		reader.endsWithNewline = inspectionReader.endedWithNewline
+	}
 
 	log.Info("Stream read in ", time.Since(t0), ", have ", reader.GetLineCount(), " lines")
 }
 
 func (reader *ReaderImpl) tailFile() error {
+	defer reader.tailingStopped.Store(true)
+
 	reader.RLock()
 	fileName := reader.FileName
 	reader.RUnlock()
@@ -341,17 +488,30 @@ func (reader *ReaderImpl) tailFile() error {
 
 	log.Debugf("Tailing file %s", *fileName)
 
+	previousFileStats, err := os.Stat(*fileName)
+	if err != nil {
+		log.Debugf("Failed to stat file %s before tailing, giving up: %s", *fileName, err.Error())
+		return nil
+	}
+
 	for {
 		// NOTE: We could use something like
 		// https://github.com/fsnotify/fsnotify instead of sleeping and polling
 		// here.
-		time.Sleep(1 * time.Second)
+		forceReload := false
+		select {
+		case <-time.After(reader.tailInterval):
+		case <-reader.reloadRequested:
+			forceReload = true
+		}
 
 		fileStats, err := os.Stat(*fileName)
 		if err != nil {
 			log.Debugf("Failed to stat file %s while tailing, giving up: %s", *fileName, err.Error())
 			return nil
 		}
+		replaced := !os.SameFile(previousFileStats, fileStats)
+		previousFileStats = fileStats
 
 		reader.RLock()
 		bytesCount := reader.bytesCount
@@ -362,17 +522,22 @@ func (reader *ReaderImpl) tailFile() error {
 			return nil
 		}
 
+		if forceReload {
+			log.Debugf("Reloading file %s on request", *fileName)
+			reader.reload()
+			bytesCount = 0
+		} else if replaced || fileStats.Size() < bytesCount {
+			log.Debugf("File %s replaced or shrunk from %d to %d bytes, reloading",
+				*fileName, bytesCount, fileStats.Size())
+			reader.reload()
+			bytesCount = 0
+		}
+
 		if fileStats.Size() == bytesCount {
 			log.Tracef("File %s unchanged at %d bytes, continue tailing", *fileName, fileStats.Size())
 			continue
 		}
 
-		if fileStats.Size() < bytesCount {
-			log.Debugf("File %s shrunk from %d to %d bytes, stop tailing",
-				*fileName, bytesCount, fileStats.Size())
-			return nil
-		}
-
 		// File grew, read the new lines
 		stream, _, err := ZOpen(*fileName)
 		if err != nil {
@@ -407,6 +572,42 @@ func (reader *ReaderImpl) tailFile() error {
 	}
 }
 
+// Reload re-reads the file from the start, to pick up any changes to it. The
+// Reloaded channel is signalled when this happens.
+//
+// Returns false if there is no file to reload from, like for piped input, or
+// if we have stopped watching the file, like for compressed files.
+func (reader *ReaderImpl) Reload() bool {
+	if reader.FileName == nil || reader.tailingStopped.Load() {
+		return false
+	}
+
+	// Non-blocking write, one pending request is enough
+	select {
+	case reader.reloadRequested <- true:
+	default:
+	}
+
+	return true
+}
+
+// Drop all lines we have read so far, so that the file can be read again from
+// the start. Signals the Reloaded channel.
+func (reader *ReaderImpl) reload() {
+	reader.Lock()
+	reader.lines = []*line{}
+	reader.lineOffsets = nil
+	reader.lineOffsetsEnd = 0
+	reader.bytesCount = 0
+	reader.endsWithNewline = false
+	reader.Unlock()
+
+	select {
+	case reader.Reloaded <- true:
+	default:
+	}
+}
+
 // NewFromStream creates a new stream reader
 //
 // The display name can be an empty string ("").
@@ -461,6 +662,10 @@ func newReaderFromStream(reader io.Reader, originalFileName *string, formatter c
 	if options.PauseAfterLines != nil {
 		pauseAfterLines = *options.PauseAfterLines
 	}
+	tailInterval := 1 * time.Second
+	if options.TailInterval != nil {
+		tailInterval = *options.TailInterval
+	}
 	var displayFileName *string
 	if originalFileName != nil {
 		basename := filepath.Base(*originalFileName)
@@ -475,7 +680,13 @@ func newReaderFromStream(reader io.Reader, originalFileName *string, formatter c
 
 		PauseStatus: &pauseStatus,
 
+		maxLines:      options.MaxLines,
+		keepLastLines: options.KeepLastLines,
+
 		MoreLinesAdded:          make(chan bool, 1),
+		Reloaded:                make(chan bool, 1),
+		tailInterval:            tailInterval,
+		reloadRequested:         make(chan bool, 1),
 		MaybeDone:               make(chan bool, 2),
 		highlightingStyle:       make(chan chroma.Style, 1),
 		doneWaitingForFirstByte: make(chan bool, 1),
@@ -632,7 +843,7 @@ func NewFromFilename(filename string, formatter chroma.Formatter, options Reader
 		return nil, err
 	}
 
-	if options.Lexer == nil {
+	if options.Lexer == nil && !options.NoHighlighting {
 		options.Lexer = lexers.Match(highlightingFilename)
 	}
 
@@ -649,6 +860,34 @@ func NewFromFilename(filename string, formatter chroma.Formatter, options Reader
 	return returnMe, nil
 }
 
+// Find the line containing the given byte offset into the input.
+//
+// If the offset is past the end of the input, the last line is returned and
+// pastEnd is true. Returns nil if there are no lines, or if we don't know the
+// byte offsets of the lines.
+func (reader *ReaderImpl) GetLineIndexForByteOffset(offset int64) (index *linemetadata.Index, pastEnd bool) {
+	reader.RLock()
+	defer reader.RUnlock()
+
+	if len(reader.lineOffsets) == 0 || len(reader.lineOffsets) != len(reader.lines) {
+		return nil, false
+	}
+
+	if offset >= reader.lineOffsetsEnd {
+		return linemetadata.IndexFromLength(len(reader.lineOffsets)), true
+	}
+
+	position, found := slices.BinarySearch(reader.lineOffsets, offset)
+	if !found {
+		// Position is where a line starting at offset would have been
+		// inserted, so the offset is inside the line before that
+		position = max(0, position-1)
+	}
+
+	lineIndex := linemetadata.IndexFromZeroBased(position)
+	return &lineIndex, false
+}
+
 // Wait for reader to finish reading and highlighting. Used by tests.
 func (reader *ReaderImpl) Wait() error {
 	// Wait for our goroutine to finish
@@ -667,7 +906,9 @@ func (reader *ReaderImpl) Wait() error {
 	return reader.Err
 }
 
-func textAsString(reader *ReaderImpl, shouldFormat bool) string {
+// Returns the text of all lines, and whether it was reformatted. If it wasn't,
+// the returned lines are the input lines.
+func textAsString(reader *ReaderImpl, shouldFormat bool) (string, bool) {
 	reader.RLock()
 
 	text := strings.Builder{}
@@ -678,27 +919,27 @@ func textAsString(reader *ReaderImpl, shouldFormat bool) string {
 	result := text.String()
 	reader.RUnlock()
 
-	var jsonData any
-	err := json.Unmarshal([]byte(result), &jsonData)
-	if err != nil {
+	if !json.Valid([]byte(result)) {
 		// Not JSON, return the text as-is
-		return result
+		return result, false
 	}
 
 	if !shouldFormat {
 		log.Info("Try the --reformat flag for automatic JSON reformatting")
-		return result
+		return result, false
 	}
 
-	// Pretty print the JSON
-	prettyJSON, err := json.MarshalIndent(jsonData, "", "  ")
+	// Pretty print the JSON. Indenting rather than decoding and encoding
+	// keeps the key order, and numbers stay exactly as they were.
+	prettyJSON := bytes.Buffer{}
+	err := json.Indent(&prettyJSON, []byte(strings.TrimSpace(result)), "", "  ")
 	if err != nil {
 		log.Debug("Failed to pretty print JSON: ", err)
-		return result
+		return result, false
 	}
 
 	log.Debug("Got the --reformat flag, reformatted JSON input")
-	return string(prettyJSON)
+	return prettyJSON.String(), true
 }
 
 func isXml(text string) bool {
@@ -722,13 +963,22 @@ func highlightFromMemory(reader *ReaderImpl, formatter chroma.Formatter, options
 	}
 	reader.RUnlock()
 
-	text := textAsString(reader, options.ShouldFormat)
+	text, reformatted := textAsString(reader, options.ShouldFormat)
 
 	if len(text) == 0 {
 		log.Debug("Buffer is empty, not highlighting")
 		return
 	}
 
+	if options.NoHighlighting {
+		log.Debug("Highlighting disabled")
+		if reformatted {
+			// Reformatted JSON, show it without highlighting
+			reader.setText(text, false)
+		}
+		return
+	}
+
 	if options.Lexer == nil && json.Valid([]byte(text)) {
 		log.Info("Buffer is valid JSON, highlighting as JSON")
 		options.Lexer = lexers.Get("json")
@@ -763,7 +1013,9 @@ func highlightFromMemory(reader *ReaderImpl, formatter chroma.Formatter, options
 		return
 	}
 
-	reader.setText(*highlighted)
+	// Highlighting keeps the lines, so unless we also reformatted them they
+	// still map to the input
+	reader.setText(*highlighted, !reformatted)
 }
 
 // createStatusUnlocked() assumes that its caller is holding the read lock
@@ -792,6 +1044,10 @@ func (reader *ReaderImpl) createStatusUnlocked(lastLine linemetadata.Index) stri
 		percent = fmt.Sprintf("%.0f%%", math.Floor(100*float64(lastLine.Index()+1)/float64(len(reader.lines))))
 	}
 
+	if reader.IsLoading() {
+		linesCount = "loading… " + linesCount
+	}
+
 	if !reader.ShouldShowLineCount() {
 		linesCount = ""
 	}
@@ -849,12 +1105,18 @@ func (reader *ReaderImpl) ShouldShowLineCount() bool {
 	return false
 }
 
+// Paused readers aren't loading, they are waiting for the user to scroll
+// further down before they continue
+func (reader *ReaderImpl) IsLoading() bool {
+	return !reader.ReadingDone.Load() && !reader.PauseStatus.Load()
+}
+
 // The reader must be RLock()ed before entering this function. The index is for
 // error reporting.
 func (reader *ReaderImpl) plain(lines []*line, firstIndex linemetadata.Index, withCache bool) []string {
 	alreadyCached := true
 	for _, l := range lines {
-		if l.plainTextCache == nil {
+		if l.plainTextCache == nil || l.plainTextCacheTabSize != textstyles.TabSize {
 			alreadyCached = false
 			break
 		}
@@ -885,6 +1147,7 @@ func (reader *ReaderImpl) plain(lines []*line, firstIndex linemetadata.Index, wi
 	if withCache {
 		for loopIndex, l := range lines {
 			l.plainTextCache = &plainLines[loopIndex]
+			l.plainTextCacheTabSize = textstyles.TabSize
 		}
 	}
 	reader.Unlock()
@@ -1038,7 +1301,10 @@ func (reader *ReaderImpl) PumpToStdout() {
 
 // Replace reader contents with the given text. Consider setting
 // HighlightingDone and signalling the MaybeDone channel afterwards.
-func (reader *ReaderImpl) setText(text string) {
+//
+// Pass keepLineOffsets=false if the new lines don't map to the input bytes any
+// more, then byte offsets will be dropped.
+func (reader *ReaderImpl) setText(text string, keepLineOffsets bool) {
 	lines := []*line{}
 	for _, lineString := range strings.Split(text, "\n") {
 		line := line{raw: lineString}
@@ -1053,6 +1319,9 @@ func (reader *ReaderImpl) setText(text string) {
 
 	reader.Lock()
 	reader.lines = lines
+	if !keepLineOffsets {
+		reader.lineOffsets = nil
+	}
 	reader.Unlock()
 
 	log.Trace("Reader done, contents explicitly set")
@@ -1072,6 +1341,16 @@ func (reader *ReaderImpl) setPauseStatus(paused bool) {
 	log.Debugf("Reader pause status changed to %t", paused)
 }
 
+// With ReaderOptions.KeepLastLines, dropping old lines moves the remaining
+// lines up. Disable dropping while the user isn't following the end of the
+// input, so that what they are looking at stays put. Dropping is enabled by
+// default.
+func (reader *ReaderImpl) SetDropOldLines(enabled bool) {
+	reader.Lock()
+	reader.keepOldLines = !enabled
+	reader.Unlock()
+}
+
 func (reader *ReaderImpl) SetPauseAfterLines(lines int) {
 	if lines < 0 {
 		log.Warnf("Tried to set pause-after-lines to %d, ignoring", lines)
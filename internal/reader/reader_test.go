@@ -1,9 +1,13 @@
 package reader
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -17,6 +21,8 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/textstyles"
+	"github.com/walles/moor/v2/twin"
 )
 
 const samplesDir = "../../sample-files"
@@ -255,6 +261,29 @@ func TestStatusText(t *testing.T) {
 	assert.Equal(t, line.StatusText, "empty: <empty>")
 }
 
+// Until we have read everything, the line count is just what we have so far
+func TestStatusTextWhileLoading(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	// NewFromStream() wants some bytes to look at before returning
+	go func() {
+		_, _ = pipeWriter.Write([]byte("a\nb\n"))
+	}()
+
+	reader, err := NewFromStream("", pipeReader, nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, err)
+	awaitLines(t, reader, "a", "b")
+
+	assert.Assert(t, reader.IsLoading())
+	assert.Equal(t, "loading… 2 lines  100%", reader.GetLines(linemetadata.Index{}, 2).StatusText)
+
+	assert.NilError(t, pipeWriter.Close())
+	assert.NilError(t, reader.Wait())
+
+	assert.Assert(t, !reader.IsLoading())
+	assert.Equal(t, "2 lines  100%", reader.GetLines(linemetadata.Index{}, 2).StatusText)
+}
+
 func testCompressedFile(t *testing.T, filename string) {
 	filenameWithPath := path.Join(samplesDir, filename)
 	reader, e := NewFromFilename(filenameWithPath, formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
@@ -276,6 +305,44 @@ func TestCompressedFiles(t *testing.T) {
 	testCompressedFile(t, "compressed.txt.zstd")
 }
 
+func gzipBytes(t *testing.T, text string) []byte {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(text))
+	assert.NilError(t, err)
+	assert.NilError(t, writer.Close())
+
+	return compressed.Bytes()
+}
+
+func TestCompressedStream(t *testing.T) {
+	compressed := gzipBytes(t, "first line\nsecond line\n")
+
+	reader, err := NewFromStream("", bytes.NewReader(compressed), nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	lines := reader.GetLines(linemetadata.Index{}, 5)
+	assert.Equal(t, len(lines.Lines), 2)
+	assert.Equal(t, lines.Lines[0].Plain(), "first line")
+	assert.Equal(t, lines.Lines[1].Plain(), "second line")
+}
+
+// Broken compressed input should show up as an error line, not as a crash
+func TestTruncatedCompressedStream(t *testing.T) {
+	compressed := gzipBytes(t, "first line\nsecond line\n")
+	truncated := compressed[:len(compressed)-10]
+
+	reader, err := NewFromStream("", bytes.NewReader(truncated), nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, reader.Wait(), "unexpected EOF")
+
+	lines := reader.GetLines(linemetadata.Index{}, 5)
+	lastLine := lines.Lines[len(lines.Lines)-1].Plain()
+	assert.Assert(t, strings.HasPrefix(lastLine, "ERROR: "), lastLine)
+	assert.Assert(t, strings.Contains(lastLine, "unexpected EOF"), lastLine)
+}
+
 func TestReadFileDoneNoHighlighting(t *testing.T) {
 	testMe, err := NewFromFilename(samplesDir+"/empty",
 		formatters.TTY, ReaderOptions{Style: styles.Get("native")})
@@ -337,6 +404,50 @@ func TestFormatJson(t *testing.T) {
 	assert.Equal(t, len(lines.Lines), 3)
 }
 
+// Reformatting shouldn't sort keys or change any numbers
+func TestFormatJsonKeepsContents(t *testing.T) {
+	jsonStream := strings.NewReader(`{"zebra":12345678901234567890,"apple":[1.50,"<b>"]}`)
+	testMe, err := NewFromStream(
+		"JSON test",
+		jsonStream,
+		formatters.TTY,
+		ReaderOptions{
+			Style:        styles.Get("native"),
+			ShouldFormat: true,
+		})
+	assert.NilError(t, err)
+
+	assert.NilError(t, testMe.Wait())
+
+	awaitLines(t, testMe,
+		"{",
+		`  "zebra": 12345678901234567890,`,
+		`  "apple": [`,
+		`    1.50,`,
+		`    "<b>"`,
+		`  ]`,
+		"}",
+	)
+}
+
+// Broken JSON should be shown as-is
+func TestFormatJsonInvalid(t *testing.T) {
+	jsonStream := strings.NewReader(`{"key":"value",}`)
+	testMe, err := NewFromStream(
+		"JSON test",
+		jsonStream,
+		formatters.TTY,
+		ReaderOptions{
+			Style:        styles.Get("native"),
+			ShouldFormat: true,
+		})
+	assert.NilError(t, err)
+
+	assert.NilError(t, testMe.Wait())
+
+	awaitLines(t, testMe, `{"key":"value",}`)
+}
+
 func TestFormatJsonArray(t *testing.T) {
 	// Note the space after "key" to verify formatting actually happens
 	jsonStream := strings.NewReader(`[{"key" :"value"}]`)
@@ -588,6 +699,21 @@ func TestCachePlainText(t *testing.T) {
 	assert.Assert(t, reader.lines[1].plainTextCache != nil)
 }
 
+// Tabs are expanded in the plain text, changing the tab size must not give us
+// stale cached plain text
+func TestCachePlainTextTabSize(t *testing.T) {
+	defer func() { textstyles.TabSize = 8 }()
+
+	reader := NewFromTextForTesting("TestCachePlainTextTabSize", "a\tb")
+	assert.NilError(t, reader.Wait())
+
+	textstyles.TabSize = 8
+	assert.Equal(t, "a       b", reader.GetLine(linemetadata.Index{}).Plain())
+
+	textstyles.TabSize = 4
+	assert.Equal(t, "a   b", reader.GetLine(linemetadata.Index{}).Plain())
+}
+
 // How long does it take to read a file?
 //
 // This can be slow due to highlighting.
@@ -680,3 +806,410 @@ func BenchmarkCountLines(b *testing.B) {
 		assert.NilError(b, err)
 	}
 }
+
+func testByteOffset(t *testing.T, reader *ReaderImpl, offset int64, expectedIndex int, expectedPastEnd bool) {
+	t.Helper()
+
+	index, pastEnd := reader.GetLineIndexForByteOffset(offset)
+	assert.Assert(t, index != nil, "Offset %d", offset)
+	assert.Equal(t, expectedIndex, index.Index(), "Offset %d", offset)
+	assert.Equal(t, expectedPastEnd, pastEnd, "Offset %d", offset)
+}
+
+func TestGetLineIndexForByteOffset(t *testing.T) {
+	// Lines are 4, 3, 1 and 6 bytes long, including the newlines
+	reader, err := NewFromStream("", strings.NewReader("abc\nde\n\nfghij\n"), formatters.TTY, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	testByteOffset(t, reader, 0, 0, false)
+	testByteOffset(t, reader, 3, 0, false) // The first newline
+	testByteOffset(t, reader, 4, 1, false)
+	testByteOffset(t, reader, 6, 1, false)
+	testByteOffset(t, reader, 7, 2, false)
+	testByteOffset(t, reader, 8, 3, false)
+	testByteOffset(t, reader, 13, 3, false)
+
+	// Past the end should clamp to the last line
+	testByteOffset(t, reader, 14, 3, true)
+	testByteOffset(t, reader, 1000, 3, true)
+}
+
+func TestGetLineIndexForByteOffsetCrLf(t *testing.T) {
+	// The line terminators are two bytes each
+	reader, err := NewFromStream("", strings.NewReader("a\r\nb\r\nc"), formatters.TTY, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	testByteOffset(t, reader, 2, 0, false)
+	testByteOffset(t, reader, 3, 1, false)
+	testByteOffset(t, reader, 6, 2, false)
+	testByteOffset(t, reader, 7, 2, true)
+}
+
+// After reformatting, lines don't map to the input any more
+func TestGetLineIndexForByteOffsetReformatted(t *testing.T) {
+	reader, err := NewFromStream(
+		"",
+		strings.NewReader(`{"key" :"value"}`),
+		formatters.TTY,
+		ReaderOptions{
+			Style:        styles.Get("native"),
+			ShouldFormat: true,
+		})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+	assert.Equal(t, 3, reader.GetLineCount())
+
+	index, _ := reader.GetLineIndexForByteOffset(0)
+	assert.Assert(t, index == nil)
+}
+
+// Style of the first cell of word on the line
+func styleOfWord(t *testing.T, line textstyles.StyledRunesWithTrailer, word string) twin.Style {
+	plain := ""
+	for _, cell := range line.StyledRunes {
+		plain += string(cell.Rune)
+	}
+
+	runeIndex := strings.Index(plain, word)
+	assert.Assert(t, runeIndex >= 0, "%q not found in %q", word, plain)
+	return line.StyledRunes[len([]rune(plain[:runeIndex]))].Style
+}
+
+func TestHighlightGo(t *testing.T) {
+	testMe, err := NewFromStream("",
+		strings.NewReader("package main\n\nfunc main() {}\n"),
+		formatters.TTY16m, ReaderOptions{Lexer: lexers.Get("go"), Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	line := testMe.GetLine(linemetadata.IndexFromZeroBased(2))
+	tokens := line.HighlightedTokens(twin.StyleDefault, twin.StyleDefault, nil)
+
+	keyword := styleOfWord(t, tokens, "func")
+	identifier := styleOfWord(t, tokens, "main")
+	assert.Assert(t, keyword != twin.StyleDefault)
+	assert.Assert(t, keyword != identifier)
+}
+
+func TestHighlightGoByFilename(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "main.go")
+	assert.NilError(t, os.WriteFile(fileName, []byte("package main\n\nfunc main() {}\n"), 0o600))
+
+	testMe, err := NewFromFilename(fileName, formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	line := testMe.GetLine(linemetadata.IndexFromZeroBased(2))
+	tokens := line.HighlightedTokens(twin.StyleDefault, twin.StyleDefault, nil)
+	assert.Assert(t, styleOfWord(t, tokens, "func") != styleOfWord(t, tokens, "main"))
+}
+
+func TestHighlightGoWithSearch(t *testing.T) {
+	testMe, err := NewFromStream("",
+		strings.NewReader("func main() {}\n"),
+		formatters.TTY16m, ReaderOptions{Lexer: lexers.Get("go"), Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	line := testMe.GetLine(linemetadata.IndexFromZeroBased(0))
+	unsearched := line.HighlightedTokens(twin.StyleDefault, twin.StyleDefault, nil)
+
+	searchHitStyle := twin.StyleDefault.WithAttr(twin.AttrReverse)
+	searched := line.HighlightedTokens(twin.StyleDefault, searchHitStyle, regexp.MustCompile("main"))
+
+	// Search hits are highlighted, the rest keeps its syntax highlighting
+	assert.Equal(t, searchHitStyle, styleOfWord(t, searched, "main"))
+	assert.Equal(t, styleOfWord(t, unsearched, "func"), styleOfWord(t, searched, "func"))
+}
+
+func TestNoHighlighting(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "main.go")
+	assert.NilError(t, os.WriteFile(fileName, []byte("package main\n\nfunc main() {}\n"), 0o600))
+
+	// No style provided, we shouldn't need one when not highlighting
+	testMe, err := NewFromFilename(fileName, formatters.TTY16m, ReaderOptions{NoHighlighting: true})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	line := testMe.GetLine(linemetadata.IndexFromZeroBased(2))
+	tokens := line.HighlightedTokens(twin.StyleDefault, twin.StyleDefault, nil)
+	for _, cell := range tokens.StyledRunes {
+		assert.Equal(t, twin.StyleDefault, cell.Style)
+	}
+}
+
+func TestNoHighlightingStillFormats(t *testing.T) {
+	testMe, err := NewFromStream("",
+		strings.NewReader(`{"key": "value"}`),
+		formatters.TTY16m, ReaderOptions{NoHighlighting: true, ShouldFormat: true})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	lines := testMe.GetLines(linemetadata.Index{}, 10)
+	assert.Equal(t, 3, len(lines.Lines))
+	assert.Equal(t, `  "key": "value"`, lines.Lines[1].Plain())
+	assert.Equal(t, twin.StyleDefault, lines.Lines[1].HighlightedTokens(twin.StyleDefault, twin.StyleDefault, nil).StyledRunes[2].Style)
+}
+
+// Wait for the reader to have exactly these lines
+func awaitLines(t *testing.T, reader *ReaderImpl, expected ...string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		actual := []string{}
+		for _, line := range reader.GetLines(linemetadata.Index{}, 1000).Lines {
+			actual = append(actual, line.Plain())
+		}
+		if strings.Join(actual, "\n") == strings.Join(expected, "\n") {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			assert.DeepEqual(t, expected, actual)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func awaitReloaded(t *testing.T, reader *ReaderImpl) {
+	select {
+	case <-reader.Reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reader to reload")
+	}
+}
+
+func TestTailGrowing(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "tailme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("a\nb\n"), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+	awaitLines(t, reader, "a", "b")
+
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NilError(t, err)
+	_, err = file.WriteString("c\n")
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+
+	awaitLines(t, reader, "a", "b", "c")
+	assert.Equal(t, 0, len(reader.Reloaded))
+}
+
+func TestTailTruncated(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "tailme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("first\nsecond\nthird\n"), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	assert.NilError(t, os.WriteFile(fileName, []byte("new\n"), 0o600))
+	awaitReloaded(t, reader)
+	awaitLines(t, reader, "new")
+
+	// Byte offsets should be for the new contents
+	index, pastEnd := reader.GetLineIndexForByteOffset(0)
+	assert.Equal(t, 0, index.Index())
+	assert.Assert(t, !pastEnd)
+
+	// Tailing should go on after reloading
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NilError(t, err)
+	_, err = file.WriteString("more\n")
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+
+	awaitLines(t, reader, "new", "more")
+}
+
+func TestTailReplaced(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "tailme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("old\n"), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	// Rotate the file, with new contents larger than the old ones so that
+	// looking at the size alone won't help
+	newFileName := fileName + ".new"
+	assert.NilError(t, os.WriteFile(newFileName, []byte("brand new 1\nbrand new 2\n"), 0o600))
+	assert.NilError(t, os.Rename(newFileName, fileName))
+
+	awaitReloaded(t, reader)
+	awaitLines(t, reader, "brand new 1", "brand new 2")
+}
+
+// Rewriting a file in place with the same size isn't detected by tailing, so
+// the user has to ask for a reload
+func TestReload(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "reloadme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("old\n"), 0o600))
+
+	// Make sure the reload comes from us, not from tailing
+	tailInterval := time.Hour
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:        styles.Get("native"),
+		TailInterval: &tailInterval,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+	awaitLines(t, reader, "old")
+
+	assert.NilError(t, os.WriteFile(fileName, []byte("new\n"), 0o600))
+	assert.Assert(t, reader.Reload())
+	awaitReloaded(t, reader)
+	awaitLines(t, reader, "new")
+}
+
+func TestReloadStream(t *testing.T) {
+	reader := NewFromTextForTesting("", "text")
+	assert.Assert(t, !reader.Reload())
+}
+
+func TestMaxLines(t *testing.T) {
+	reader, err := NewFromStream("", strings.NewReader("1\n2\n3\n4\n5\n"), nil, ReaderOptions{
+		Style:    &chroma.Style{},
+		MaxLines: 3,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "1", "2", "3")
+	assert.Equal(t, "3 lines  100%", reader.GetLines(linemetadata.Index{}, 3).StatusText)
+}
+
+// Reading should stop at the limit, even if the input never ends
+func TestMaxLinesEndlessStream(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer func() {
+		assert.NilError(t, pipeWriter.Close())
+	}()
+
+	go func() {
+		for i := 1; ; i++ {
+			_, err := pipeWriter.Write([]byte(strconv.Itoa(i) + "\n"))
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	reader, err := NewFromStream("", pipeReader, nil, ReaderOptions{
+		Style:    &chroma.Style{},
+		MaxLines: 3,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "1", "2", "3")
+}
+
+// No tailing after reaching the limit, new lines would come after the ones we
+// want
+func TestMaxLinesFile(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "limitme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("a\nb\nc\n"), 0o600))
+
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:    styles.Get("native"),
+		MaxLines: 2,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "a", "b")
+	assert.Assert(t, !reader.Reload())
+}
+
+func TestKeepLastLines(t *testing.T) {
+	reader, err := NewFromStream("", strings.NewReader("1\n2\n3\n4\n5\n"), nil, ReaderOptions{
+		Style:         &chroma.Style{},
+		MaxLines:      3,
+		KeepLastLines: true,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+
+	awaitLines(t, reader, "3", "4", "5")
+
+	// Byte offsets should still point to the right lines
+	index, pastEnd := reader.GetLineIndexForByteOffset(int64(len("1\n2\n3\n")))
+	assert.Equal(t, 1, index.Index())
+	assert.Assert(t, !pastEnd)
+}
+
+// Lines must stay put while the user is looking at them
+func TestKeepLastLinesNotDropping(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "tailme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("a\nb\nc\n"), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:         styles.Get("native"),
+		TailInterval:  &tailInterval,
+		MaxLines:      2,
+		KeepLastLines: true,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+	awaitLines(t, reader, "b", "c")
+
+	reader.SetDropOldLines(false)
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NilError(t, err)
+	_, err = file.WriteString("d\n")
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+	awaitLines(t, reader, "b", "c", "d")
+
+	// Back to following, the next line drops the old ones
+	reader.SetDropOldLines(true)
+	file, err = os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NilError(t, err)
+	_, err = file.WriteString("e\n")
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+	awaitLines(t, reader, "d", "e")
+}
+
+func TestKeepLastLinesTailing(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "tailme.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("a\nb\nc\n"), 0o600))
+
+	tailInterval := 10 * time.Millisecond
+	reader, err := NewFromFilename(fileName, formatters.TTY, ReaderOptions{
+		Style:         styles.Get("native"),
+		TailInterval:  &tailInterval,
+		MaxLines:      2,
+		KeepLastLines: true,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+	awaitLines(t, reader, "b", "c")
+
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NilError(t, err)
+	_, err = file.WriteString("d\n")
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+
+	awaitLines(t, reader, "c", "d")
+}
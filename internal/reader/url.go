@@ -0,0 +1,156 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	log "github.com/sirupsen/logrus"
+)
+
+// How long to wait for a web server to start responding. Once the response
+// has started, we wait for as long as it takes, since the server could be
+// streaming a log that doesn't get new lines very often. A var rather than a
+// const so that tests can change it.
+var urlResponseTimeout = 30 * time.Second
+
+// True if name is an http:// or https:// URL rather than a file name
+func IsUrl(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// OpenUrl starts downloading from an http:// or https:// URL. Redirects are
+// followed, and non-2xx responses are reported as errors. Any compression is
+// removed from the returned stream.
+//
+// Cancelling the context aborts the download.
+func OpenUrl(ctx context.Context, urlString string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, urlString, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = urlResponseTimeout
+	client := &http.Client{Transport: transport}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("%s: %s", urlString, response.Status)
+	}
+
+	unzipped, err := ZReader(response.Body)
+	if err != nil {
+		_ = response.Body.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{unzipped, response.Body}, nil
+}
+
+// Downloads from a URL on the first Read(), so that the download happens in the
+// reader goroutine rather than when creating the reader.
+type urlStream struct {
+	ctx       context.Context
+	urlString string
+
+	body io.ReadCloser
+	err  error
+}
+
+func (s *urlStream) Read(p []byte) (int, error) {
+	if s.body == nil && s.err == nil {
+		s.body, s.err = OpenUrl(s.ctx, s.urlString)
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	n, err := s.body.Read(p)
+	if err == io.EOF {
+		closeErr := s.body.Close()
+		if closeErr != nil {
+			log.Debug("Closing download of ", s.urlString, " failed: ", closeErr)
+		}
+	}
+
+	return n, err
+}
+
+// What to call a URL in the status bar, and to base highlighting on. This is
+// the last part of the path, or the host name if the path is empty.
+func urlDisplayName(urlString string) string {
+	parsed, err := url.Parse(urlString)
+	if err != nil {
+		return urlString
+	}
+
+	base := path.Base(parsed.Path)
+	if base == "/" || base == "." {
+		return parsed.Host
+	}
+
+	return base
+}
+
+// NewFromUrl creates a new reader for an http:// or https:// URL.
+//
+// Lines are shown while the download is still in progress. Problems with the
+// download, like the server responding with 404, end up as an error line last
+// in the reader.
+//
+// Call Cancel() on the returned reader to abort the download.
+//
+// If options.Style is nil, you must call reader.SetStyleForHighlighting() later
+// to get highlighting.
+func NewFromUrl(urlString string, formatter chroma.Formatter, options ReaderOptions) *ReaderImpl {
+	displayName := urlDisplayName(urlString)
+	if options.Lexer == nil && !options.NoHighlighting {
+		options.Lexer = lexers.Match(displayName)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	returnMe := newReaderFromStream(&urlStream{ctx: ctx, urlString: urlString}, nil, formatter, options)
+
+	returnMe.Lock()
+	returnMe.DisplayName = &displayName
+	returnMe.cancel = cancel
+	returnMe.Unlock()
+
+	if options.Lexer == nil {
+		returnMe.HighlightingDone.Store(true)
+	}
+
+	if options.Style != nil {
+		returnMe.SetStyleForHighlighting(*options.Style)
+	}
+
+	return returnMe
+}
+
+// Cancel aborts any download in progress. Lines we already have are kept.
+func (reader *ReaderImpl) Cancel() {
+	reader.RLock()
+	cancel := reader.cancel
+	reader.RUnlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
@@ -0,0 +1,124 @@
+package reader
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"gotest.tools/v3/assert"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+func lastLinePlain(reader *ReaderImpl) string {
+	lines := reader.GetLines(linemetadata.Index{}, reader.GetLineCount()).Lines
+	return lines[len(lines)-1].Plain()
+}
+
+func TestIsUrl(t *testing.T) {
+	assert.Assert(t, IsUrl("http://example.com/log.txt"))
+	assert.Assert(t, IsUrl("HTTPS://example.com/"))
+	assert.Assert(t, !IsUrl("ftp://example.com/log.txt"))
+	assert.Assert(t, !IsUrl("http.txt"))
+	assert.Assert(t, !IsUrl("/tmp/http://x"))
+}
+
+func TestUrlDisplayName(t *testing.T) {
+	assert.Equal(t, "log.txt", urlDisplayName("https://example.com/logs/log.txt?x=1"))
+	assert.Equal(t, "example.com", urlDisplayName("https://example.com/"))
+	assert.Equal(t, "example.com", urlDisplayName("https://example.com"))
+}
+
+// Lines should show up before the download is done
+func TestUrlStreaming(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		w.(http.Flusher).Flush()
+
+		<-release
+		fmt.Fprintln(w, "line 4")
+	}))
+	defer server.Close()
+
+	reader := NewFromUrl(server.URL+"/log.txt", nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.Equal(t, "log.txt", *reader.DisplayName)
+	assert.Assert(t, reader.FileName == nil)
+
+	awaitLines(t, reader, "line 1", "line 2", "line 3")
+	assert.Assert(t, !reader.ReadingDone.Load())
+
+	close(release)
+	assert.NilError(t, reader.Wait())
+	assert.Equal(t, 4, reader.GetLineCount())
+	assert.Equal(t, "line 4", lastLinePlain(reader))
+}
+
+func TestUrlRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "moved\nhere\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reader := NewFromUrl(server.URL+"/old", nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, reader.Wait())
+	assert.Equal(t, 2, reader.GetLineCount())
+	assert.Equal(t, "here", lastLinePlain(reader))
+}
+
+func TestUrlHttpError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	reader := NewFromUrl(server.URL+"/missing.txt", nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.ErrorContains(t, reader.Wait(), "404 Not Found")
+
+	lastLine := lastLinePlain(reader)
+	assert.Assert(t, strings.HasPrefix(lastLine, "ERROR: "), lastLine)
+	assert.Assert(t, strings.Contains(lastLine, "/missing.txt: 404 Not Found"), lastLine)
+}
+
+func TestUrlResponseTimeout(t *testing.T) {
+	defer func(saved time.Duration) { urlResponseTimeout = saved }(urlResponseTimeout)
+	urlResponseTimeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	reader := NewFromUrl(server.URL, nil, ReaderOptions{Style: &chroma.Style{}})
+	assert.ErrorContains(t, reader.Wait(), "timeout")
+	assert.Assert(t, strings.HasPrefix(lastLinePlain(reader), "ERROR: "))
+}
+
+func TestUrlCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "first line")
+		w.(http.Flusher).Flush()
+
+		// Never finish on our own
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	reader := NewFromUrl(server.URL, nil, ReaderOptions{Style: &chroma.Style{}})
+	awaitLines(t, reader, "first line")
+
+	reader.Cancel()
+	assert.ErrorContains(t, reader.Wait(), "context canceled")
+	assert.Equal(t, "first line", reader.GetLine(linemetadata.Index{}).Plain())
+}
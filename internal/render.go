@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"regexp"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Settings for RenderRows() and NewScroller()
+type RenderOptions struct {
+	Width  int
+	Height int
+
+	WrapLongLines   bool
+	ShowLineNumbers bool
+
+	// If set, hits for this pattern are highlighted just like when searching
+	SearchPattern *regexp.Regexp
+}
+
+// Render the first screenful of a reader the way the pager would show it, but
+// without any terminal. One slice of cells is returned per screen row, with no
+// status bar.
+//
+// The reader should be done reading before this is called, otherwise you'll
+// get whatever lines it has read so far.
+func RenderRows(r *reader.ReaderImpl, options RenderOptions) [][]twin.StyledRune {
+	return NewScroller(r, options).Rows()
+}
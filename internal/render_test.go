@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func renderRowsToStrings(rows [][]twin.StyledRune) []string {
+	result := []string{}
+	for _, row := range rows {
+		result = append(result, rowToString(row))
+	}
+	return result
+}
+
+func TestRenderRows(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "first\nsecond\nthird")
+	assert.NilError(t, r.Wait())
+
+	rows := RenderRows(r, RenderOptions{Width: 10, Height: 2})
+	assert.DeepEqual(t, []string{"first", "second"}, renderRowsToStrings(rows))
+}
+
+func TestRenderRowsWithLineNumbers(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "first\nsecond\nthird")
+	assert.NilError(t, r.Wait())
+
+	rows := RenderRows(r, RenderOptions{Width: 10, Height: 5, ShowLineNumbers: true})
+	assert.DeepEqual(t, []string{"  1 first", "  2 second", "  3 third"}, renderRowsToStrings(rows))
+}
+
+func TestRenderRowsWrapped(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "hello world")
+	assert.NilError(t, r.Wait())
+
+	rows := RenderRows(r, RenderOptions{Width: 6, Height: 5})
+	assert.DeepEqual(t, []string{"hello>"}, renderRowsToStrings(rows))
+
+	rows = RenderRows(r, RenderOptions{Width: 6, Height: 5, WrapLongLines: true})
+	assert.DeepEqual(t, []string{"hello", "world"}, renderRowsToStrings(rows))
+}
+
+func TestRenderRowsSearchHighlighting(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "abc")
+	assert.NilError(t, r.Wait())
+
+	rows := RenderRows(r, RenderOptions{Width: 10, Height: 1, SearchPattern: regexp.MustCompile("b")})
+	assert.Equal(t, "abc", rowToString(rows[0]))
+
+	assert.Equal(t, twin.StyleDefault, rows[0][0].Style)
+	assert.Assert(t, rows[0][1].Style != twin.StyleDefault, "Search hit should be highlighted")
+	assert.Equal(t, twin.StyleDefault, rows[0][2].Style)
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/textstyles"
+)
+
+// Is there room for the ruler, and does the user want it? There is always at
+// least one screen row left for the contents.
+func (p *Pager) isShowingRuler() bool {
+	return p.ShowRuler && !p.isShowingHelp && p.rowsAboveStatusBar() > 1
+}
+
+// The ruler marker for a one based column number. Every tenth column gets the
+// tens digit, and every fifth column gets a '+'.
+func rulerRune(column int) rune {
+	if column%10 == 0 {
+		return rune('0' + (column/10)%10)
+	}
+	if column%5 == 0 {
+		return '+'
+	}
+	return '.'
+}
+
+// A row of column markers like "....+....1....+....2", lined up with the line
+// contents below it even when scrolled sideways.
+func (p *Pager) renderRuler(numberPrefixLength int) []renderedLine {
+	if !p.isShowingRuler() {
+		return nil
+	}
+
+	width := p.contentWidth()
+	cells := createLinePrefix(nil, numberPrefixLength)
+
+	// Like in decorateLine()
+	column := max(0, p.leftColumnZeroBased-numberPrefixLength)
+	for len(cells) < width {
+		column++
+		cells = append(cells, textstyles.CellWithMetadata{Rune: rulerRune(column), Style: lineNumbersStyle})
+	}
+
+	return []renderedLine{{cells: cells}}
+}
+
+func (p *Pager) toggleRuler() {
+	p.ShowRuler = !p.ShowRuler
+
+	if p.ShowRuler {
+		p.setMessage("Showing column ruler")
+	} else {
+		p.setMessage("Column ruler hidden")
+	}
+}
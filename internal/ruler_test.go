@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRuler(t *testing.T) {
+	pager := createLinesPager(t, 25, 4, "abcdefghijklmnopqrstuvwxyz0123456789", "second", "third")
+	pager.ShowRuler = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "....+....1....+....2....+", rows[0])
+	assert.Equal(t, "abcdefghijklmnopqrstuvwx>", rows[1])
+	assert.Equal(t, "second", rows[2])
+	assert.Equal(t, 2, pager.visibleHeight())
+}
+
+func TestRulerScrolledRight(t *testing.T) {
+	pager := createLinesPager(t, 25, 4, "abcdefghijklmnopqrstuvwxyz0123456789", "second", "third")
+	pager.ShowRuler = true
+
+	screenRows(pager)
+	pager.moveRight(7)
+
+	// The ruler should start at column 8, like the line below it
+	rows := screenRows(pager)
+	assert.Equal(t, ".1....+....2....+....3..", rows[0][1:])
+	assert.Equal(t, "ijklmnopqrstuvwxyz01234>", rows[1][1:])
+}
+
+func TestRulerWithLineNumbers(t *testing.T) {
+	pager := createLinesPager(t, 15, 4, "abcdefghijklmnop", "second", "third")
+	pager.ShowRuler = true
+	pager.showLineNumbers = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "    ....+....1.", rows[0])
+	assert.Equal(t, "  1 abcdefghij>", rows[1])
+}
+
+func TestRulerToggle(t *testing.T) {
+	pager := createLinesPager(t, 25, 4, "first", "second", "third")
+
+	typeRunes(pager, "r")
+	assert.Assert(t, pager.ShowRuler)
+	assert.Equal(t, "....+....1....+....2....+", screenRows(pager)[0])
+
+	typeRunes(pager, "r")
+	assert.Assert(t, !pager.ShowRuler)
+	assert.Equal(t, "first", screenRows(pager)[0])
+}
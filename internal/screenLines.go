@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/davecgh/go-spew/spew"
 	log "github.com/sirupsen/logrus"
@@ -32,6 +33,12 @@ type renderedLine struct {
 }
 
 type renderedScreen struct {
+	// Zero or one lines shown above everything else, see Pager.ShowRuler
+	ruler []renderedLine
+
+	// Shown above the scrollable lines, see Pager.HeaderLines
+	headerLines []renderedLine
+
 	lines             []renderedLine
 	inputLines        []reader.NumberedLine
 	numberPrefixWidth int // Including padding. 0 means no line numbers.
@@ -47,7 +54,8 @@ func (p *Pager) redraw(spinner string) {
 
 	lastUpdatedScreenLineNumber := -1
 	renderedScreen := p.renderLines()
-	for screenLineNumber, row := range renderedScreen.lines {
+	rows := slices.Concat(renderedScreen.ruler, renderedScreen.headerLines, renderedScreen.lines)
+	for screenLineNumber, row := range rows {
 		lastUpdatedScreenLineNumber = screenLineNumber
 		column := 0
 		for _, cell := range row.cells {
@@ -55,6 +63,10 @@ func (p *Pager) redraw(spinner string) {
 		}
 	}
 
+	if p.ShowScrollbar {
+		p.drawScrollbar(renderedScreen.lines, len(renderedScreen.ruler)+len(renderedScreen.headerLines))
+	}
+
 	// Status line code follows
 
 	eofSpinner := spinner
@@ -68,9 +80,17 @@ func (p *Pager) redraw(spinner string) {
 		column += p.screen.SetCell(column, lastUpdatedScreenLineNumber+1, cell.ToStyledRune())
 	}
 
-	p.mode.drawFooter(renderedScreen.statusText, spinner)
+	statusText := renderedScreen.statusText
+	if p.StatusBarFormat != "" {
+		statusText = p.formatStatusBar(p.StatusBarFormat, renderedScreen)
+	} else if position := p.statusPosition(renderedScreen); position != "" {
+		statusText += "  " + position
+	}
+	p.mode.drawFooter(statusText, spinner)
 
 	p.screen.Show()
+
+	p.updateStatusFile(renderedScreen)
 }
 
 // Render all lines that should go on the screen.
@@ -109,7 +129,7 @@ func (p *Pager) internalRenderLines(highlightSearchHitLines bool) renderedScreen
 	if p.lineIndex() != nil {
 		lineIndexToShow = *p.lineIndex()
 	}
-	inputLines := p.Reader().GetLines(lineIndexToShow, p.visibleHeight())
+	inputLines := p.getLinesToRender(lineIndexToShow)
 	if len(inputLines.Lines) == 0 {
 		// Empty input, empty output
 		return renderedScreen{statusText: inputLines.StatusText}
@@ -120,7 +140,7 @@ func (p *Pager) internalRenderLines(highlightSearchHitLines bool) renderedScreen
 
 	allLines := make([]renderedLine, 0)
 	for _, line := range inputLines.Lines {
-		rendering := p.renderLine(line, numberPrefixLength, highlightSearchHitLines)
+		rendering := p.renderLine(line, numberPrefixLength, highlightSearchHitLines, &lineIndexToShow)
 
 		var onScreenLength int
 		for i := range rendering {
@@ -180,10 +200,26 @@ func (p *Pager) internalRenderLines(highlightSearchHitLines bool) renderedScreen
 		allLines = allLines[0:wantedLineCount]
 	}
 
-	// Fill in the line trailers
-	screenWidth, _ := p.screen.Size()
-	for i := range allLines {
-		line := &allLines[i]
+	headerLines := p.renderHeaderLines(numberPrefixLength)
+
+	p.fillInTrailers(headerLines)
+	p.fillInTrailers(allLines)
+
+	return renderedScreen{
+		ruler:             p.renderRuler(numberPrefixLength),
+		headerLines:       headerLines,
+		lines:             allLines,
+		statusText:        inputLines.StatusText,
+		inputLines:        inputLines.Lines,
+		numberPrefixWidth: numberPrefixLength,
+	}
+}
+
+// Pad lines with trailers to the full screen width
+func (p *Pager) fillInTrailers(lines []renderedLine) {
+	screenWidth := p.contentWidth()
+	for i := range lines {
+		line := &lines[i]
 		if line.trailer == twin.StyleDefault {
 			continue
 		}
@@ -192,13 +228,115 @@ func (p *Pager) internalRenderLines(highlightSearchHitLines bool) renderedScreen
 			line.cells = append(line.cells, textstyles.CellWithMetadata{Rune: ' ', Style: line.trailer})
 		}
 	}
+}
 
-	return renderedScreen{
-		lines:             allLines,
-		statusText:        inputLines.StatusText,
-		inputLines:        inputLines.Lines,
-		numberPrefixWidth: numberPrefixLength,
+// Get enough input lines to fill the screen, starting at firstIndex.
+//
+// Normally that's one line per screen row, but with SqueezeBlankLines set some
+// lines won't be shown, so we may need more than that.
+func (p *Pager) getLinesToRender(firstIndex linemetadata.Index) reader.InputLines {
+	wantedRows := p.visibleHeight()
+	wantedLineCount := wantedRows
+	lineCount := p.Reader().GetLineCount()
+	for {
+		inputLines := p.Reader().GetLines(firstIndex, wantedLineCount)
+		if !p.SqueezeBlankLines || len(inputLines.Lines) < wantedLineCount {
+			return inputLines
+		}
+
+		lastLine := inputLines.Lines[len(inputLines.Lines)-1]
+		if lastLine.Index.Index()+1 >= lineCount {
+			// Can't get any more lines than this
+			return inputLines
+		}
+
+		visibleLineCount := 0
+		for _, line := range inputLines.Lines {
+			if line.Index.IsBefore(firstIndex) {
+				continue
+			}
+			if !p.isSqueezedAway(line) {
+				visibleLineCount++
+			}
+		}
+		if visibleLineCount >= wantedRows {
+			// Every visible line takes up at least one screen row
+			return inputLines
+		}
+
+		wantedLineCount *= 2
+	}
+}
+
+// With SqueezeBlankLines set, blank lines following other blank lines are not
+// shown.
+func (p *Pager) isSqueezedAway(line reader.NumberedLine) bool {
+	if !p.SqueezeBlankLines {
+		return false
+	}
+	if line.Line.Plain() != "" {
+		return false
+	}
+	if line.Index.IsZero() {
+		return false
+	}
+
+	previousLine := p.Reader().GetLine(line.Index.NonWrappingAdd(-1))
+	return previousLine != nil && previousLine.Line.Plain() == ""
+}
+
+// Highlight all cells of a (sub) line, except for search hits which should
+// stand out even on the current line.
+func highlightCurrentLine(line *textstyles.StyledRunesWithTrailer) {
+	for i := range line.StyledRunes {
+		cell := &line.StyledRunes[i]
+		if cell.IsSearchHit {
+			continue
+		}
+
+		if currentLineBackground != nil {
+			cell.Style = cell.Style.WithBackground(*currentLineBackground)
+		} else {
+			cell.Style = cell.Style.WithAttr(twin.AttrReverse)
+		}
+	}
+
+	if currentLineBackground != nil {
+		line.Trailer = line.Trailer.WithBackground(*currentLineBackground)
+	}
+}
+
+// A line's cells with all highlighting done, before wrapping and adding line
+// numbers. Header lines are rendered from this as well.
+func (p *Pager) highlightedLine(line reader.NumberedLine) textstyles.StyledRunesWithTrailer {
+	if p.ShowEscapes {
+		line.Line = line.Line.WithEscapesShown()
+	}
+
+	textStyle := plainTextStyle
+	if p.ColorDiffs {
+		if diffStyle := diffLineStyle(p.unfilteredReader(), line); diffStyle != nil {
+			textStyle = *diffStyle
+		}
 	}
+
+	highlighted := line.HighlightedTokens(textStyle, searchHitStyle, p.searchPattern, p.highlights()...)
+	if p.highlightedColumn > 0 {
+		// Before marking whitespace, which would make trailing spaces look
+		// like a column
+		highlightColumn(highlighted.StyledRunes, p.highlightedColumn, p.ColumnDelimiter)
+	}
+	if p.ShowWhitespace {
+		markWhitespace(highlighted.StyledRunes)
+	}
+	if p.DimNonMatchingLines && p.searchPattern != nil && !highlighted.ContainsSearchHit {
+		for i := range highlighted.StyledRunes {
+			cell := &highlighted.StyledRunes[i]
+			cell.Style = cell.Style.WithAttr(twin.AttrDim)
+		}
+	}
+
+	return highlighted
 }
 
 // Render one input line into one or more screen lines.
@@ -208,12 +346,21 @@ func (p *Pager) internalRenderLines(highlightSearchHitLines bool) renderedScreen
 //
 // lineNumber and numberPrefixLength are required for knowing how much to
 // indent, and to (optionally) render the line number.
-func (p *Pager) renderLine(line reader.NumberedLine, numberPrefixLength int, highlightSearchHitLines bool) []renderedLine {
-	highlighted := line.HighlightedTokens(plainTextStyle, searchHitStyle, p.searchPattern)
+//
+// currentLine should be the topmost line on screen, for HighlightCurrentLine
+// to know which line to highlight, and for RelativeLineNumbers to know what to
+// count from. Pass nil if the result will not be shown.
+//
+// Lines hidden by SqueezeBlankLines are rendered into zero screen lines.
+func (p *Pager) renderLine(line reader.NumberedLine, numberPrefixLength int, highlightSearchHitLines bool, currentLine *linemetadata.Index) []renderedLine {
+	if p.isSqueezedAway(line) {
+		return []renderedLine{}
+	}
+
+	highlighted := p.highlightedLine(line)
 	var wrapped []textstyles.StyledRunesWithTrailer
 	if p.WrapLongLines {
-		width, _ := p.screen.Size()
-		wrapped = wrapLine(width-numberPrefixLength, highlighted.StyledRunes)
+		wrapped = wrapLine(p.contentWidth()-numberPrefixLength, highlighted.StyledRunes)
 	} else {
 		// All on one line
 		wrapped = []textstyles.StyledRunesWithTrailer{{
@@ -228,8 +375,12 @@ func (p *Pager) renderLine(line reader.NumberedLine, numberPrefixLength int, hig
 		for i := range wrapped {
 			line := &wrapped[i] // We need a pointer to modify in place, otherwise setting the trailer won't have any effect
 			if line.ContainsSearchHit {
-				// Highlight this line!
+				// Highlight this line! Except for the hits themselves, they
+				// already stand out and should keep doing so.
 				for i := range line.StyledRunes {
+					if line.StyledRunes[i].IsSearchHit {
+						continue
+					}
 					line.StyledRunes[i].Style = line.StyledRunes[i].Style.WithBackground(*searchHitLineBackground)
 				}
 				line.Trailer = line.Trailer.WithBackground(*searchHitLineBackground)
@@ -237,15 +388,29 @@ func (p *Pager) renderLine(line reader.NumberedLine, numberPrefixLength int, hig
 		}
 	}
 
+	isCurrentLine := currentLine != nil && line.Index == *currentLine
+	if p.HighlightCurrentLine && isCurrentLine {
+		for i := range wrapped {
+			highlightCurrentLine(&wrapped[i])
+		}
+	}
+
+	timeDelta := ""
+	if p.ShowTimeDeltas {
+		timeDelta = p.timeDeltaText(line)
+	}
+
 	rendered := make([]renderedLine, 0)
 	for wrapIndex, subLine := range wrapped {
-		lineNumber := line.Number
+		lineNumber := p.lineNumberToShow(line, currentLine)
 		visibleLineNumber := &lineNumber
+		visibleTimeDelta := timeDelta
 		if wrapIndex > 0 {
 			visibleLineNumber = nil
+			visibleTimeDelta = ""
 		}
 
-		decorated := p.decorateLine(visibleLineNumber, numberPrefixLength, subLine.StyledRunes)
+		decorated := p.decorateLine(visibleLineNumber, visibleTimeDelta, numberPrefixLength, subLine.StyledRunes)
 
 		rendered = append(rendered, renderedLine{
 			inputLineIndex:    line.Index,
@@ -268,13 +433,18 @@ func (p *Pager) renderLine(line reader.NumberedLine, numberPrefixLength int, hig
 }
 
 // Take a rendered line and decorate as needed:
+//   - Time delta, see ShowTimeDeltas
 //   - Line number, or leading whitespace for wrapped lines
 //   - Scroll left indicator
 //   - Scroll right indicator
-func (p *Pager) decorateLine(lineNumberToShow *linemetadata.Number, numberPrefixLength int, contents []textstyles.CellWithMetadata) []textstyles.CellWithMetadata {
-	width, _ := p.screen.Size()
+func (p *Pager) decorateLine(lineNumberToShow *linemetadata.Number, timeDelta string, numberPrefixLength int, contents []textstyles.CellWithMetadata) []textstyles.CellWithMetadata {
+	width := p.contentWidth()
 	newLine := make([]textstyles.CellWithMetadata, 0, width)
-	newLine = append(newLine, createLinePrefix(lineNumberToShow, numberPrefixLength)...)
+	timeDeltasWidth := p.timeDeltasWidth()
+	if timeDeltasWidth > 0 {
+		newLine = append(newLine, createTimeDeltaPrefix(timeDelta, timeDeltasWidth)...)
+	}
+	newLine = append(newLine, createLinePrefix(lineNumberToShow, numberPrefixLength-timeDeltasWidth)...)
 
 	// Find the first and last fully visible runes.
 	var firstVisibleRuneIndex *int
@@ -411,3 +581,39 @@ func createLinePrefix(lineNumber *linemetadata.Number, numberPrefixLength int) [
 
 	return lineNumberPrefix
 }
+
+// Make tabs and trailing spaces visible, for ShowWhitespace. Only runes and
+// styles are changed, so column positions and search hits stay where they
+// were.
+func markWhitespace(cells []textstyles.CellWithMetadata) {
+	trailing := true
+	for i := len(cells) - 1; i >= 0; i-- {
+		cell := &cells[i]
+		if cell.IsTab {
+			if cell.StartsTab {
+				cell.Rune = '→'
+				markWhitespaceStyle(cell)
+			}
+			continue
+		}
+
+		if cell.Rune != ' ' {
+			trailing = false
+			continue
+		}
+
+		if trailing {
+			cell.Rune = '·'
+			markWhitespaceStyle(cell)
+		}
+	}
+}
+
+func markWhitespaceStyle(cell *textstyles.CellWithMetadata) {
+	if cell.IsSearchHit {
+		// Search hits must stay visible
+		return
+	}
+
+	cell.Style = whitespaceStyle.WithBackground(cell.Style.Background())
+}
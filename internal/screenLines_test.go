@@ -1,11 +1,14 @@
 package internal
 
 import (
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/google/go-cmp/cmp"
 	"github.com/walles/moor/v2/internal/linemetadata"
 	"github.com/walles/moor/v2/internal/reader"
@@ -20,7 +23,7 @@ import (
 func renderedToString(row []textstyles.CellWithMetadata) string {
 	rowString := ""
 	for _, cell := range row {
-		rowString += string(cell.Rune)
+		rowString += string(cell.Rune) + cell.Combining()
 	}
 
 	return strings.TrimRight(rowString, " ")
@@ -41,7 +44,7 @@ func testHorizontalCropping(t *testing.T, contents string, firstVisibleColumn in
 	numberedLine := reader.GetLine(linemetadata.IndexFromZeroBased(0))
 	assert.Assert(t, numberedLine != nil)
 
-	screenLine := pager.renderLine(*numberedLine, pager.getLineNumberPrefixLength(numberedLine.Number), true)
+	screenLine := pager.renderLine(*numberedLine, pager.getLineNumberPrefixLength(numberedLine.Number), true, nil)
 	assert.Equal(t, renderedToString(screenLine[0].cells), expected)
 }
 
@@ -57,6 +60,24 @@ func TestCreateScreenLineCanScrollRight(t *testing.T) {
 	testHorizontalCropping(t, "abc", 0, 1, "a>")
 }
 
+// The scroll right hint should replace the last visible cell of a colored line,
+// without shifting anything
+func TestScrollRightHintOverlaysColoredLine(t *testing.T) {
+	pager := createLinesPager(t, 5, 2, "\x1b[31mabc\x1b[0mdefgh")
+	pager.ShowStatusBar = false
+	ellipsis := twin.StyleDefault.WithForeground(twin.NewColor16(4))
+	pager.ScrollRightHint = textstyles.CellWithMetadata{Rune: '…', Style: ellipsis}
+
+	pager.redraw("")
+	row := pager.screen.(*twin.FakeScreen).GetRow(0)
+	assert.Equal(t, rowToString(row), "abcd…")
+
+	red := twin.StyleDefault.WithForeground(twin.NewColor16(1))
+	assert.Equal(t, row[2].Style, red)
+	assert.Equal(t, row[3].Style, twin.StyleDefault)
+	assert.Equal(t, row[4].Style, ellipsis)
+}
+
 func TestCreateScreenLineCanAlmostScrollRight(t *testing.T) {
 	testHorizontalCropping(t, "abc", 0, 2, "abc")
 }
@@ -117,7 +138,7 @@ func TestSearchHighlight(t *testing.T) {
 		searchPattern: regexp.MustCompile("\""),
 	}
 
-	rendered := pager.renderLine(*numberedLine, pager.getLineNumberPrefixLength(numberedLine.Number), true)
+	rendered := pager.renderLine(*numberedLine, pager.getLineNumberPrefixLength(numberedLine.Number), true, nil)
 	assert.DeepEqual(t, []renderedLine{
 		{
 			inputLineIndex:    linemetadata.Index{},
@@ -423,3 +444,428 @@ func BenchmarkRenderLines(b *testing.B) {
 		pager.renderLines()
 	}
 }
+
+// Search hits should stay highlighted after the user is done searching
+func TestSearchHighlightWhileViewing(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "abc\nxbx")
+	assert.NilError(t, reader.Wait())
+	screen := twin.NewFakeScreen(20, 5)
+	pager := NewPager(reader)
+	pager.screen = screen
+	pager.ShowLineNumbers = false
+	pager.showLineNumbers = false
+
+	pager.searchString = "b"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.mode = PagerModeViewing{pager: pager}
+	pager.redraw("")
+
+	assert.Equal(t, screen.GetRow(0)[1].Rune, 'b')
+	assert.Assert(t, screen.GetRow(0)[1].Style.Equal(searchHitStyle))
+	assert.Assert(t, screen.GetRow(0)[0].Style.Equal(twin.StyleDefault))
+	assert.Assert(t, screen.GetRow(1)[1].Style.Equal(searchHitStyle))
+
+	// 'U' clears the highlighting
+	pager.mode.onRune('U')
+	pager.redraw("")
+	assert.Assert(t, screen.GetRow(0)[1].Style.Equal(twin.StyleDefault))
+}
+
+// Only the matched cells should get the search hit style, with the rest of the
+// line getting the softer line background. Wide runes, color codes and wrapping
+// must not throw the highlight off.
+func TestSearchHitCellsOnly(t *testing.T) {
+	defer func(saved *twin.Color) { searchHitLineBackground = saved }(searchHitLineBackground)
+	searchHitLineBackground = &red
+
+	pager := createLinesPager(t, 10, 4, "日本\x1b[31mfoo\x1b[0mbarbaz!", "other")
+	pager.WrapLongLines = true
+	pager.ShowStatusBar = false
+	pager.searchPattern = regexp.MustCompile("obarb")
+
+	rendered := pager.renderLines()
+	assert.Equal(t, "日本foobar", renderedToString(rendered.lines[0].cells))
+	assert.Equal(t, "baz!", renderedToString(rendered.lines[1].cells))
+	assert.Equal(t, "other", renderedToString(rendered.lines[2].cells))
+
+	for i, cell := range rendered.lines[0].cells[:len([]rune("日本foobar"))] {
+		// The second "o" and "bar"
+		isHit := i >= 4
+		assert.Equal(t, cell.IsSearchHit, isHit, "Column %d", i)
+		if isHit {
+			assert.Equal(t, searchHitStyle, cell.Style, "Column %d", i)
+		} else {
+			assert.Equal(t, red, cell.Style.Background(), "Column %d", i)
+		}
+	}
+
+	// The "foo" color should survive the line background
+	assert.Equal(t, twin.NewColor16(1), rendered.lines[0].cells[2].Style.Foreground())
+
+	for i, cell := range rendered.lines[1].cells[:len("baz!")] {
+		// The "b" in "baz"
+		isHit := i == 0
+		assert.Equal(t, cell.IsSearchHit, isHit, "Column %d", i)
+		if isHit {
+			assert.Equal(t, searchHitStyle, cell.Style, "Column %d", i)
+		} else {
+			assert.Equal(t, red, cell.Style.Background(), "Column %d", i)
+		}
+	}
+
+	// Lines without hits get no background
+	assert.Equal(t, twin.ColorDefault, rendered.lines[2].cells[0].Style.Background())
+}
+
+// Combining accents share cells with the letters they modify, so they must not
+// shift search hits or wrap points
+func TestSearchHitAfterCombiningAccents(t *testing.T) {
+	pager := createLinesPager(t, 5, 4, "e\u0301e\u0301e\u0301xe\u0301e\u0301")
+	pager.WrapLongLines = true
+	pager.ShowStatusBar = false
+	pager.searchPattern = regexp.MustCompile("x")
+
+	rendered := pager.renderLines()
+	assert.Equal(t, "e\u0301e\u0301e\u0301xe\u0301", renderedToString(rendered.lines[0].cells))
+	assert.Equal(t, "e\u0301", renderedToString(rendered.lines[1].cells))
+
+	for i, cell := range rendered.lines[0].cells {
+		assert.Equal(t, cell.IsSearchHit, i == 3, "Column %d", i)
+	}
+
+	// The screen gets one cell per letter, with the accents attached
+	screen := pager.screen.(*twin.FakeScreen)
+	pager.redraw("")
+	assert.Equal(t, 'e', screen.GetRow(0)[2].Rune)
+	assert.Equal(t, "\u0301", screen.GetRow(0)[2].Combining())
+	assert.Equal(t, 'x', screen.GetRow(0)[3].Rune)
+	assert.Equal(t, "", screen.GetRow(0)[3].Combining())
+}
+
+func TestHighlightCurrentLine(t *testing.T) {
+	defer func(saved *twin.Color) { searchHitLineBackground = saved }(searchHitLineBackground)
+	searchHitLineBackground = nil
+
+	defer func() { currentLineBackground = nil }()
+	currentLineBackground = &red
+
+	pager := createLinesPager(t, 8, 4, "first line", "second", "third", "fourth", "fifth", "sixth")
+	pager.HighlightCurrentLine = true
+	pager.WrapLongLines = true
+	pager.ShowStatusBar = false
+	pager.searchPattern = regexp.MustCompile("rst")
+
+	rendered := pager.renderLines()
+	assert.Equal(t, "first", renderedToString(rendered.lines[0].cells))
+	assert.Equal(t, "line", renderedToString(rendered.lines[1].cells))
+	assert.Equal(t, "second", renderedToString(rendered.lines[2].cells))
+
+	// Both sub lines of the wrapped current line should be highlighted...
+	assert.Equal(t, red, rendered.lines[0].cells[0].Style.Background())
+	assert.Equal(t, red, rendered.lines[1].cells[0].Style.Background())
+	assert.Equal(t, red, rendered.lines[1].trailer.Background())
+
+	// ... except for the search hit
+	assert.Assert(t, rendered.lines[0].cells[2].IsSearchHit)
+	assert.Equal(t, searchHitStyle, rendered.lines[0].cells[2].Style)
+
+	// Other lines should not be highlighted
+	assert.Equal(t, twin.ColorDefault, rendered.lines[2].cells[0].Style.Background())
+
+	// Scrolling down moves the highlight
+	pager.scrollPosition = pager.scrollPosition.NextLine(2)
+	rendered = pager.renderLines()
+	assert.Equal(t, "second", renderedToString(rendered.lines[0].cells))
+	assert.Equal(t, red, rendered.lines[0].cells[0].Style.Background())
+	assert.Equal(t, twin.ColorDefault, rendered.lines[1].cells[0].Style.Background())
+}
+
+func TestHighlightCurrentLineDisabled(t *testing.T) {
+	defer func() { currentLineBackground = nil }()
+	currentLineBackground = &red
+
+	pager := createLinesPager(t, 8, 4, "first", "second")
+	pager.ShowStatusBar = false
+
+	rendered := pager.renderLines()
+	assert.Equal(t, twin.ColorDefault, rendered.lines[0].cells[0].Style.Background())
+}
+
+func testLineNumbersWidth(t *testing.T, lineCount int, expectedFirstRow string) {
+	lines := []string{}
+	for i := 1; i <= lineCount; i++ {
+		lines = append(lines, "x")
+	}
+	pager := createLinesPager(t, 20, 5, lines...)
+	pager.showLineNumbers = true
+
+	rendered := pager.renderLines()
+	assert.Equal(t, len(expectedFirstRow)-1, rendered.numberPrefixWidth, "%d lines", lineCount)
+	assert.Equal(t, expectedFirstRow, renderedToString(rendered.lines[0].cells), "%d lines", lineCount)
+}
+
+func TestLineNumbersWidth(t *testing.T) {
+	// Never narrower than three digits plus a separator
+	testLineNumbersWidth(t, 9, "  1 x")
+	testLineNumbersWidth(t, 99, "  1 x")
+
+	// Sized for the last line of the input, not the last line on screen
+	testLineNumbersWidth(t, 1000, "   1 x")
+	testLineNumbersWidth(t, 100_000, "      1 x")
+}
+
+// When following growing input, the line numbers column should widen once the
+// line count passes a power of ten
+func TestLineNumbersWidthGrowing(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close() //nolint:errcheck
+
+	// NewFromStream() wants some bytes to look at before returning
+	go func() {
+		_, _ = pipeWriter.Write([]byte(strings.Repeat("x\n", 999)))
+	}()
+
+	reader, err := reader.NewFromStream("", pipeReader, formatters.TTY16m, reader.ReaderOptions{})
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 999)
+
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.mode.onRune('F')
+	assert.Equal(t, 4, pager.renderLines().numberPrefixWidth)
+
+	_, err = pipeWriter.Write([]byte("x\n"))
+	assert.NilError(t, err)
+	awaitLineCount(t, reader, 1000)
+	pager.handleMoreLinesAvailable()
+
+	rendered := pager.renderLines()
+	assert.Equal(t, 5, rendered.numberPrefixWidth)
+	assert.Equal(t, "1000 x", renderedToString(rendered.lines[len(rendered.lines)-1].cells))
+}
+
+func TestSqueezeBlankLines(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "", "", "", "b", "", "c", "d", "", "", "", "e")
+	pager.ShowStatusBar = false
+	pager.SqueezeBlankLines = true
+
+	assert.DeepEqual(t, []string{"a", "", "b", "", "c"}, screenRows(pager))
+
+	pager.SqueezeBlankLines = false
+	assert.DeepEqual(t, []string{"a", "", "", "", "b"}, screenRows(pager))
+}
+
+func TestSqueezeBlankLinesScrolledToEnd(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "", "", "", "b", "", "c", "d", "", "", "", "e")
+	pager.ShowStatusBar = false
+	pager.SqueezeBlankLines = true
+
+	pager.scrollToEnd()
+	assert.DeepEqual(t, []string{"", "c", "d", "", "e"}, screenRows(pager))
+	assert.Assert(t, pager.isScrolledToEnd())
+
+	// Scrolling up one row should skip over the squeezed lines
+	pager.scrollPosition = pager.scrollPosition.PreviousLine(1)
+	assert.DeepEqual(t, []string{"b", "", "c", "d", ""}, screenRows(pager))
+	assert.Assert(t, !pager.isScrolledToEnd())
+}
+
+func TestSqueezeBlankLinesAtEnd(t *testing.T) {
+	// NewFromTextForTesting() would drop the trailing blank lines
+	reader, err := reader.NewFromStream(
+		"",
+		strings.NewReader("a\nb\nc\n\n\n\n"),
+		formatters.TTY,
+		reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Wait())
+	assert.Equal(t, 6, reader.GetLineCount())
+
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(20, 3)
+	pager.showLineNumbers = false
+	pager.ShowStatusBar = false
+	pager.SqueezeBlankLines = true
+
+	pager.scrollToEnd()
+	assert.DeepEqual(t, []string{"b", "c", ""}, screenRows(pager))
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestSqueezeBlankLinesKeepsIndices(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "", "", "", "b", "", "c", "d", "", "", "", "e")
+	pager.ShowStatusBar = false
+	pager.SqueezeBlankLines = true
+
+	// Line numbers should still be those of the input
+	pager.showLineNumbers = true
+	assert.DeepEqual(t, []string{"  1 a", "  2", "  5 b", "  6", "  7 c"}, screenRows(pager))
+	pager.showLineNumbers = false
+
+	// Going to a squeezed away line should show the next visible one
+	pager.goToLine(linemetadata.IndexFromOneBased(3))
+	assert.Equal(t, 4, pager.lineIndex().Index())
+	assert.Equal(t, "b", screenRows(pager)[0])
+
+	pager.searchPattern = regexp.MustCompile("e")
+	pager.scrollToSearchHits()
+	lines := pager.renderLines().lines
+	assert.Equal(t, 11, lines[len(lines)-1].inputLineIndex.Index())
+}
+
+func TestToggleSqueezeBlankLinesKeepsTopLine(t *testing.T) {
+	pager := createLinesPager(t, 20, 5, "a", "", "", "", "b", "", "c", "d", "", "", "", "e")
+	pager.ShowStatusBar = false
+	pager.SqueezeBlankLines = true
+	pager.SqueezeBlankLines = false
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromOneBased(5), "test")
+	assert.Equal(t, "b", screenRows(pager)[0])
+
+	pager.toggleSqueezeBlankLines()
+	assert.Assert(t, pager.SqueezeBlankLines)
+	assert.Equal(t, "b", screenRows(pager)[0])
+
+	pager.toggleSqueezeBlankLines()
+	assert.Assert(t, !pager.SqueezeBlankLines)
+	assert.Equal(t, "b", screenRows(pager)[0])
+}
+
+func TestShowWhitespaceTrailingSpaces(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, "a b  ", "c")
+	pager.ShowWhitespace = true
+
+	cells := pager.renderLines().lines[0].cells
+	assert.Equal(t, "a b··", renderedToString(cells))
+
+	// Only the trailing spaces are marked
+	assert.Equal(t, twin.StyleDefault, cells[1].Style)
+	assert.Equal(t, whitespaceStyle, cells[3].Style)
+	assert.Equal(t, whitespaceStyle, cells[4].Style)
+
+	pager.ShowWhitespace = false
+	assert.Equal(t, "a b", renderedToString(pager.renderLines().lines[0].cells))
+}
+
+func TestShowWhitespaceTabs(t *testing.T) {
+	pager := createLinesPager(t, 20, 3, "a\tb\t", "c")
+	pager.ShowWhitespace = true
+
+	// Tabs keep their widths, so that columns don't move
+	cells := pager.renderLines().lines[0].cells
+	assert.Equal(t, "a→      b→", renderedToString(cells))
+	assert.Equal(t, 16, len(cells))
+	assert.Equal(t, whitespaceStyle, cells[1].Style)
+	assert.Equal(t, whitespaceStyle, cells[9].Style)
+}
+
+func TestShowWhitespaceKeepsSearchHits(t *testing.T) {
+	// Other tests may have set this, we want plain search hit styling
+	defer func(background *twin.Color) { searchHitLineBackground = background }(searchHitLineBackground)
+	searchHitLineBackground = nil
+
+	pager := createLinesPager(t, 20, 3, "ab  ", "c")
+	pager.ShowWhitespace = true
+	pager.searchString = "b "
+	pager.searchPattern = toPattern(pager.searchString)
+
+	cells := pager.renderLines().lines[0].cells
+	assert.Equal(t, "ab··", renderedToString(cells))
+	assert.Assert(t, cells[2].IsSearchHit)
+	assert.Equal(t, searchHitStyle, cells[2].Style)
+	assert.Equal(t, whitespaceStyle, cells[3].Style)
+
+	// Searching still sees the spaces
+	assert.Equal(t, "ab  ", pager.Reader().GetLine(linemetadata.Index{}).Plain())
+}
+
+// Wrapping should happen at word boundaries, with search hits staying on the
+// words they belong to
+func TestWordWrapSearchHits(t *testing.T) {
+	pager := createLinesPager(t, 12, 5, "the quick brown fox jumps", "abcdefghijklmnop")
+	pager.WrapLongLines = true
+	pager.ShowStatusBar = false
+	pager.searchPattern = regexp.MustCompile("fox|lmn")
+
+	rendered := pager.renderLines()
+	assert.Equal(t, "the quick", renderedToString(rendered.lines[0].cells))
+	assert.Equal(t, "brown fox", renderedToString(rendered.lines[1].cells))
+	assert.Equal(t, "jumps", renderedToString(rendered.lines[2].cells))
+
+	// A hard break would have given us "the quick br" and "own fox jump"
+	for i, cell := range rendered.lines[1].cells[:len("brown fox")] {
+		assert.Equal(t, i >= len("brown "), cell.IsSearchHit, "Column %d", i)
+	}
+
+	// Words longer than the screen width get a hard break
+	assert.Equal(t, "abcdefghijkl", renderedToString(rendered.lines[3].cells))
+	assert.Equal(t, "mnop", renderedToString(rendered.lines[4].cells))
+	assert.Assert(t, rendered.lines[3].cells[11].IsSearchHit)
+	assert.Assert(t, !rendered.lines[3].cells[10].IsSearchHit)
+	assert.Assert(t, rendered.lines[4].cells[1].IsSearchHit)
+	assert.Assert(t, !rendered.lines[4].cells[2].IsSearchHit)
+}
+
+func TestShowEscapes(t *testing.T) {
+	pager := createLinesPager(t, 30, 3, "\x1b[31mred\x1b[m plain", "c")
+
+	cells := pager.renderLines().lines[0].cells
+	assert.Equal(t, "red plain", renderedToString(cells))
+	assert.Equal(t, twin.StyleDefault.WithForeground(twin.NewColor16(1)), cells[0].Style)
+
+	typeRunes(pager, "E")
+	assert.Assert(t, pager.ShowEscapes)
+	cells = pager.renderLines().lines[0].cells
+	assert.Equal(t, "^[[31mred^[[m plain", renderedToString(cells))
+	for _, cell := range cells {
+		assert.Equal(t, twin.StyleDefault, cell.Style)
+	}
+
+	typeRunes(pager, "E")
+	assert.Assert(t, !pager.ShowEscapes)
+	assert.Equal(t, "red plain", renderedToString(pager.renderLines().lines[0].cells))
+}
+
+func TestDimNonMatchingLines(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "a match", "no hit", "match")
+	pager.DimNonMatchingLines = true
+	pager.searchPattern = regexp.MustCompile("match")
+
+	rendered := pager.renderLines()
+	assert.Equal(t, "no hit", renderedToString(rendered.lines[1].cells))
+	for _, cell := range rendered.lines[1].cells {
+		assert.Assert(t, cell.Style.HasAttr(twin.AttrDim))
+	}
+
+	// Matching lines are left alone, both the hits and the rest of the line
+	for _, line := range []renderedLine{rendered.lines[0], rendered.lines[2]} {
+		for _, cell := range line.cells {
+			assert.Assert(t, !cell.Style.HasAttr(twin.AttrDim), renderedToString(line.cells))
+		}
+	}
+	assert.Assert(t, rendered.lines[0].cells[2].IsSearchHit)
+}
+
+// Nothing to compare with without a search, don't dim anything
+func TestDimNonMatchingLinesWithoutSearch(t *testing.T) {
+	pager := createLinesPager(t, 20, 4, "a match", "no hit")
+	pager.DimNonMatchingLines = true
+
+	for _, line := range pager.renderLines().lines {
+		for _, cell := range line.cells {
+			assert.Assert(t, !cell.Style.HasAttr(twin.AttrDim))
+		}
+	}
+}
+
+func TestToggleDimNonMatchingLines(t *testing.T) {
+	pager := createLinesPager(t, 40, 4, "a match", "no hit")
+	pager.searchPattern = regexp.MustCompile("match")
+
+	pager.mode.onRune('D')
+	assert.Assert(t, pager.DimNonMatchingLines)
+	assert.Assert(t, pager.renderLines().lines[1].cells[0].Style.HasAttr(twin.AttrDim))
+
+	pager.mode.onRune('D')
+	assert.Assert(t, !pager.DimNonMatchingLines)
+	assert.Assert(t, !pager.renderLines().lines[1].cells[0].Style.HasAttr(twin.AttrDim))
+}
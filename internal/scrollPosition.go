@@ -42,6 +42,8 @@ type scrollPositionCanonical struct {
 	showLineNumbers bool // From pager
 	showStatusBar   bool // From pager
 	wrapLongLines   bool // From pager
+	squeezeBlanks   bool // From pager
+	headerLines     int  // From pager.headerLineCount()
 
 	pagerLineCount int // From pager.Reader().GetLineCount()
 
@@ -50,7 +52,7 @@ type scrollPositionCanonical struct {
 }
 
 func canonicalFromPager(pager *Pager) scrollPositionCanonical {
-	width, _ := pager.screen.Size()
+	width := pager.contentWidth()
 	height := pager.visibleHeight()
 	return scrollPositionCanonical{
 		width:           width,
@@ -58,6 +60,8 @@ func canonicalFromPager(pager *Pager) scrollPositionCanonical {
 		showLineNumbers: pager.showLineNumbers,
 		showStatusBar:   pager.ShowStatusBar,
 		wrapLongLines:   pager.WrapLongLines,
+		squeezeBlanks:   pager.SqueezeBlankLines,
+		headerLines:     pager.headerLineCount(),
 
 		pagerLineCount: pager.Reader().GetLineCount(),
 
@@ -126,13 +130,14 @@ func NewScrollPositionFromIndex(index linemetadata.Index, name string) scrollPos
 
 // Move towards the top until deltaScreenLines is not negative any more
 func (si *scrollPositionInternal) handleNegativeDeltaScreenLines(pager *Pager) {
-	for !si.lineIndex.IsZero() && si.deltaScreenLines < 0 {
+	top := pager.firstScrollableIndex()
+	for si.lineIndex.IsAfter(top) && si.deltaScreenLines < 0 {
 		// Render the previous line
 		previousLineIndex := si.lineIndex.NonWrappingAdd(-1)
 		previousLine := pager.Reader().GetLine(previousLineIndex)
 		previousSubLinesCount := 0
 		if previousLine != nil {
-			previousSubLines := pager.renderLine(*previousLine, si.getMaxNumberPrefixLength(pager), true)
+			previousSubLines := pager.renderLine(*previousLine, si.getMaxNumberPrefixLength(pager), true, nil)
 			previousSubLinesCount = len(previousSubLines)
 		}
 
@@ -142,8 +147,9 @@ func (si *scrollPositionInternal) handleNegativeDeltaScreenLines(pager *Pager) {
 		si.deltaScreenLines += previousSubLinesCount
 	}
 
-	if si.lineIndex.IsZero() && si.deltaScreenLines <= 0 {
+	if !si.lineIndex.IsAfter(top) && si.deltaScreenLines <= 0 {
 		// Can't go any higher
+		si.lineIndex = &top
 		si.deltaScreenLines = 0
 		return
 	}
@@ -166,14 +172,19 @@ func (si *scrollPositionInternal) handlePositiveDeltaScreenLines(pager *Pager) {
 			if line == nil {
 				panic(fmt.Errorf("Last line is nil"))
 			}
-			subLines := pager.renderLine(*line, maxPrefixLength, true)
+			subLines := pager.renderLine(*line, maxPrefixLength, true, nil)
 
 			// ... and go to the bottom of that.
 			si.deltaScreenLines = len(subLines) - 1
+			if si.deltaScreenLines < 0 {
+				// The last line was squeezed away, go to the bottom of the
+				// closest line above it that is visible
+				si.handleNegativeDeltaScreenLines(pager)
+			}
 			return
 		}
 
-		subLines := pager.renderLine(*line, maxPrefixLength, true)
+		subLines := pager.renderLine(*line, maxPrefixLength, true, nil)
 		if si.deltaScreenLines < len(subLines) {
 			// Sublines are within bounds!
 			return
@@ -212,7 +223,7 @@ func (si *scrollPositionInternal) emptyBottomLinesCount(pager *Pager) int {
 			break
 		}
 
-		subLines := pager.renderLine(*line, lastLineNumberWidth, true)
+		subLines := pager.renderLine(*line, lastLineNumberWidth, true, nil)
 		unclaimedViewportLines -= len(subLines)
 		if unclaimedViewportLines <= 0 {
 			return 0
@@ -271,6 +282,11 @@ func (si *scrollPositionInternal) canonicalize(pager *Pager) {
 
 	si.handleNegativeDeltaScreenLines(pager)
 	si.handlePositiveDeltaScreenLines(pager)
+	if top := pager.firstScrollableIndex(); si.lineIndex.IsBefore(top) {
+		// Above the first line below the header lines
+		si.lineIndex = &top
+		si.deltaScreenLines = 0
+	}
 	emptyBottomLinesCount := si.emptyBottomLinesCount(pager)
 	if emptyBottomLinesCount > 0 {
 		// First, adjust deltaScreenLines to get us to the top
@@ -344,6 +360,13 @@ func (p *Pager) isScrolledToEnd() bool {
 		return true
 	}
 	lastInputLineIndex := *linemetadata.IndexFromLength(inputLineCount)
+	lastInputLine := p.Reader().GetLine(lastInputLineIndex)
+	for p.isSqueezedAway(*lastInputLine) {
+		// Squeezed away lines are never on screen, look for the last line
+		// that can be
+		lastInputLineIndex = lastInputLineIndex.NonWrappingAdd(-1)
+		lastInputLine = p.Reader().GetLine(lastInputLineIndex)
+	}
 
 	visibleLines := p.renderLines().lines
 	lastVisibleLine := visibleLines[len(visibleLines)-1]
@@ -354,8 +377,7 @@ func (p *Pager) isScrolledToEnd() bool {
 
 	// Last line is on screen, now we need to figure out whether we can see all
 	// of it
-	lastInputLine := p.Reader().GetLine(lastInputLineIndex)
-	lastInputLineRendered := p.renderLine(*lastInputLine, p.getLineNumberPrefixLength(lastInputLine.Number), true)
+	lastInputLineRendered := p.renderLine(*lastInputLine, p.getLineNumberPrefixLength(lastInputLine.Number), true, nil)
 	lastRenderedSubLine := lastInputLineRendered[len(lastInputLineRendered)-1]
 
 	// If the last visible subline is the same as the last possible subline then
@@ -363,6 +385,50 @@ func (p *Pager) isScrolledToEnd() bool {
 	return lastVisibleLine.wrapIndex == lastRenderedSubLine.wrapIndex
 }
 
+// ScrollOff, limited so that the margins above and below the target line don't
+// overlap
+func (p *Pager) effectiveScrollOff() int {
+	return max(0, min(p.ScrollOff, (p.visibleHeight()-1)/2))
+}
+
+// Scroll as little as possible to get at least ScrollOff screen rows above and
+// below the given input line. Near the start and end of the input there may be
+// less context than that.
+//
+// Does nothing if the line isn't on screen.
+func (p *Pager) applyScrollOff(index linemetadata.Index) {
+	scrollOff := p.effectiveScrollOff()
+	if scrollOff == 0 {
+		return
+	}
+
+	firstRow := -1
+	lastRow := -1
+	for row, line := range p.renderLines().lines {
+		if line.inputLineIndex != index {
+			continue
+		}
+		if firstRow == -1 {
+			firstRow = row
+		}
+		lastRow = row
+	}
+	if firstRow == -1 {
+		// Not on screen
+		return
+	}
+
+	if firstRow < scrollOff {
+		p.scrollPosition = p.scrollPosition.PreviousLine(scrollOff - firstRow)
+		return
+	}
+
+	rowsBelow := p.visibleHeight() - 1 - lastRow
+	if rowsBelow < scrollOff {
+		p.scrollPosition = p.scrollPosition.NextLine(scrollOff - rowsBelow)
+	}
+}
+
 // Returns nil if there are no lines
 func (p *Pager) getLastVisiblePosition() *scrollPosition {
 	rendered := p.renderLines()
@@ -0,0 +1,94 @@
+package internal
+
+import "github.com/walles/moor/v2/internal/textstyles"
+
+const scrollbarTrackRune = '│'
+const scrollbarThumbRune = '█'
+
+// Screen columns available for line numbers and line contents. With
+// ShowScrollbar, the rightmost column is taken by the scrollbar.
+func (p *Pager) contentWidth() int {
+	width, _ := p.screen.Size()
+	if p.ShowScrollbar {
+		return width - 1
+	}
+	return width
+}
+
+// Which scrollbar rows the thumb covers, from top up to but not including
+// bottom. The thumb is always at least one row high.
+//
+// firstLine and lastLine are the zero based indices of the first and last
+// input lines on screen.
+func scrollbarThumb(firstLine int, lastLine int, lineCount int, height int) (top int, bottom int) {
+	if lineCount <= 0 {
+		return 0, height
+	}
+
+	top = firstLine * height / lineCount
+	bottom = ((lastLine+1)*height + lineCount - 1) / lineCount // Rounded up
+
+	top = min(max(top, 0), height-1)
+	bottom = min(max(bottom, top+1), height)
+	return top, bottom
+}
+
+// Which scrollbar rows have search hits on them, based on what the search hit
+// counter has found so far. Returns nil if there is no search.
+func (p *Pager) scrollbarHitRows(lineCount int, height int) []bool {
+	counter := p.getSearchHitCounter()
+	if counter == nil || lineCount <= 0 {
+		return nil
+	}
+
+	rows := make([]bool, height)
+	for _, hit := range counter.getHits() {
+		row := hit.Index() * height / lineCount
+		if row < height {
+			rows[row] = true
+		}
+	}
+	return rows
+}
+
+// Render the scrollbar next to the scrollable lines, one cell per screen row
+func (p *Pager) renderScrollbar(lines []renderedLine) []textstyles.CellWithMetadata {
+	height := p.visibleHeight()
+	if height <= 0 {
+		return nil
+	}
+
+	top, bottom := 0, height
+	lineCount := p.Reader().GetLineCount()
+	if len(lines) > 0 {
+		top, bottom = scrollbarThumb(
+			lines[0].inputLineIndex.Index(),
+			lines[len(lines)-1].inputLineIndex.Index(),
+			lineCount,
+			height)
+	}
+	hitRows := p.scrollbarHitRows(lineCount, height)
+
+	cells := make([]textstyles.CellWithMetadata, 0, height)
+	for row := range height {
+		cell := textstyles.CellWithMetadata{Rune: scrollbarTrackRune, Style: lineNumbersStyle}
+		if row >= top && row < bottom {
+			cell.Rune = scrollbarThumbRune
+		}
+		if hitRows != nil && hitRows[row] {
+			cell.Style = searchHitStyle
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells
+}
+
+// Draw the scrollbar in the rightmost column, next to the scrollable lines
+// starting at firstRow
+func (p *Pager) drawScrollbar(lines []renderedLine, firstRow int) {
+	width, _ := p.screen.Size()
+	for i, cell := range p.renderScrollbar(lines) {
+		p.screen.SetCell(width-1, firstRow+i, cell.ToStyledRune())
+	}
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestScrollbarThumb(t *testing.T) {
+	assertThumb := func(firstLine int, lastLine int, lineCount int, height int, expectedTop int, expectedBottom int) {
+		t.Helper()
+		top, bottom := scrollbarThumb(firstLine, lastLine, lineCount, height)
+		assert.Equal(t, expectedTop, top, "top")
+		assert.Equal(t, expectedBottom, bottom, "bottom")
+	}
+
+	// Everything fits, the thumb covers all of the scrollbar
+	assertThumb(0, 2, 3, 5, 0, 5)
+
+	// Start, middle and end of 100 lines with 5 on screen
+	assertThumb(0, 4, 100, 5, 0, 1)
+	assertThumb(50, 54, 100, 5, 2, 3)
+	assertThumb(95, 99, 100, 5, 4, 5)
+
+	// Many lines, the thumb is still one row high
+	assertThumb(5000, 5004, 10000, 5, 2, 3)
+
+	// Half of the lines on screen, half of the scrollbar covered
+	assertThumb(0, 9, 20, 10, 0, 5)
+	assertThumb(10, 19, 20, 10, 5, 10)
+}
+
+// The rightmost character of each screen row above the status bar
+func scrollbarColumn(pager *Pager) string {
+	rows := screenRows(pager)
+	column := ""
+	for _, row := range rows[:len(rows)-1] {
+		runes := []rune(row)
+		column += string(runes[len(runes)-1])
+	}
+	return column
+}
+
+func TestScrollbarThumbPosition(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.ShowScrollbar = true
+
+	assert.Equal(t, "██│││", scrollbarColumn(pager))
+
+	pager.scrollPosition = NewScrollPositionFromIndex(pager.lineIndex().NonWrappingAdd(10), "TestScrollbarThumbPosition")
+	assert.Equal(t, "││██│", scrollbarColumn(pager))
+
+	pager.scrollToEnd()
+	assert.Equal(t, "│││██", scrollbarColumn(pager))
+}
+
+func TestScrollbarTakesOneColumn(t *testing.T) {
+	long := strings.Repeat("x", 30)
+
+	pager := createLinesPager(t, 20, 3, long)
+	assert.Equal(t, strings.Repeat("x", 19)+">", screenRows(pager)[0])
+
+	pager.ShowScrollbar = true
+	assert.Equal(t, strings.Repeat("x", 18)+">█", screenRows(pager)[0])
+}
+
+func TestScrollbarWrapping(t *testing.T) {
+	pager := createLinesPager(t, 11, 4, "0123456789012345")
+	pager.WrapLongLines = true
+	pager.ShowScrollbar = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "0123456789█", rows[0])
+	assert.Equal(t, "012345    █", rows[1])
+}
+
+func TestScrollbarSearchHits(t *testing.T) {
+	lines := []string{}
+	for i := range 20 {
+		if i == 15 {
+			lines = append(lines, "hit")
+		} else {
+			lines = append(lines, "miss")
+		}
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+	pager.ShowScrollbar = true
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	assert.Equal(t, "1 match", awaitSearchHitCountText(t, pager))
+
+	screenRows(pager)
+	screen := pager.screen.(*twin.FakeScreen)
+	for row := range 5 {
+		cell := screen.GetRow(row)[19]
+		if row == 3 {
+			assert.Equal(t, searchHitStyle, cell.Style, "row %d", row)
+		} else {
+			assert.Equal(t, lineNumbersStyle, cell.Style, "row %d", row)
+		}
+	}
+}
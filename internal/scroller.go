@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Scroller does the pager's scrolling, wrapping and clamping, but without any
+// terminal or user input. Give it a size, scroll it around and ask it what
+// would be on screen.
+//
+// Create using NewScroller().
+type Scroller struct {
+	pager *Pager
+}
+
+// The options' Width and Height are the initial size, change it using
+// Resize().
+//
+// The reader should be done reading before this is called, otherwise you'll
+// get whatever lines it has read so far.
+func NewScroller(r *reader.ReaderImpl, options RenderOptions) *Scroller {
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(options.Width, options.Height)
+	pager.ShowStatusBar = false
+	pager.WrapLongLines = options.WrapLongLines
+	pager.ShowLineNumbers = options.ShowLineNumbers
+	pager.showLineNumbers = options.ShowLineNumbers
+	pager.searchPattern = options.SearchPattern
+
+	return &Scroller{pager: pager}
+}
+
+// Resizing keeps the top line, and clamps the position if that would leave
+// empty rows at the bottom
+func (s *Scroller) Resize(width int, height int) {
+	s.pager.screen = twin.NewFakeScreen(width, height)
+}
+
+// Scroll this many screen lines towards the end, negative means towards the
+// start. Scrolling is clamped to the input, just like when paging.
+func (s *Scroller) Scroll(lines int) {
+	s.pager.scrollPosition = s.pager.scrollPosition.NextLine(lines)
+}
+
+func (s *Scroller) ScrollToStart() {
+	s.pager.scrollPosition = newScrollPosition("Scroller position")
+}
+
+func (s *Scroller) ScrollToEnd() {
+	s.pager.scrollToEnd()
+}
+
+// The input line shown at the top of the screen, or nil if there are no lines
+func (s *Scroller) TopLine() *linemetadata.Index {
+	return s.pager.lineIndex()
+}
+
+// One slice of cells per screen row, at most as many as the height
+func (s *Scroller) Rows() [][]twin.StyledRune {
+	rows := [][]twin.StyledRune{}
+	for _, line := range s.pager.renderLines().lines {
+		row := make([]twin.StyledRune, 0, len(line.cells))
+		for _, cell := range line.cells {
+			row = append(row, cell.ToStyledRune())
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"gotest.tools/v3/assert"
+)
+
+func TestScrollerClampsAtStart(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "a\nb\nc\nd")
+	scroller := NewScroller(r, RenderOptions{Width: 10, Height: 2})
+
+	scroller.Scroll(-5)
+	assert.Equal(t, 0, scroller.TopLine().Index())
+	assert.DeepEqual(t, []string{"a", "b"}, renderRowsToStrings(scroller.Rows()))
+}
+
+func TestScrollerClampsAtEnd(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "a\nb\nc\nd")
+	scroller := NewScroller(r, RenderOptions{Width: 10, Height: 2})
+
+	scroller.Scroll(1)
+	assert.DeepEqual(t, []string{"b", "c"}, renderRowsToStrings(scroller.Rows()))
+
+	// Don't leave empty rows at the bottom
+	scroller.Scroll(5)
+	assert.Equal(t, 2, scroller.TopLine().Index())
+	assert.DeepEqual(t, []string{"c", "d"}, renderRowsToStrings(scroller.Rows()))
+
+	scroller.ScrollToStart()
+	scroller.ScrollToEnd()
+	assert.DeepEqual(t, []string{"c", "d"}, renderRowsToStrings(scroller.Rows()))
+}
+
+func TestScrollerWrapped(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "first\nhello big world\nlast")
+	scroller := NewScroller(r, RenderOptions{
+		Width:         6,
+		Height:        2,
+		WrapLongLines: true,
+	})
+
+	// Scrolling is by screen lines, not by input lines
+	scroller.Scroll(2)
+	assert.Equal(t, 1, scroller.TopLine().Index())
+	assert.DeepEqual(t, []string{"big", "world"}, renderRowsToStrings(scroller.Rows()))
+
+	scroller.ScrollToEnd()
+	assert.DeepEqual(t, []string{"world", "last"}, renderRowsToStrings(scroller.Rows()))
+}
+
+func TestScrollerResize(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "a\nb\nc\nd")
+	scroller := NewScroller(r, RenderOptions{Width: 10, Height: 2})
+	scroller.ScrollToEnd()
+	assert.Equal(t, 2, scroller.TopLine().Index())
+
+	// Growing the screen at the end of the input should show more lines from
+	// above
+	scroller.Resize(10, 3)
+	assert.Equal(t, 1, scroller.TopLine().Index())
+	assert.DeepEqual(t, []string{"b", "c", "d"}, renderRowsToStrings(scroller.Rows()))
+}
+
+func TestScrollerEmpty(t *testing.T) {
+	r := reader.NewFromTextForTesting("", "")
+	scroller := NewScroller(r, RenderOptions{Width: 10, Height: 2})
+
+	scroller.Scroll(3)
+	assert.Assert(t, scroller.TopLine() == nil)
+	assert.Equal(t, 0, len(scroller.Rows()))
+}
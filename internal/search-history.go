@@ -304,8 +304,15 @@ func (h *SearchHistory) addEntry(entry string) {
 		return
 	}
 
+	writeHistoryFile(h.absFileName, h.entries)
+}
+
+// Write lines to a temp file and rename it into place, so that a failed write
+// doesn't destroy the existing history. Problems are logged but otherwise
+// ignored.
+func writeHistoryFile(absFileName string, lines []string) {
 	// Write new file to a temp file and rename it into place
-	tmpFilePath := h.absFileName + ".tmp"
+	tmpFilePath := absFileName + ".tmp"
 	f, err := os.Create(tmpFilePath)
 	if err != nil {
 		log.Infof("Could not create temp history file %s: %v", tmpFilePath, err)
@@ -327,9 +334,9 @@ func (h *SearchHistory) addEntry(entry string) {
 
 		if shouldRename {
 			// Rename temp file into place
-			err = os.Rename(tmpFilePath, h.absFileName)
+			err = os.Rename(tmpFilePath, absFileName)
 			if err != nil {
-				log.Infof("Could not rename temp history file %s to %s: %v", tmpFilePath, h.absFileName, err)
+				log.Infof("Could not rename temp history file %s to %s: %v", tmpFilePath, absFileName, err)
 				return
 			}
 		} else {
@@ -342,7 +349,7 @@ func (h *SearchHistory) addEntry(entry string) {
 	}()
 
 	writer := bufio.NewWriter(f)
-	for _, line := range h.entries {
+	for _, line := range lines {
 		_, err := writer.WriteString(line + "\n")
 		if err != nil {
 			log.Infof("Could not write to temp history file %s: %v", tmpFilePath, err)
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchHistoryNavigation(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "apa\nbepa\ncepa"))
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.searchHistory = &SearchHistory{entries: []string{"apa", "bepa"}}
+
+	search := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = search
+	search.onRune('c')
+
+	search.onKey(twin.KeyUp)
+	assert.Equal(t, "bepa", pager.searchString)
+
+	search.onKey(twin.KeyUp)
+	assert.Equal(t, "apa", pager.searchString)
+
+	// Already at the oldest entry, stay there
+	search.onKey(twin.KeyUp)
+	assert.Equal(t, "apa", pager.searchString)
+
+	search.onKey(twin.KeyDown)
+	assert.Equal(t, "bepa", pager.searchString)
+
+	// Past the end of the history, whatever the user typed should be back
+	search.onKey(twin.KeyDown)
+	assert.Equal(t, "c", pager.searchString)
+	assert.Equal(t, "c", search.inputBox.text)
+}
+
+func TestSearchHistoryAddEntry(t *testing.T) {
+	history := SearchHistory{}
+
+	history.addEntry("apa")
+	history.addEntry("apa")
+	assert.DeepEqual(t, []string{"apa"}, history.entries)
+
+	history.addEntry("bepa")
+	history.addEntry("apa")
+	assert.DeepEqual(t, []string{"bepa", "apa"}, history.entries)
+
+	// Empty searches should not be remembered
+	history.addEntry("")
+	assert.DeepEqual(t, []string{"bepa", "apa"}, history.entries)
+}
+
+func TestSearchHistoryPersistence(t *testing.T) {
+	t.Setenv("LESSSECURE", "")
+	historyFile := filepath.Join(t.TempDir(), "search_history")
+
+	history := BootSearchHistory(historyFile)
+	history.addEntry("apa")
+	history.addEntry("bepa")
+
+	// Simulate a pager restart
+	reloaded := BootSearchHistory(historyFile)
+	assert.DeepEqual(t, []string{"apa", "bepa"}, reloaded.entries)
+}
@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+)
+
+// How many lines the search hit counter looks at between checking whether it
+// should stop
+const searchHitCounterChunkSize = 10_000
+
+// Don't ask for redraws more often than this while counting
+const searchHitCounterProgressInterval = 100 * time.Millisecond
+
+// Counts the lines with search hits in the background, so that we can tell
+// the user things like "match 3 of 17".
+//
+// A hit is a line with at least one match in it, just like when moving between
+// hits using 'n' and 'p'.
+type searchHitCounter struct {
+	// All of these are immutable after creation
+	reader     reader.Reader
+	pattern    *regexp.Regexp
+	source     reader.Reader // The reader we're counting in, before any filtering
+	filter     string        // The filter that was active when we started counting
+	onProgress func()
+
+	lock sync.Mutex
+
+	// Indices of all lines with hits so far, in order
+	hits []linemetadata.Index
+
+	// Number of lines we have looked at
+	countedLines int
+
+	running bool
+	stopped bool
+}
+
+func newSearchHitCounter(r reader.Reader, pattern *regexp.Regexp, source reader.Reader, filter string, onProgress func()) *searchHitCounter {
+	return &searchHitCounter{
+		reader:     r,
+		pattern:    pattern,
+		source:     source,
+		filter:     filter,
+		onProgress: onProgress,
+	}
+}
+
+// Start counting in the background, unless we're already counting or have
+// already counted all available lines.
+func (c *searchHitCounter) update() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.running || c.stopped {
+		return
+	}
+
+	if c.countedLines >= c.reader.GetLineCount() {
+		// Nothing new to count
+		return
+	}
+
+	c.running = true
+	go func() {
+		defer func() {
+			PanicHandler("searchHitCounter.count()", recover(), debug.Stack())
+		}()
+
+		c.count()
+	}()
+}
+
+func (c *searchHitCounter) stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopped = true
+}
+
+// Count until we run out of lines, or until we get stopped
+func (c *searchHitCounter) count() {
+	lastProgress := time.Now()
+	for {
+		c.lock.Lock()
+		if c.stopped {
+			c.running = false
+			c.lock.Unlock()
+			return
+		}
+		firstIndex := c.countedLines
+		c.lock.Unlock()
+
+		// Near the end of the input we can get lines before firstIndex back,
+		// those we have already counted.
+		lines := c.reader.GetLines(linemetadata.IndexFromZeroBased(firstIndex), searchHitCounterChunkSize)
+		newHits := []linemetadata.Index{}
+		nextIndex := firstIndex
+		for _, line := range lines.Lines {
+			if line.Index.Index() < firstIndex {
+				continue
+			}
+
+			if c.pattern.MatchString(line.Plain()) {
+				newHits = append(newHits, line.Index)
+			}
+			nextIndex = line.Index.Index() + 1
+		}
+
+		c.lock.Lock()
+		c.hits = append(c.hits, newHits...)
+		c.countedLines = nextIndex
+		done := nextIndex == firstIndex
+		if done {
+			c.running = false
+		}
+		c.lock.Unlock()
+
+		if done {
+			c.onProgress()
+			return
+		}
+
+		if time.Since(lastProgress) > searchHitCounterProgressInterval {
+			c.onProgress()
+			lastProgress = time.Now()
+		}
+	}
+}
+
+// If we have counted more lines than there are, the input must have been
+// reloaded, and our counts are for something else
+func (c *searchHitCounter) hasCountedPast(lineCount int) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.countedLines > lineCount
+}
+
+// Returns the one based number of the hit on the given line, or 0 if that
+// line has no hit or hasn't been counted yet. Also returns the number of hits
+// counted so far, and whether counting is done.
+func (c *searchHitCounter) get(hitIndex *linemetadata.Index) (ordinal int, total int, done bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	total = len(c.hits)
+	done = !c.running && c.countedLines >= c.reader.GetLineCount()
+
+	if hitIndex == nil {
+		return
+	}
+
+	i, found := slices.BinarySearchFunc(c.hits, *hitIndex, func(a, b linemetadata.Index) int {
+		return a.Index() - b.Index()
+	})
+	if found {
+		ordinal = i + 1
+	}
+
+	return
+}
+
+// Indices of the lines with hits counted so far, in order
+func (c *searchHitCounter) getHits() []linemetadata.Index {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return slices.Clone(c.hits)
+}
+
+// Get a counter for the current search, starting a new one if the search,
+// filter or input has changed since last time. Returns nil if there is no
+// search.
+func (p *Pager) getSearchHitCounter() *searchHitCounter {
+	if p.searchPattern == nil || p.searchString == "" {
+		if p.searchHitCounter != nil {
+			p.searchHitCounter.stop()
+			p.searchHitCounter = nil
+		}
+		return nil
+	}
+
+	var source reader.Reader = _HelpReader
+	if p.pipedReader != nil && !p.isShowingHelp {
+		source = p.pipedReader
+	} else if !p.isShowingHelp {
+		p.readerLock.Lock()
+		source = p.readers[p.currentReader]
+		p.readerLock.Unlock()
+	}
+
+	filter := ""
+	if p.filterPattern != nil {
+		filter = p.filterPattern.String()
+		if p.filterInverted {
+			filter = "!" + filter
+		}
+	}
+
+	counter := p.searchHitCounter
+	if counter == nil ||
+		counter.source != source ||
+		counter.filter != filter ||
+		counter.pattern.String() != p.searchPattern.String() ||
+		counter.hasCountedPast(p.Reader().GetLineCount()) {
+		if counter != nil {
+			counter.stop()
+		}
+
+		events := p.screen.Events()
+		counter = newSearchHitCounter(p.Reader(), p.searchPattern, source, filter, func() {
+			// Ask the main loop for a redraw, unless one is coming anyway
+			select {
+			case events <- eventSearchHitsCounted{}:
+			default:
+			}
+		})
+		p.searchHitCounter = counter
+	}
+
+	counter.update()
+	return counter
+}
+
+// Something like "match 3 of 17", or "" if there is no search. While counting,
+// the total gets a "+" after it.
+func (p *Pager) searchHitCountText() string {
+	counter := p.getSearchHitCounter()
+	if counter == nil {
+		return ""
+	}
+
+	ordinal, total, done := counter.get(p.currentSearchHit)
+	totalString := fmt.Sprint(total)
+	if !done {
+		totalString += "+"
+	}
+
+	if ordinal > 0 {
+		return fmt.Sprintf("match %d of %s", ordinal, totalString)
+	}
+
+	if done && total == 0 {
+		return "no matches"
+	}
+	if done && total == 1 {
+		return "1 match"
+	}
+	return totalString + " matches"
+}
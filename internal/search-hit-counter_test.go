@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// Wait for the background counting to finish, then return the status text
+func awaitSearchHitCountText(t *testing.T, pager *Pager) string {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, _, done := pager.getSearchHitCounter().get(nil)
+		if done {
+			return pager.searchHitCountText()
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Search hit counting never finished")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSearchHitCountOrdinal(t *testing.T) {
+	lines := numberedLines("miss ", 1, 30)
+	for i := 3; i <= 30; i += 3 {
+		lines[i-1] = fmt.Sprintf("hit %d", i)
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	// The first hit is already on screen, so searching starts at the second one
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "match 2 of 10", awaitSearchHitCountText(t, pager))
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, 3*4, pager.currentSearchHit.Index()+1)
+	assert.Equal(t, "match 4 of 10", awaitSearchHitCountText(t, pager))
+
+	ordinal, total, done := pager.getSearchHitCounter().get(pager.currentSearchHit)
+	assert.Equal(t, 4, ordinal)
+	assert.Equal(t, 10, total)
+	assert.Assert(t, done)
+}
+
+func TestSearchHitCountWithoutCurrentHit(t *testing.T) {
+	lines := numberedLines("miss ", 1, 30)
+	for i := 3; i <= 30; i += 3 {
+		lines[i-1] = fmt.Sprintf("hit %d", i)
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	assert.Equal(t, "10 matches", awaitSearchHitCountText(t, pager))
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "match 2 of 10", awaitSearchHitCountText(t, pager))
+
+	// Scrolling away means we're no longer at any particular hit
+	pager.handleScrolledDown()
+	assert.Equal(t, "10 matches", awaitSearchHitCountText(t, pager))
+}
+
+func TestSearchHitCountNoMatches(t *testing.T) {
+	lines := numberedLines("miss ", 1, 30)
+	for i := 3; i <= 30; i += 3 {
+		lines[i-1] = fmt.Sprintf("hit %d", i)
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+	pager.searchString = "xyzzy"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	assert.Equal(t, "no matches", awaitSearchHitCountText(t, pager))
+}
+
+func TestSearchHitCountRestartsOnNewSearch(t *testing.T) {
+	lines := numberedLines("miss ", 1, 30)
+	for i := 3; i <= 30; i += 3 {
+		lines[i-1] = fmt.Sprintf("hit %d", i)
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	assert.Equal(t, "10 matches", awaitSearchHitCountText(t, pager))
+
+	pager.searchString = "hit 1"
+	pager.searchPattern = toPattern(pager.searchString)
+	assert.Equal(t, "3 matches", awaitSearchHitCountText(t, pager))
+}
+
+func TestSearchHitCountNoSearch(t *testing.T) {
+	lines := numberedLines("miss ", 1, 30)
+	for i := 3; i <= 30; i += 3 {
+		lines[i-1] = fmt.Sprintf("hit %d", i)
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+
+	assert.Equal(t, "", pager.searchHitCountText())
+	assert.Assert(t, pager.getSearchHitCounter() == nil)
+}
@@ -154,3 +154,53 @@ func _findFirstHit(reader reader.Reader, startPosition linemetadata.Index, patte
 		}
 	}
 }
+
+// How many lines FindAllHits() asks the reader for at a time
+const findAllHitsChunkSize = 10_000
+
+// A line with one or more search hits, see FindAllHits()
+type LineHits struct {
+	Index linemetadata.Index
+
+	// Start and end byte offsets of each hit into the line's plain text, like
+	// from regexp.FindAllStringIndex()
+	Offsets [][2]int
+}
+
+// Find all lines with hits for pattern, in input order. Just like FindFirstHit(),
+// this looks at the lines without formatting.
+//
+// Unlike FindFirstHit(), this doesn't care where the user is, so it's useful
+// for counting hits or for showing all of them.
+func FindAllHits(reader reader.Reader, pattern regexp.Regexp) []LineHits {
+	hits := []LineHits{}
+	nextIndex := 0
+	for {
+		// Near the end of the input we can get lines before nextIndex back,
+		// those we have already looked at.
+		firstIndex := nextIndex
+		lines := reader.GetLines(linemetadata.IndexFromZeroBased(firstIndex), findAllHitsChunkSize)
+		for _, line := range lines.Lines {
+			if line.Index.Index() < firstIndex {
+				continue
+			}
+			nextIndex = line.Index.Index() + 1
+
+			byteIndices := pattern.FindAllStringIndex(line.Plain(), -1)
+			if byteIndices == nil {
+				continue
+			}
+
+			offsets := make([][2]int, 0, len(byteIndices))
+			for _, byteIndex := range byteIndices {
+				offsets = append(offsets, [2]int{byteIndex[0], byteIndex[1]})
+			}
+			hits = append(hits, LineHits{Index: line.Index, Offsets: offsets})
+		}
+
+		if nextIndex == firstIndex {
+			// No new lines, we're done
+			return hits
+		}
+	}
+}
@@ -57,7 +57,7 @@ func TestFindFirstHitNoMatchBackwards(t *testing.T) {
 func rowToString(row []twin.StyledRune) string {
 	rowString := ""
 	for _, cell := range row {
-		rowString += string(cell.Rune)
+		rowString += string(cell.Rune) + cell.Combining()
 	}
 
 	return strings.TrimRight(rowString, " ")
@@ -166,3 +166,16 @@ func BenchmarkHighlightedWarmSearch(b *testing.B) {
 func BenchmarkPlainTextWarmSearch(b *testing.B) {
 	benchmarkSearch(b, false, true)
 }
+
+// Offsets are into the plain text, with formatting removed
+func TestFindAllHitsOffsets(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "x\x1b[1mab\x1b[0mab\nnothing\nab")
+	assert.NilError(t, reader.Wait())
+
+	hits := FindAllHits(reader, *toPattern("ab"))
+	assert.Equal(t, 2, len(hits))
+	assert.Equal(t, 0, hits[0].Index.Index())
+	assert.DeepEqual(t, [][2]int{{1, 3}, {3, 5}}, hits[0].Offsets)
+	assert.Equal(t, 2, hits[1].Index.Index())
+	assert.DeepEqual(t, [][2]int{{0, 2}}, hits[1].Offsets)
+}
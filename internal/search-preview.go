@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/twin"
+)
+
+// How many lines to show above and below the search hit in the search preview
+const searchPreviewContextLines = 2
+
+// The search hit to preview. That's the one we last jumped to, or the first one
+// on screen if we didn't have to jump. Nil if there are no hits.
+func (p *Pager) searchPreviewHit() *linemetadata.Index {
+	if p.currentSearchHit != nil {
+		return p.currentSearchHit
+	}
+
+	for _, row := range p.renderLines().lines {
+		for _, cell := range row.cells {
+			if cell.StartsSearchHit {
+				return &row.inputLineIndex
+			}
+		}
+	}
+
+	return nil
+}
+
+// The lines to show in the search preview, with the index of the hit line in
+// the returned slice. Returns nil if there is nothing to preview.
+func (p *Pager) searchPreviewLines() ([]string, int) {
+	hitIndex := p.searchPreviewHit()
+	if hitIndex == nil {
+		return nil, 0
+	}
+
+	hit := hitIndex.Index()
+	first := max(0, hit-searchPreviewContextLines)
+	last := min(p.Reader().GetLineCount()-1, hit+searchPreviewContextLines)
+
+	lines := []string{}
+	for index := first; index <= last; index++ {
+		line := p.Reader().GetLine(linemetadata.IndexFromZeroBased(index))
+		if line == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", line.Number.Format(), line.Plain()))
+	}
+
+	return lines, hit - first
+}
+
+// Show the current search hit with some context right above the search
+// prompt, so that the user can see what they are about to jump to.
+func (p *Pager) drawSearchPreview() {
+	lines, hitRow := p.searchPreviewLines()
+	if len(lines) == 0 {
+		return
+	}
+
+	width, height := p.screen.Size()
+
+	// Leave room for the search prompt, and drop lines from the top if we
+	// don't have room for all of them
+	rowCount := min(len(lines), height-1)
+	skip := len(lines) - rowCount
+	for row := range rowCount {
+		style := twin.StyleDefault.WithAttr(twin.AttrDim)
+		if skip+row == hitRow {
+			style = twin.StyleDefault.WithAttr(twin.AttrReverse)
+		}
+
+		screenRow := height - 1 - rowCount + row
+		pos := 0
+		for _, char := range lines[skip+row] {
+			if pos >= width {
+				break
+			}
+			pos += p.screen.SetCell(pos, screenRow, twin.NewStyledRune(char, style))
+		}
+		for pos < width {
+			pos += p.screen.SetCell(pos, screenRow, twin.NewStyledRune(' ', style))
+		}
+	}
+}
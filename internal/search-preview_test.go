@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchPreview(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.searchHistory = &SearchHistory{}
+	pager.ShowLineNumbers = false
+	pager.SearchPreview = true
+
+	typeRunes(pager, "/line 10")
+	assert.Equal(t, "Search", modeName(pager))
+	assert.Equal(t, 9, pager.currentSearchHit.Index())
+
+	rows := screenRows(pager)
+	assert.DeepEqual(t, rows, []string{
+		"8: line 8",
+		"9: line 9",
+		"10: line 10",
+		"11: line 11",
+		"12: line 12",
+		"Search: line 10",
+	})
+
+	screen := pager.screen.(*twin.FakeScreen)
+	assert.Equal(t, screen.GetRow(2)[0].Style, twin.StyleDefault.WithAttr(twin.AttrReverse))
+	assert.Equal(t, screen.GetRow(1)[0].Style, twin.StyleDefault.WithAttr(twin.AttrDim))
+
+	// Submitting the search should remove the preview
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "line 8", screenRows(pager)[0])
+}
+
+func TestSearchPreviewAtTop(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.searchHistory = &SearchHistory{}
+	pager.ShowLineNumbers = false
+	pager.SearchPreview = true
+
+	typeRunes(pager, "/line 2")
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	rows := screenRows(pager)
+	assert.DeepEqual(t, rows[1:], []string{
+		"1: line 1",
+		"2: line 2",
+		"3: line 3",
+		"4: line 4",
+		"Search: line 2",
+	})
+	assert.Equal(t, pager.screen.(*twin.FakeScreen).GetRow(2)[0].Style, twin.StyleDefault.WithAttr(twin.AttrReverse))
+}
+
+func TestSearchPreviewToggle(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.searchHistory = &SearchHistory{}
+	pager.ShowLineNumbers = false
+
+	typeRunes(pager, "/line 10")
+	assert.Equal(t, "line 8", screenRows(pager)[0])
+
+	typeRunes(pager, "\x10") // CTRL-p
+	assert.Assert(t, pager.SearchPreview)
+	assert.Equal(t, "8: line 8", screenRows(pager)[0])
+
+	typeRunes(pager, "\x10") // CTRL-p
+	assert.Assert(t, !pager.SearchPreview)
+	assert.Equal(t, "line 8", screenRows(pager)[0])
+}
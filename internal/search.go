@@ -54,10 +54,25 @@ func (p *Pager) scrollToSearchHits() {
 	if !p.searchHitIsVisible() {
 		p.scrollRightToSearchHits()
 	}
-	p.centerSearchHitsVertically()
+	p.placeSearchHitVertically(*firstHitIndex)
 }
 
-// Scroll to the next search hit, when the user presses 'n'.
+// Like 'n' in less, search again in the direction of the last search. If
+// reversed is set, go the other way like 'N' does.
+func (p *Pager) repeatSearch(reversed bool) {
+	direction := p.searchDirection
+	if reversed {
+		direction = !direction
+	}
+
+	if direction == SearchDirectionBackward {
+		p.scrollToPreviousSearchHit()
+	} else {
+		p.scrollToNextSearchHit()
+	}
+}
+
+// Scroll to the next search hit, when the user presses 'n' after a forward search.
 func (p *Pager) scrollToNextSearchHit() {
 	if p.searchPattern == nil {
 		// Nothing to search for, never mind
@@ -75,11 +90,12 @@ func (p *Pager) scrollToNextSearchHit() {
 	}
 
 	if p.isViewing() && p.isScrolledToEnd() {
-		p.mode = PagerModeNotFound{pager: p}
+		p.searchReachedEnd(SearchDirectionForward)
 		return
 	}
 
 	var firstSearchIndex linemetadata.Index
+	wrapped := false
 
 	switch {
 	case p.isViewing():
@@ -87,20 +103,41 @@ func (p *Pager) scrollToNextSearchHit() {
 		position := p.getLastVisiblePosition().NextLine(1)
 		firstSearchIndex = *position.lineIndex(p)
 
+		if scrollOff := p.effectiveScrollOff(); scrollOff > 0 {
+			// Hits in the bottom ScrollOff rows are visible, but without
+			// enough context below them. Include those in the search.
+			rendered := p.renderLines().lines
+			firstSearchIndex = rendered[max(0, len(rendered)-scrollOff)].inputLineIndex
+		}
+
 	case p.isNotFound():
+		if p.SearchWrap == SearchWrapNever {
+			p.setNotFound()
+			return
+		}
+
 		// Restart searching from the top
 		p.mode = PagerModeViewing{pager: p}
 		firstSearchIndex = linemetadata.Index{}
+		wrapped = true
 
 	default:
 		panic(fmt.Sprint("Unknown search mode when finding next: ", p.mode))
 	}
 
 	firstHitIndex := FindFirstHit(p.Reader(), *p.searchPattern, firstSearchIndex, nil, SearchDirectionForward)
+	if firstHitIndex == nil && !wrapped {
+		p.searchReachedEnd(SearchDirectionForward)
+		return
+	}
 	if firstHitIndex == nil {
-		p.mode = PagerModeNotFound{pager: p}
+		p.setNotFound()
 		return
 	}
+	if wrapped {
+		p.giveSearchFeedback()
+	}
+	p.rememberPosition()
 	p.scrollPosition = NewScrollPositionFromIndex(*firstHitIndex, "scrollToNextSearchHit")
 
 	// Don't let any search hit scroll out of sight
@@ -111,7 +148,7 @@ func (p *Pager) scrollToNextSearchHit() {
 	if !p.searchHitIsVisible() {
 		p.scrollRightToSearchHits()
 	}
-	p.centerSearchHitsVertically()
+	p.placeSearchHitVertically(*firstHitIndex)
 }
 
 // Scroll backwards to the previous search hit, while the user is typing the
@@ -170,10 +207,11 @@ func (p *Pager) scrollToSearchHitsBackwards() {
 	if !p.searchHitIsVisible() {
 		p.scrollLeftToSearchHits()
 	}
-	p.centerSearchHitsVertically()
+	p.placeSearchHitVertically(*firstHitIndex)
 }
 
-// Scroll backwards to the previous search hit, when the user presses 'N'.
+// Scroll backwards to the previous search hit, when the user presses 'p', or
+// 'N' after a forward search.
 func (p *Pager) scrollToPreviousSearchHit() {
 	if p.searchPattern == nil {
 		// Nothing to search for, never mind
@@ -191,12 +229,13 @@ func (p *Pager) scrollToPreviousSearchHit() {
 	}
 
 	var firstSearchIndex linemetadata.Index
+	wrapped := false
 
 	switch {
 	case p.isViewing():
 		if p.scrollPosition.lineIndex(p).Index() == 0 {
 			// Already at the top, can't go further up
-			p.mode = PagerModeNotFound{pager: p}
+			p.searchReachedEnd(SearchDirectionBackward)
 			return
 		}
 
@@ -204,20 +243,41 @@ func (p *Pager) scrollToPreviousSearchHit() {
 		position := p.scrollPosition.PreviousLine(1)
 		firstSearchIndex = *position.lineIndex(p)
 
+		if scrollOff := p.effectiveScrollOff(); scrollOff > 0 {
+			// Hits in the top ScrollOff rows are visible, but without enough
+			// context above them. Include those in the search.
+			rendered := p.renderLines().lines
+			firstSearchIndex = rendered[min(scrollOff, len(rendered))-1].inputLineIndex
+		}
+
 	case p.isNotFound():
+		if p.SearchWrap == SearchWrapNever {
+			p.setNotFound()
+			return
+		}
+
 		// Restart searching from the bottom
 		p.mode = PagerModeViewing{pager: p}
 		firstSearchIndex = *linemetadata.IndexFromLength(p.Reader().GetLineCount())
+		wrapped = true
 
 	default:
 		panic(fmt.Sprint("Unknown search mode when finding previous: ", p.mode))
 	}
 
 	hitIndex := FindFirstHit(p.Reader(), *p.searchPattern, firstSearchIndex, nil, SearchDirectionBackward)
+	if hitIndex == nil && !wrapped {
+		p.searchReachedEnd(SearchDirectionBackward)
+		return
+	}
 	if hitIndex == nil {
-		p.mode = PagerModeNotFound{pager: p}
+		p.setNotFound()
 		return
 	}
+	if wrapped {
+		p.giveSearchFeedback()
+	}
+	p.rememberPosition()
 	p.scrollPosition = *scrollPositionFromIndex("scrollToPreviousSearchHit", *hitIndex)
 
 	// Don't let any search hit scroll out of sight
@@ -228,7 +288,7 @@ func (p *Pager) scrollToPreviousSearchHit() {
 	if !p.searchHitIsVisible() {
 		p.scrollLeftToSearchHits()
 	}
-	p.centerSearchHitsVertically()
+	p.placeSearchHitVertically(*hitIndex)
 }
 
 // Return true if any search hit is currently visible on screen.
@@ -251,6 +311,28 @@ func (p *Pager) searchHitIsVisible() bool {
 	return false
 }
 
+// After jumping to the search hit on line hitIndex, move it to where the user
+// wants it on screen. By default the hits are centered vertically, set
+// SearchJumpOffset to put the hit at a fixed screen row instead.
+//
+// Either way, ScrollOff rows of context are kept around the hit if possible.
+func (p *Pager) placeSearchHitVertically(hitIndex linemetadata.Index) {
+	p.currentSearchHit = &hitIndex
+
+	if p.SearchJumpOffset == nil {
+		p.centerSearchHitsVertically()
+		p.applyScrollOff(hitIndex)
+		return
+	}
+
+	// Never push the hit off the bottom of the screen. Near the top and bottom
+	// of the input, canonicalization will clamp the position for us.
+	offset := max(0, min(*p.SearchJumpOffset, p.visibleHeight()-1))
+
+	p.scrollPosition = NewScrollPositionFromIndex(hitIndex, "placeSearchHitVertically").PreviousLine(offset)
+	p.applyScrollOff(hitIndex)
+}
+
 func (p *Pager) centerSearchHitsVertically() {
 	if p.WrapLongLines {
 		// FIXME: Centering is not supported when wrapping, future improvement!
@@ -322,7 +404,7 @@ func (p *Pager) scrollMaxRight() {
 		}
 	}
 
-	screenWidth, _ := p.screen.Size()
+	screenWidth := p.contentWidth()
 
 	availableWidth := screenWidth - rendered.numberPrefixWidth
 	if widestLineWidth <= availableWidth {
@@ -331,7 +413,7 @@ func (p *Pager) scrollMaxRight() {
 	}
 
 	p.showLineNumbers = false
-	availableWidth += rendered.numberPrefixWidth
+	availableWidth += rendered.numberPrefixWidth - p.timeDeltasWidth()
 	if widestLineWidth <= availableWidth {
 		// All lines fit on screen with line numbers off, this means we're now
 		// max scrolled right
@@ -356,7 +438,7 @@ func (p *Pager) scrollRightToSearchHits() bool {
 	// Check how far right we can scroll at most. Factors involved:
 	// - Screen width
 	// - Length of longest visible line
-	screenWidth, _ := p.screen.Size()
+	screenWidth := p.contentWidth()
 
 	widestLineWidth := 0 // In screen cells, some runes are double-width
 	rendered := p.renderLines()
@@ -451,7 +533,7 @@ func (p *Pager) scrollLeftToSearchHits() bool {
 	restoreLeftColumn := p.leftColumnZeroBased
 	restoreShowLineNumbers := p.showLineNumbers
 
-	screenWidth, _ := p.screen.Size()
+	screenWidth := p.contentWidth()
 
 	// If we go max left, which column will be the rightmost visible one?
 	var fullLeftRightmostVisibleColumn int
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/walles/moor/v2/internal/linemetadata"
 	"github.com/walles/moor/v2/internal/reader"
 	"github.com/walles/moor/v2/twin"
 	"gotest.tools/v3/assert"
@@ -88,6 +89,26 @@ func modeName(pager *Pager) string {
 		return "Search"
 	case *PagerModeGotoLine:
 		return "GotoLine"
+	case *PagerModeFilter:
+		return "Filter"
+	case *PagerModeInfo:
+		return "Info"
+	case PagerModeReposition:
+		return "Reposition"
+	case *PagerModeHighlight:
+		return "Highlight"
+	case *PagerModePipe:
+		return "Pipe"
+	case *PagerModePiping:
+		return "Piping"
+	case PagerModeConfirmQuit:
+		return "ConfirmQuit"
+	case *PagerModeMarkList:
+		return "MarkList"
+	case PagerModeConfirmWrap:
+		return "ConfirmWrap"
+	case PagerModeOpenUrl:
+		return "OpenUrl"
 	default:
 		panic("Unknown pager mode")
 	}
@@ -156,6 +177,39 @@ func TestScrollToNextSearchHit_WrapAfterNotFound(t *testing.T) {
 	assert.Assert(t, pager.lineIndex().IsZero())
 }
 
+func TestScrollToNextSearchHit_NoWrap(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.SearchWrap = SearchWrapNever
+	pager.scrollToEnd()
+
+	pager.searchString = "a"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+
+	// Searching again should not wrap
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
+
+func TestScrollToPreviousSearchHit_NoWrap(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.SearchWrap = SearchWrapNever
+
+	pager.searchString = "f"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+
+	// Searching again should not wrap
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+	assert.Assert(t, pager.lineIndex().IsZero())
+}
+
 func TestScrollToNextSearchHit_WrapAfterFound(t *testing.T) {
 	// Create a pager scrolled to the last line
 	pager := createThreeLinesPager(t)
@@ -176,6 +230,98 @@ func TestScrollToNextSearchHit_WrapAfterFound(t *testing.T) {
 	assert.Equal(t, 4, pager.lineIndex().Index())
 }
 
+func TestScrollToNextSearchHit_JumpOffset(t *testing.T) {
+	// Three screen lines minus the status bar leaves two visible lines
+	pager := createThreeLinesPager(t)
+	offset := 1
+	pager.SearchJumpOffset = &offset
+
+	// Search for "d", it's on the fourth line (ref createThreeLinesPager())
+	pager.searchString = "d"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// "d" should now be on the second screen row
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+func TestScrollToNextSearchHit_JumpOffsetTooLarge(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	offset := 5
+	pager.SearchJumpOffset = &offset
+
+	pager.searchString = "d"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.scrollToNextSearchHit()
+
+	// The offset should be clamped to the last visible row
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+func TestScrollToNextSearchHit_JumpOffsetClampedAtTop(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.scrollToEnd()
+	offset := 1
+	pager.SearchJumpOffset = &offset
+
+	// Search for "a", it's on the first line (ref createThreeLinesPager())
+	pager.searchString = "a"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	// Go NotFound, then wrap to the top
+	pager.scrollToNextSearchHit()
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// Can't put "a" on the second row, since there's nothing above it
+	assert.Assert(t, pager.lineIndex().IsZero())
+}
+
+func TestScrollToNextSearchHit_JumpOffsetClampedAtBottom(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	offset := 0
+	pager.SearchJumpOffset = &offset
+
+	// Search for "e", it's on the fifth line (ref createThreeLinesPager())
+	pager.searchString = "e"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.scrollToNextSearchHit()
+
+	// "e" is on the first row, followed by "f"
+	assert.Equal(t, 4, pager.lineIndex().Index())
+
+	// Search for "f", it's on the last line
+	pager.searchString = "f"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.mode = PagerModeNotFound{pager: pager}
+
+	pager.scrollToNextSearchHit()
+
+	// Can't put "f" on the first row, since we can't scroll past the end
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
+
+func TestScrollToPreviousSearchHit_JumpOffset(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.scrollToEnd()
+	offset := 1
+	pager.SearchJumpOffset = &offset
+
+	// Search for "c", it's on the third line (ref createThreeLinesPager())
+	pager.searchString = "c"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// "c" should now be on the second screen row
+	assert.Equal(t, 1, pager.lineIndex().Index())
+}
+
 // Ref: https://github.com/walles/moor/issues/152
 func Test152(t *testing.T) {
 	// Show a pager on a five lines terminal
@@ -354,3 +500,510 @@ func TestScrollRightToSearchHits_OnlyStartOfHitTriggers(t *testing.T) {
 
 	assert.Assert(t, !pager.scrollRightToSearchHits(), "No more search hit starts to the right, should not scroll")
 }
+
+// Count the lines in the pager's reader matching the current search pattern
+func countSearchHitLines(pager *Pager) int {
+	count := 0
+	lines := pager.Reader().GetLines(linemetadata.Index{}, pager.Reader().GetLineCount())
+	for _, line := range lines.Lines {
+		if pager.searchPattern.MatchString(line.Plain()) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSearchToggleCaseSensitivity(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "träff\nTRÄFF\nmiss")
+	assert.NilError(t, reader.Wait())
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.searchHistory = &SearchHistory{}
+
+	search := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = search
+	for _, char := range "träff" {
+		search.onRune(char)
+	}
+
+	// Smart case, lower case search string is case insensitive
+	assert.Equal(t, 2, countSearchHitLines(pager))
+
+	search.onRune('\x14') // CTRL-t
+	assert.Equal(t, 1, countSearchHitLines(pager))
+
+	search.inputBox.setText("TRÄFF")
+	assert.Equal(t, 1, countSearchHitLines(pager), "Manual toggle should override smart case")
+
+	search.onRune('\x14') // CTRL-t
+	assert.Equal(t, 2, countSearchHitLines(pager))
+
+	// Dismissing the search should bring back smart case for the next one
+	search.onKey(twin.KeyEnter)
+	assert.Assert(t, pager.searchCaseSensitive == nil)
+	assert.Equal(t, 2, countSearchHitLines(pager))
+}
+
+func TestSearchCaseIndicator(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "träff")
+	pager := NewPager(reader)
+	screen := twin.NewFakeScreen(80, 5)
+	pager.screen = screen
+	pager.searchHistory = &SearchHistory{}
+
+	search := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = search
+	search.onRune('x')
+
+	search.drawFooter("", "")
+	assert.Assert(t, strings.HasPrefix(rowToString(screen.GetRow(4)), "Search: x"))
+
+	search.onRune('\x14') // CTRL-t
+	search.drawFooter("", "")
+	assert.Assert(t, strings.HasPrefix(rowToString(screen.GetRow(4)), "Search [case]: x"))
+
+	search.onRune('\x14') // CTRL-t
+	search.drawFooter("", "")
+	assert.Assert(t, strings.HasPrefix(rowToString(screen.GetRow(4)), "Search [nocase]: x"))
+}
+
+func TestSearchToggleLiteral(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "a.b\naxb\n(paren")
+	assert.NilError(t, reader.Wait())
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(20, 5)
+	pager.searchHistory = &SearchHistory{}
+
+	search := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = search
+	search.inputBox.setText("a.b")
+
+	// Regexp by default, matching both lines
+	assert.Equal(t, 2, countSearchHitLines(pager))
+
+	search.onRune('\x12') // CTRL-r
+	assert.Assert(t, pager.searchLiteral)
+	assert.Equal(t, 1, countSearchHitLines(pager))
+	assert.Assert(t, pager.searchPattern.MatchString("a.b"))
+	assert.Assert(t, !pager.searchPattern.MatchString("axb"))
+
+	// Invalid regexps are just searched for
+	search.inputBox.setText("(")
+	assert.Equal(t, 1, countSearchHitLines(pager))
+
+	search.onRune('\x12') // CTRL-r
+	assert.Assert(t, !pager.searchLiteral)
+	search.inputBox.setText("a.b")
+	assert.Equal(t, 2, countSearchHitLines(pager))
+}
+
+func TestSearchIncompleteRegexp(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "a\nc\nd\nb\ne\nf\nfoo(bar)")
+	assert.NilError(t, reader.Wait())
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.searchHistory = &SearchHistory{}
+
+	typeRunes(pager, "/b")
+	assert.Equal(t, "b", pager.searchString)
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	// Not valid regexps, the hits for "b" should stay
+	typeRunes(pager, "(")
+	assert.Equal(t, "b", pager.searchString)
+	assert.Equal(t, 3, pager.lineIndex().Index())
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "Search [bad pattern]: b("), statusBarRow(pager))
+
+	typeRunes(pager, "ar")
+	assert.Equal(t, "b", pager.searchString)
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	// Valid again, and matching "bar" on the last line. That's at the end, so
+	// the last line will be at the bottom of the screen.
+	typeRunes(pager, ")")
+	assert.Equal(t, "b(ar)", pager.searchString)
+	assert.Equal(t, 5, pager.lineIndex().Index())
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "Search: b(ar)"), statusBarRow(pager))
+}
+
+func TestSearchSubmitInvalidRegexp(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "a\nc\nd\nb\ne\nf\nfoo(bar)")
+	assert.NilError(t, reader.Wait())
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	pager.searchHistory = &SearchHistory{}
+
+	typeRunes(pager, "/o(b")
+	assert.Equal(t, "o", pager.searchString)
+
+	// Submitting an invalid regexp searches for it verbatim
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "o(b", pager.searchString)
+	assert.Assert(t, pager.searchPattern.MatchString("foo(bar)"))
+	assert.Equal(t, 5, pager.lineIndex().Index(), "Last line should be at the bottom of the screen")
+}
+
+func TestSearchToggleWholeWords(t *testing.T) {
+	reader := reader.NewFromTextForTesting("", "err\nerror\nterror\nan err.or")
+	assert.NilError(t, reader.Wait())
+	pager := NewPager(reader)
+	pager.screen = twin.NewFakeScreen(20, 6)
+	pager.searchHistory = &SearchHistory{}
+
+	search := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = search
+	search.inputBox.setText("err")
+	assert.Equal(t, 4, countSearchHitLines(pager))
+
+	search.onRune('\x17') // CTRL-w
+	assert.Assert(t, pager.searchWholeWords)
+	assert.Assert(t, pager.searchPattern.MatchString("err"))
+	assert.Assert(t, !pager.searchPattern.MatchString("error"))
+	assert.Assert(t, !pager.searchPattern.MatchString("terror"))
+	assert.Equal(t, 2, countSearchHitLines(pager))
+
+	search.drawFooter("", "")
+	assert.Equal(t, "Search [words]: err", rowToString(pager.screen.(*twin.FakeScreen).GetRow(5)))
+
+	// Regexp alternatives should all be whole words
+	search.inputBox.setText("err|an")
+	assert.Assert(t, !pager.searchPattern.MatchString("error"))
+	assert.Assert(t, pager.searchPattern.MatchString("an"))
+
+	// Literal mode should quote first, then add the boundaries
+	search.onRune('\x12') // CTRL-r
+	search.inputBox.setText("err.or")
+	assert.Assert(t, pager.searchPattern.MatchString("an err.or"))
+	assert.Assert(t, !pager.searchPattern.MatchString("an err.ors"))
+	assert.Assert(t, !pager.searchPattern.MatchString("errxor"))
+}
+
+func TestScrollToPreviousSearchHit_WrapAfterNotFound(t *testing.T) {
+	// Create a pager scrolled to the first line
+	pager := createThreeLinesPager(t)
+
+	// Search for "f", it's on the last line (ref createThreeLinesPager())
+	pager.searchString = "f"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	// Scroll to the previous search hit, this should take us into _NotFound
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+
+	// Scroll to the previous search hit, this should wrap the search and take
+	// us to the bottom
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestScrollToPreviousSearchHit_WrapAfterFound(t *testing.T) {
+	// Create a pager scrolled to the first line
+	pager := createThreeLinesPager(t)
+
+	// Search for "a", it's on the first line (ref createThreeLinesPager())
+	pager.searchString = "a"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	// Scroll to the previous search hit, this should take us into _NotFound
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+
+	// Scroll to the previous search hit, this should wrap the search and take
+	// us back to the top again
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Assert(t, pager.lineIndex().IsZero())
+}
+
+func TestBackwardsSearchFromTopWraps(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.searchHistory = &SearchHistory{}
+
+	// Start a backwards search from the top
+	pager.mode.onRune('?')
+	assert.Equal(t, "Search", modeName(pager))
+	assert.Equal(t, SearchDirectionBackward, pager.mode.(*PagerModeSearch).direction)
+
+	// "e" is on the next to last line, so we should wrap there. With two
+	// lines visible, "d" should be at the top of the screen.
+	pager.mode.onRune('e')
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	// Leaving search mode should keep us at the hit
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 3, pager.lineIndex().Index())
+}
+
+func TestScrollToNextSearchHit_ScrollOff(t *testing.T) {
+	lines := numberedLines("line ", 0, 19)
+	lines[10] = "hit"
+	pager := createLinesPager(t, 20, 8, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.ScrollOff = 2
+	offset := 100
+	pager.SearchJumpOffset = &offset
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// The hit should be on the fifth row, leaving two rows below it
+	assert.Equal(t, 10-4, pager.lineIndex().Index())
+}
+
+func TestScrollToNextSearchHit_ScrollOffHitInBottomMargin(t *testing.T) {
+	// The hit is on the last visible row
+	lines := numberedLines("line ", 0, 19)
+	lines[6] = "hit"
+	pager := createLinesPager(t, 20, 8, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.ScrollOff = 2
+	offset := 100
+	pager.SearchJumpOffset = &offset
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// The hit should have been scrolled up to get two rows of context below it
+	assert.Equal(t, 6-4, pager.lineIndex().Index())
+}
+
+func TestScrollToNextSearchHit_ScrollOffAtEnd(t *testing.T) {
+	lines := numberedLines("line ", 0, 19)
+	lines[19] = "hit"
+	pager := createLinesPager(t, 20, 8, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.ScrollOff = 2
+	offset := 0
+	pager.SearchJumpOffset = &offset
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// The hit is on the last line, no context to show below it
+	assert.Equal(t, 20-7, pager.lineIndex().Index())
+	assert.Assert(t, pager.isScrolledToEnd())
+}
+
+func TestScrollToNextSearchHit_ScrollOffCentered(t *testing.T) {
+	lines := numberedLines("line ", 0, 19)
+	lines[12] = "hit"
+	pager := createLinesPager(t, 20, 8, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.ScrollOff = 100
+
+	pager.scrollToNextSearchHit()
+
+	// Centering already gives the hit three rows above and below
+	assert.Equal(t, 12-3, pager.lineIndex().Index())
+}
+
+func TestScrollToPreviousSearchHit_ScrollOff(t *testing.T) {
+	lines := numberedLines("line ", 0, 19)
+	lines[10] = "hit"
+	pager := createLinesPager(t, 20, 8, lines...)
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.ScrollOff = 2
+	pager.scrollToEnd()
+	offset := 0
+	pager.SearchJumpOffset = &offset
+
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// The hit should be on the third row, leaving two rows above it
+	assert.Equal(t, 10-2, pager.lineIndex().Index())
+}
+
+func TestGoToLine_ScrollOff(t *testing.T) {
+	pager := createLinesPager(t, 20, 8, numberedLines("line ", 0, 19)...)
+	pager.ScrollOff = 2
+
+	pager.goToLine(linemetadata.IndexFromZeroBased(10))
+	assert.Equal(t, 10-2, pager.lineIndex().Index())
+
+	// No context available above the first line
+	pager.goToLine(linemetadata.IndexFromZeroBased(0))
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+// Rapid typing in a large input should result in only one search, for the
+// final text
+func TestSearchDebounce(t *testing.T) {
+	defer func(saved int) { searchDebounceMinLines = saved }(searchDebounceMinLines)
+	searchDebounceMinLines = 0
+
+	reader := reader.NewFromTextForTesting("", "a\nab\nabc\nabcd\nabcde\nabcdef\n")
+	screen := twin.NewFakeScreen(20, 5)
+	pager := NewPager(reader)
+	pager.screen = screen
+
+	searchMode := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = searchMode
+	searchMode.inputBox.setText("abc")
+	staleGeneration := searchMode.searchGeneration
+	searchMode.inputBox.setText("abcd")
+	searchMode.inputBox.setText("abcde")
+
+	// Nothing searched yet
+	assert.Equal(t, "", pager.searchString)
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	// Timers for replaced searches firing anyway should have no effect
+	searchMode.onSearchDebounced(staleGeneration)
+	assert.Equal(t, "", pager.searchString)
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	searchMode.onSearchDebounced(searchMode.searchGeneration)
+	assert.Equal(t, "abcde", pager.searchString)
+	assert.Equal(t, 2, pager.lineIndex().Index())
+	assert.Assert(t, searchMode.pendingSearch == nil)
+
+	// Nothing left to do
+	searchMode.onSearchDebounced(searchMode.searchGeneration)
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+// Pressing Enter should search right away, without waiting for the debounce
+func TestSearchDebounceEnter(t *testing.T) {
+	defer func(saved int) { searchDebounceMinLines = saved }(searchDebounceMinLines)
+	searchDebounceMinLines = 0
+
+	reader := reader.NewFromTextForTesting("", "a\nab\nabc\nabcd\nabcde\nabcdef\n")
+	screen := twin.NewFakeScreen(20, 5)
+	pager := NewPager(reader)
+	pager.screen = screen
+
+	searchMode := NewPagerModeSearch(pager, SearchDirectionForward, pager.scrollPosition)
+	pager.mode = searchMode
+	searchMode.inputBox.setText("abcd")
+	searchMode.inputBox.setText("abcde")
+	assert.Equal(t, "", pager.searchString)
+
+	searchMode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "abcde", pager.searchString)
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+// Search hits are found in the input lines, not in the wrapped screen rows, so
+// a hit can span a wrap point
+func TestScrollToNextSearchHit_AcrossWrap(t *testing.T) {
+	defer func(saved *twin.Color) { searchHitLineBackground = saved }(searchHitLineBackground)
+	searchHitLineBackground = nil
+
+	pager := createLinesPager(t, 5, 4, "1", "2", "3", "4", "5", "abcdefghij", "6")
+	pager.WrapLongLines = true
+	pager.ShowLineNumbers = false
+
+	pager.searchString = "def"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	rows := screenRows(pager)
+	fakeScreen := pager.screen.(*twin.FakeScreen)
+	for row := range 3 {
+		if rows[row] != "abcde" {
+			continue
+		}
+
+		// Found the first half of the wrapped line, the hit should be
+		// highlighted on both rows
+		assert.Equal(t, rows[row+1], "fghij")
+		firstRow := fakeScreen.GetRow(row)
+		secondRow := fakeScreen.GetRow(row + 1)
+		assert.Assert(t, firstRow[2].Style != searchHitStyle)
+		assert.Equal(t, firstRow[3].Style, searchHitStyle)
+		assert.Equal(t, firstRow[4].Style, searchHitStyle)
+		assert.Equal(t, secondRow[0].Style, searchHitStyle)
+		assert.Assert(t, secondRow[1].Style != searchHitStyle)
+		return
+	}
+
+	t.Fatalf("Wrapped line not found on screen: %#v", rows)
+}
+
+// After a backward search, 'n' should keep going up and 'N' should go down
+func TestRepeatSearchBackward(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.searchHistory = &SearchHistory{}
+	pager.scrollToEnd()
+
+	typeRunes(pager, "?[a-d]")
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 2, pager.lineIndex().Index(), "d should be at the bottom of the screen")
+
+	pager.mode.onRune('n')
+	assert.Equal(t, 1, pager.lineIndex().Index())
+	pager.mode.onRune('n')
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	// Nothing more above, then wrap to the bottom
+	pager.mode.onRune('n')
+	assert.Equal(t, "NotFound", modeName(pager))
+	pager.mode.onRune('n')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 3, pager.lineIndex().Index())
+
+	// Nothing more below, then wrap to the top
+	pager.mode.onRune('N')
+	assert.Equal(t, "NotFound", modeName(pager))
+	pager.mode.onRune('N')
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 0, pager.lineIndex().Index())
+
+	pager.mode.onRune('N')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+// After a forward search, 'n' should keep going down and 'N' should go up
+func TestRepeatSearchForward(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.searchHistory = &SearchHistory{}
+
+	typeRunes(pager, "/[c-f]")
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	pager.mode.onRune('n')
+	assert.Equal(t, 4, pager.lineIndex().Index())
+
+	pager.mode.onRune('N')
+	assert.Equal(t, 3, pager.lineIndex().Index())
+	pager.mode.onRune('N')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+
+	// Leaving and re-entering viewing mode should not forget the direction
+	typeRunes(pager, "g")
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+	pager.mode.onRune('n')
+	assert.Equal(t, 4, pager.lineIndex().Index())
+}
+
+func TestFindAllHits(t *testing.T) {
+	pager := createThreeLinesPager(t)
+
+	hits := FindAllHits(pager.Reader(), *regexp.MustCompile("[a-c]|e"))
+	indices := []int{}
+	for _, hit := range hits {
+		indices = append(indices, hit.Index.Index())
+		assert.DeepEqual(t, [][2]int{{0, 1}}, hit.Offsets)
+	}
+	assert.DeepEqual(t, []int{0, 1, 2, 4}, indices)
+
+	// Where the user is doesn't matter
+	pager.scrollToEnd()
+	assert.Equal(t, 4, len(FindAllHits(pager.Reader(), *regexp.MustCompile("[a-c]|e"))))
+
+	assert.Equal(t, 0, len(FindAllHits(pager.Reader(), *regexp.MustCompile("x"))))
+}
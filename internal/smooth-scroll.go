@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"time"
+)
+
+// With SmoothScroll, page scrolls are done in this many steps, spread out over
+// smoothScrollDuration
+const smoothScrollFrames = 6
+
+const smoothScrollDuration = 90 * time.Millisecond
+
+// A page scroll in progress, see scrollScreenLines()
+type scrollAnimation struct {
+	// Where we'll end up
+	target scrollPosition
+
+	// The whole distance, negative for scrolling up
+	lines int
+
+	// Screen lines left to scroll, negative for scrolling up
+	remainingLines int
+
+	framesLeft int
+}
+
+// Time to take another step towards the animation target
+type eventScrollAnimationFrame struct {
+	animation *scrollAnimation
+}
+
+// Scroll this many screen lines, negative means up. With SmoothScroll, we get
+// there over a few frames rather than all at once.
+func (p *Pager) scrollScreenLines(lines int) {
+	// Start from where any previous animation was going
+	p.finishScrollAnimation()
+
+	if lines > 0 && p.handleAtEnd() {
+		return
+	}
+
+	target := p.scrollPosition.NextLine(lines)
+	if !p.SmoothScroll || max(lines, -lines) < smoothScrollFrames {
+		p.scrollPosition = target
+		p.handleScrolled(lines)
+		return
+	}
+
+	// Don't let following or search hits move us while we're animating
+	p.handleScrolledUp()
+
+	p.scrollAnimation = &scrollAnimation{
+		target:         target,
+		lines:          lines,
+		remainingLines: lines,
+		framesLeft:     smoothScrollFrames,
+	}
+
+	// Start moving right away, the user wants to see something happen
+	p.onScrollAnimationFrame(p.scrollAnimation)
+}
+
+func (p *Pager) handleScrolled(lines int) {
+	if lines < 0 {
+		p.handleScrolledUp()
+	} else {
+		p.handleScrolledDown()
+	}
+}
+
+func (p *Pager) onScrollAnimationFrame(animation *scrollAnimation) {
+	if animation != p.scrollAnimation {
+		// Finished or replaced since this frame was scheduled
+		return
+	}
+
+	step := animation.remainingLines / animation.framesLeft
+	animation.remainingLines -= step
+	animation.framesLeft--
+	if animation.framesLeft == 0 {
+		p.finishScrollAnimation()
+		return
+	}
+
+	p.scrollPosition = p.scrollPosition.NextLine(step)
+
+	events := p.screen.Events()
+	time.AfterFunc(smoothScrollDuration/smoothScrollFrames, func() {
+		select {
+		case events <- eventScrollAnimationFrame{animation: animation}:
+		default:
+			// Event queue full, the next user input will finish the
+			// animation
+		}
+	})
+}
+
+// Jump to where any ongoing scroll animation was going. Done before handling
+// user input, so that the input applies to the final position.
+func (p *Pager) finishScrollAnimation() {
+	animation := p.scrollAnimation
+	if animation == nil {
+		return
+	}
+
+	p.scrollAnimation = nil
+	p.scrollPosition = animation.target
+	p.handleScrolled(animation.lines)
+}
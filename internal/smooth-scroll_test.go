@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// Like the main loop does for key presses
+func pressRune(pager *Pager, char rune) {
+	pager.finishScrollAnimation()
+	pager.mode.onRune(char)
+}
+
+func runScrollAnimation(pager *Pager) {
+	for pager.scrollAnimation != nil {
+		pager.onScrollAnimationFrame(pager.scrollAnimation)
+	}
+}
+
+func TestSmoothScrollPageDown(t *testing.T) {
+	pager := createLinesPager(t, 20, 21, numberedLines("line ", 0, 99)...)
+	pager.SmoothScroll = true
+
+	pressRune(pager, 'f')
+	assert.Assert(t, pager.scrollAnimation != nil)
+
+	// Moving, but not there yet
+	assert.Assert(t, pager.lineIndex().Index() > 0)
+	assert.Assert(t, pager.lineIndex().Index() < 19)
+
+	runScrollAnimation(pager)
+	assert.Equal(t, 19, pager.lineIndex().Index())
+
+	pressRune(pager, 'b')
+	runScrollAnimation(pager)
+	assert.Equal(t, 0, pager.lineIndex().Index())
+}
+
+func TestSmoothScrollCancelled(t *testing.T) {
+	pager := createLinesPager(t, 20, 21, numberedLines("line ", 0, 99)...)
+	pager.SmoothScroll = true
+
+	pressRune(pager, 'f')
+	animation := pager.scrollAnimation
+	pager.onScrollAnimationFrame(animation)
+	assert.Assert(t, pager.lineIndex().Index() < 19)
+
+	// The next key press should start from the target of the first one
+	pressRune(pager, 'j')
+	assert.Assert(t, pager.scrollAnimation == nil)
+	assert.Equal(t, 20, pager.lineIndex().Index())
+
+	// Any frames still on their way should be ignored
+	pager.onScrollAnimationFrame(animation)
+	assert.Equal(t, 20, pager.lineIndex().Index())
+}
+
+func TestSmoothScrollReplaced(t *testing.T) {
+	pager := createLinesPager(t, 20, 21, numberedLines("line ", 0, 99)...)
+	pager.SmoothScroll = true
+
+	pressRune(pager, 'f')
+	pressRune(pager, 'f')
+	runScrollAnimation(pager)
+	assert.Equal(t, 38, pager.lineIndex().Index())
+}
+
+func TestSmoothScrollToEnd(t *testing.T) {
+	pager := createLinesPager(t, 20, 21, numberedLines("line ", 0, 99)...)
+	pager.SmoothScroll = true
+
+	// Scrolling past the end should stop at the end, just like without
+	// animation
+	pressRune(pager, '9')
+	pressRune(pager, 'f')
+	runScrollAnimation(pager)
+	assert.Equal(t, 80, pager.lineIndex().Index())
+}
+
+func TestSmoothScrollDisabled(t *testing.T) {
+	pager := createLinesPager(t, 20, 21, numberedLines("line ", 0, 99)...)
+
+	pressRune(pager, 'f')
+	assert.Assert(t, pager.scrollAnimation == nil)
+	assert.Equal(t, 19, pager.lineIndex().Index())
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+// Set up StartAtEnd and InitialSearch. Should be called before the first
+// redraw.
+func (p *Pager) applyStartupPosition() {
+	if p.StartAtEnd && p.TargetLine == nil {
+		// Keep scrolling to the end while the input is being read, then stop
+		// there. See handleReadingMaybeDone().
+		reallyHigh := linemetadata.IndexMax()
+		p.TargetLine = &reallyHigh
+		p.followUntilDone = true
+	}
+
+	if p.InitialSearch != "" {
+		p.searchString = p.InitialSearch
+		p.searchPattern = p.toSearchPattern(p.InitialSearch)
+		p.initialSearchFrom = &linemetadata.Index{}
+		p.continueInitialSearch()
+	}
+}
+
+// Look for the first InitialSearch hit in any lines we haven't searched yet.
+// If we reach the end of the input without finding anything, we stay at the
+// top and tell the user.
+func (p *Pager) continueInitialSearch() {
+	if p.initialSearchFrom == nil {
+		return
+	}
+
+	if p.searchPattern == nil {
+		// Not a valid search, never mind
+		p.initialSearchFrom = nil
+		return
+	}
+
+	p.readerLock.Lock()
+	r := p.readers[p.currentReader]
+	p.readerLock.Unlock()
+
+	// Check this before counting lines, otherwise we could miss lines coming
+	// in between the two checks
+	done := r.ReadingDone.Load()
+	lineCount := r.GetLineCount()
+
+	var hit *linemetadata.Index
+	if p.initialSearchFrom.Index() < lineCount {
+		hit = FindFirstHit(r, *p.searchPattern, *p.initialSearchFrom, nil, SearchDirectionForward)
+	}
+	if hit != nil {
+		// Like in scrollToSearchHits()
+		p.initialSearchFrom = nil
+		p.initialSearchFound = true
+		p.scrollPosition = NewScrollPositionFromIndex(*hit, "continueInitialSearch")
+		if !p.searchHitIsVisible() {
+			p.scrollRightToSearchHits()
+		}
+		p.placeSearchHitVertically(*hit)
+		return
+	}
+
+	if done {
+		p.initialSearchFrom = nil
+		p.setNotFound()
+		return
+	}
+
+	// Continue from here when more lines come in
+	next := linemetadata.IndexFromZeroBased(lineCount)
+	p.initialSearchFrom = &next
+}
+
+// The reader may have finished reading the input
+func (p *Pager) handleReadingMaybeDone() {
+	p.readerLock.Lock()
+	r := p.readers[p.currentReader]
+	p.readerLock.Unlock()
+
+	p.continueInitialSearch()
+	p.applyAutoWrap(r)
+
+	if !p.followUntilDone || !r.ReadingDone.Load() {
+		return
+	}
+
+	p.followUntilDone = false
+	if p.TargetLine != nil && *p.TargetLine == linemetadata.IndexMax() {
+		// Started with StartAtEnd and the user hasn't scrolled away, we're at
+		// the end now so stop following
+		p.scrollToEnd()
+		p.setTargetLine(nil)
+	}
+}
+
+// What the process should exit with after paging. With an InitialSearch that
+// didn't find anything, this is NotFoundExitCode. Quitting before the search
+// is done counts as not found.
+func (p *Pager) ExitCode() int {
+	if p.InitialSearch == "" || p.initialSearchFound {
+		return 0
+	}
+
+	return p.NotFoundExitCode
+}
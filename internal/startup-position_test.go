@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestStartAtLine(t *testing.T) {
+	// 20 lines, five of them visible at a time
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	target := linemetadata.IndexFromOneBased(8)
+	pager.TargetLine = &target
+
+	pager.applyStartupPosition()
+	pager.handleMoreLinesAvailable()
+
+	assert.Equal(t, "line 8", screenRows(pager)[0])
+}
+
+func TestStartAtEnd(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.StartAtEnd = true
+
+	pager.applyStartupPosition()
+	pager.handleMoreLinesAvailable()
+	assert.Equal(t, "line 20", screenRows(pager)[4])
+
+	// All input has been read, so we should stop following
+	pager.handleReadingMaybeDone()
+	assert.Assert(t, pager.TargetLine == nil)
+	assert.Equal(t, "line 20", screenRows(pager)[4])
+}
+
+func TestStartAtEndWhileFollowing(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	reallyHigh := linemetadata.IndexMax()
+	pager.TargetLine = &reallyHigh
+	pager.StartAtEnd = true
+
+	pager.applyStartupPosition()
+	pager.handleReadingMaybeDone()
+
+	// --follow wins, keep following
+	assert.Equal(t, linemetadata.IndexMax(), *pager.TargetLine)
+}
+
+func TestStartAtSearchHit(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.InitialSearch = "line 12"
+
+	pager.applyStartupPosition()
+
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, "line 12", pager.searchString)
+	assert.Equal(t, 11, pager.currentSearchHit.Index())
+}
+
+func TestStartAtSearchNoHits(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.InitialSearch = "xyzzy"
+
+	pager.applyStartupPosition()
+
+	assert.Equal(t, "NotFound", modeName(pager))
+	assert.Equal(t, "line 1", screenRows(pager)[0])
+	assert.Equal(t, "Not found: xyzzy", screenRows(pager)[5])
+}
+
+func TestExitCodeAfterStartupSearch(t *testing.T) {
+	found := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	found.InitialSearch = "line 12"
+	found.NotFoundExitCode = 3
+	found.applyStartupPosition()
+	assert.Equal(t, 0, found.ExitCode())
+
+	notFound := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	notFound.InitialSearch = "xyzzy"
+	notFound.NotFoundExitCode = 3
+	notFound.applyStartupPosition()
+	assert.Equal(t, 3, notFound.ExitCode())
+
+	// Moving on from the not found message doesn't make anything found
+	notFound.mode.onRune('j')
+	assert.Equal(t, 3, notFound.ExitCode())
+}
+
+// Exit codes are opt-in, and only about startup searches
+func TestExitCodeDefaults(t *testing.T) {
+	notFound := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	notFound.InitialSearch = "xyzzy"
+	notFound.applyStartupPosition()
+	assert.Equal(t, 0, notFound.ExitCode())
+
+	noSearch := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	noSearch.NotFoundExitCode = 3
+	noSearch.applyStartupPosition()
+	assert.Equal(t, 0, noSearch.ExitCode())
+}
+
+// The hit may not have been read yet when we start
+func TestStartAtSearchHitArrivingLater(t *testing.T) {
+	input, writer := io.Pipe()
+	writeTheRest := make(chan struct{})
+	go func() {
+		_, _ = writer.Write([]byte(strings.Repeat("hay\n", 10)))
+		<-writeTheRest
+		_, _ = writer.Write([]byte(strings.Repeat("hay\n", 10) + "needle\n"))
+		_ = writer.Close()
+	}()
+
+	r, err := reader.NewFromStream("test", input, formatters.TTY, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	awaitLineCount(t, r, 10)
+
+	pager := NewPager(r)
+	pager.screen = twin.NewFakeScreen(20, 6)
+	pager.InitialSearch = "needle"
+
+	pager.applyStartupPosition()
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Assert(t, pager.currentSearchHit == nil)
+
+	close(writeTheRest)
+	assert.NilError(t, r.Wait())
+
+	pager.handleMoreLinesAvailable()
+	pager.continueInitialSearch()
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 20, pager.currentSearchHit.Index())
+}
+
+// With a search given on the command line, the status bar should count the
+// hits right away, and 'n' should go to the next one
+func TestStartAtSearchHitCount(t *testing.T) {
+	lines := numberedLines("miss ", 1, 30)
+	for i := 3; i <= 30; i += 3 {
+		lines[i-1] = fmt.Sprintf("hit %d", i)
+	}
+	pager := createLinesPager(t, 20, 6, lines...)
+	pager.InitialSearch = "hit"
+
+	pager.applyStartupPosition()
+	assert.Equal(t, 2, pager.currentSearchHit.Index())
+	assert.Equal(t, "match 1 of 10", awaitSearchHitCountText(t, pager))
+
+	// "hit 6" is already on screen, so 'n' goes past it to "hit 9"
+	typeRunes(pager, "n")
+	assert.Equal(t, 8, pager.currentSearchHit.Index())
+	assert.Equal(t, "match 3 of 10", awaitSearchHitCountText(t, pager))
+}
@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/internal/util"
+)
+
+// Expand a StatusBarFormat template for the given screen contents.
+//
+// Placeholders:
+//
+//	%f: File name. For concatenated files, the one the first line on screen
+//	    came from.
+//	%l: Line number of the first line on screen
+//	%L: Total number of lines
+//	%p: How far into the input the last line on screen is, in percent
+//	%e: "(END)" or "(TOP)" if we're at either end of the input, "(FOLLOWING)"
+//	    if we're following input that is still coming in, otherwise empty
+//	%m: Name of the current mode
+//	%c: Number of columns scrolled to the right
+//	%w: "wrap" if long lines are wrapped, "chop" if they are cut off
+//	%%: A literal %
+//
+// Unknown placeholders are rendered literally.
+func (p *Pager) formatStatusBar(format string, rendered renderedScreen) string {
+	var result strings.Builder
+
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' || i+1 >= len(runes) {
+			result.WriteRune(runes[i])
+			continue
+		}
+
+		i++
+		switch runes[i] {
+		case 'f':
+			result.WriteString(p.statusFileName())
+		case 'l':
+			if len(rendered.inputLines) > 0 {
+				result.WriteString(rendered.inputLines[0].Number.Format())
+			} else {
+				result.WriteString("-")
+			}
+		case 'L':
+			result.WriteString(util.FormatInt(p.Reader().GetLineCount()))
+		case 'p':
+			result.WriteString(p.statusPercent(rendered))
+		case 'e':
+			result.WriteString(p.statusPosition(rendered))
+		case 'm':
+			result.WriteString(p.statusModeName())
+		case 'c':
+			result.WriteString(util.FormatInt(p.leftColumnZeroBased))
+		case 'w':
+			if p.WrapLongLines {
+				result.WriteString("wrap")
+			} else {
+				result.WriteString("chop")
+			}
+		case '%':
+			result.WriteRune('%')
+		default:
+			result.WriteRune('%')
+			result.WriteRune(runes[i])
+		}
+	}
+
+	return result.String()
+}
+
+func (p *Pager) statusFileName() string {
+	if name := p.topLineSourceName(); name != "" {
+		return name
+	}
+
+	var r *reader.ReaderImpl
+	if p.isShowingHelp {
+		r = _HelpReader
+	} else {
+		p.readerLock.Lock()
+		r = p.readers[p.currentReader]
+		p.readerLock.Unlock()
+	}
+
+	if r.DisplayName == nil {
+		return ""
+	}
+	return *r.DisplayName
+}
+
+// When showing concatenated files, the name of the one the top line came from.
+// Otherwise "".
+func (p *Pager) topLineSourceName() string {
+	if p.isShowingHelp || p.pipedReader != nil {
+		return ""
+	}
+
+	index := p.lineIndex()
+	if index == nil {
+		return ""
+	}
+
+	line := p.Reader().GetLine(*index)
+	if line == nil {
+		return ""
+	}
+
+	p.readerLock.Lock()
+	r := p.readers[p.currentReader]
+	p.readerLock.Unlock()
+
+	// Filtered lines keep their original numbers
+	return r.SourceName(linemetadata.IndexFromZeroBased(line.Number.AsZeroBased()))
+}
+
+func (p *Pager) statusPercent(rendered renderedScreen) string {
+	lineCount := p.Reader().GetLineCount()
+	if lineCount == 0 || len(rendered.lines) == 0 {
+		// We're showing all zero lines
+		return "100%"
+	}
+
+	lastVisible := rendered.lines[len(rendered.lines)-1].inputLineIndex
+	return fmt.Sprintf("%.0f%%", math.Floor(100*float64(lastVisible.Index()+1)/float64(lineCount)))
+}
+
+// "(END)" if the last line of the input is on screen, like in less. "(TOP)" if
+// the first line is on screen and there's more below. "(FOLLOWING)" if we're
+// staying at the end while more lines are coming in. Empty otherwise.
+//
+// While the input is still being read, we're never at the end.
+func (p *Pager) statusPosition(rendered renderedScreen) string {
+	if len(rendered.lines) == 0 {
+		return ""
+	}
+
+	var r *reader.ReaderImpl
+	if p.isShowingHelp {
+		r = _HelpReader
+	} else {
+		p.readerLock.Lock()
+		r = p.readers[p.currentReader]
+		p.readerLock.Unlock()
+	}
+
+	if r.ReadingDone.Load() && p.isScrolledToEnd() {
+		return "(END)"
+	}
+
+	if p.isFollowing() {
+		// More lines may come in, we're not at the end yet
+		return "(FOLLOWING)"
+	}
+
+	if rendered.lines[0].inputLineIndex == p.firstScrollableIndex() && rendered.lines[0].wrapIndex == 0 {
+		return "(TOP)"
+	}
+
+	return ""
+}
+
+func (p *Pager) statusModeName() string {
+	if p.isShowingHelp {
+		return "Help"
+	}
+
+	if p.isFollowing() {
+		if p.filterPattern != nil {
+			// Like tail -f | grep
+			return "Following, filtered"
+		}
+		return "Following"
+	}
+
+	switch p.mode.(type) {
+	case *PagerModeSearch:
+		return "Search"
+	case *PagerModeFilter:
+		return "Filter"
+	case *PagerModeGotoLine:
+		return "Go to line"
+	}
+
+	if p.filterPattern != nil {
+		return "Filtered"
+	}
+
+	return "Viewing"
+}
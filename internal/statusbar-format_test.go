@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func statusBarRow(pager *Pager) string {
+	pager.redraw("")
+
+	screen := pager.screen.(*twin.FakeScreen)
+	_, height := screen.Size()
+	return rowToString(screen.GetRow(height - 1))
+}
+
+func TestStatusBarFormat(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.StatusBarFormat = "%m %l/%L %p"
+
+	assert.Equal(t, "Viewing 1/6 33%  Pre", statusBarRow(pager))
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Equal(t, "Viewing 2/6 50%  Pre", statusBarRow(pager))
+}
+
+func TestStatusBarFormatUnknownPlaceholders(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.StatusBarFormat = "%x 100%% %"
+
+	assert.Equal(t, "%x 100% %  Press ESC", statusBarRow(pager))
+}
+
+func TestStatusBarFormatFileName(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.StatusBarFormat = "[%f]"
+
+	displayName := "x.txt"
+	pager.readers[0].DisplayName = &displayName
+
+	assert.Equal(t, "[x.txt]  Press ESC /", statusBarRow(pager))
+}
+
+func TestStatusBarFormatColumn(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "0123456789abcdefghij"))
+	pager.ShowLineNumbers = false
+	pager.showLineNumbers = false
+	pager.SideScrollAmount = 5
+	pager.StatusBarFormat = "col %c"
+	screen := twin.NewFakeScreen(10, 3)
+	pager.screen = screen
+	pager.redraw("")
+
+	pager.mode.onKey(twin.KeyRight)
+	pager.redraw("")
+	assert.Equal(t, "col 5  Pre", rowToString(screen.GetRow(2)))
+}
+
+func TestStatusBarFormatWrap(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("", "a\nb"))
+	pager.StatusBarFormat = "%w"
+	screen := twin.NewFakeScreen(20, 4)
+	pager.screen = screen
+
+	pager.redraw("")
+	assert.Equal(t, "chop  Press ESC / q", rowToString(screen.GetRow(3)))
+
+	pager.WrapLongLines = true
+	pager.redraw("")
+	assert.Equal(t, "wrap  Press ESC / q", rowToString(screen.GetRow(3)))
+}
+
+func TestStatusPosition(t *testing.T) {
+	pager := createThreeLinesPager(t)
+	pager.screen = twin.NewFakeScreen(40, 3)
+
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "6 lines  33%  (TOP)  Press"), statusBarRow(pager))
+
+	pager.mode.onKey(twin.KeyDown)
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "6 lines  50%  Press"), statusBarRow(pager))
+
+	pager.scrollToEnd()
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "6 lines  100%  (END)  Press"), statusBarRow(pager))
+
+	// With a custom format, the user decides where this goes
+	pager.StatusBarFormat = "%p %e"
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "100% (END)  Press"), statusBarRow(pager))
+}
+
+func TestStatusConcatenatedSourceName(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	assert.NilError(t, os.WriteFile(first, []byte("a\nb\n"), 0o600))
+	assert.NilError(t, os.WriteFile(second, []byte("c\nd\n"), 0o600))
+
+	concatenated, err := reader.NewFromFilenames([]string{first, second}, false, nil, reader.ReaderOptions{Style: &chroma.Style{}})
+	assert.NilError(t, err)
+	assert.NilError(t, concatenated.Wait())
+
+	pager := NewPager(concatenated)
+	pager.screen = twin.NewFakeScreen(40, 3)
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "first.txt: 4 lines  50%"), statusBarRow(pager))
+
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(2), "TestStatusConcatenatedSourceName")
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "second.txt: 4 lines  100%"), statusBarRow(pager))
+
+	pager.StatusBarFormat = "%f"
+	assert.Assert(t, strings.HasPrefix(statusBarRow(pager), "second.txt  Press"), statusBarRow(pager))
+}
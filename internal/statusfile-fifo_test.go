@@ -0,0 +1,36 @@
+//go:build !windows
+
+package internal
+
+import (
+	"path"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// Nobody reading from the pipe must not make the pager hang
+func TestStatusFileFifoWithoutReader(t *testing.T) {
+	fifo := path.Join(t.TempDir(), "status.fifo")
+	assert.NilError(t, syscall.Mkfifo(fifo, 0o600))
+
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.StatusFile = fifo
+
+	done := make(chan struct{})
+	go func() {
+		for range 10 {
+			typeRunes(pager, "j")
+			pager.redraw("")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Redrawing with an unread status pipe hung")
+	}
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Writes the current position to a file or named pipe, for showing in tmux
+// status bars and the like.
+//
+// Writing happens in the background, so that a stalled reader of a named pipe
+// can't freeze the pager. If the writer falls behind, only the latest position
+// gets written.
+type statusFileWriter struct {
+	fileName string
+
+	lock sync.Mutex
+
+	// The next text to write, nil if there is nothing new
+	pending *string
+
+	// The text we were last asked to write, for not writing the same thing
+	// over and over
+	latest string
+
+	// Set by stop(), no more updates after that
+	stopped bool
+
+	wakeup chan struct{}
+
+	// Closed when the background writer is done
+	done chan struct{}
+}
+
+func newStatusFileWriter(fileName string) *statusFileWriter {
+	writer := &statusFileWriter{
+		fileName: fileName,
+		wakeup:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer func() {
+			PanicHandler("statusFileWriter.run()", recover(), debug.Stack())
+		}()
+		defer close(writer.done)
+
+		writer.run()
+	}()
+
+	return writer
+}
+
+// Queue some text for writing. Never blocks.
+func (w *statusFileWriter) update(text string) {
+	w.lock.Lock()
+	if w.stopped || text == w.latest {
+		w.lock.Unlock()
+		return
+	}
+	w.latest = text
+	w.pending = &text
+	w.lock.Unlock()
+
+	select {
+	case w.wakeup <- struct{}{}:
+	default:
+		// A wakeup is already queued, and it will pick up our text
+	}
+}
+
+// Make the background writer exit after writing any pending text. Never
+// blocks.
+func (w *statusFileWriter) stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.stopped {
+		return
+	}
+
+	w.stopped = true
+	close(w.wakeup)
+}
+
+func (w *statusFileWriter) run() {
+	for range w.wakeup {
+		w.lock.Lock()
+		text := w.pending
+		w.pending = nil
+		w.lock.Unlock()
+
+		if text == nil {
+			continue
+		}
+
+		w.write(*text)
+	}
+}
+
+func (w *statusFileWriter) write(text string) {
+	stat, err := os.Stat(w.fileName)
+	if err == nil && !stat.Mode().IsRegular() {
+		w.writeToPipe(text)
+		return
+	}
+
+	w.replaceFile(text)
+}
+
+// Write to a temporary file and rename it over the status file, so that
+// readers never see it empty or half written
+func (w *statusFileWriter) replaceFile(text string) {
+	file, err := os.CreateTemp(filepath.Dir(w.fileName), filepath.Base(w.fileName)+".*.tmp")
+	if err != nil {
+		log.Debugf("Not writing status to %s: %v", w.fileName, err)
+		return
+	}
+
+	_, err = file.WriteString(text)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), w.fileName)
+	}
+	if err != nil {
+		log.Debugf("Writing status to %s failed: %v", w.fileName, err)
+		_ = os.Remove(file.Name())
+	}
+}
+
+func (w *statusFileWriter) writeToPipe(text string) {
+	// O_NONBLOCK makes opening a named pipe without a reader fail rather than
+	// hang, and writing to a full one fail rather than wait.
+	file, err := os.OpenFile(w.fileName, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		log.Debugf("Not writing status to %s: %v", w.fileName, err)
+		return
+	}
+
+	_, err = file.WriteString(text)
+	if err != nil {
+		log.Debugf("Writing status to %s failed: %v", w.fileName, err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		log.Debugf("Closing status file %s failed: %v", w.fileName, err)
+	}
+}
+
+// Something like "line 340/1000, 34%". No thousands separators, to make this
+// easy for scripts to parse.
+func (p *Pager) statusFileText(rendered renderedScreen) string {
+	firstLine := 0
+	if len(rendered.inputLines) > 0 {
+		firstLine = rendered.inputLines[0].Number.AsOneBased()
+	}
+
+	return fmt.Sprintf("line %d/%d, %s\n", firstLine, p.Reader().GetLineCount(), p.statusPercent(rendered))
+}
+
+// Write the current position to StatusFile, if set
+func (p *Pager) updateStatusFile(rendered renderedScreen) {
+	if p.StatusFile == "" {
+		return
+	}
+
+	if p.statusFileWriter == nil {
+		p.statusFileWriter = newStatusFileWriter(p.StatusFile)
+	}
+
+	p.statusFileWriter.update(p.statusFileText(rendered))
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// Wait for the status file to get some specific contents
+func awaitStatusFile(t *testing.T, fileName string, expected string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		contents, err := os.ReadFile(fileName)
+		if err == nil && string(contents) == expected {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Status file never became %q, last was %q (err=%v)", expected, string(contents), err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStatusFile(t *testing.T) {
+	statusFile := path.Join(t.TempDir(), "status")
+
+	// 20 lines, five of them visible at a time
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+	pager.StatusFile = statusFile
+
+	pager.redraw("")
+	awaitStatusFile(t, statusFile, "line 1/20, 25%\n")
+
+	typeRunes(pager, "10j")
+	pager.redraw("")
+	awaitStatusFile(t, statusFile, "line 11/20, 75%\n")
+
+	pager.scrollToEnd()
+	pager.redraw("")
+	awaitStatusFile(t, statusFile, "line 16/20, 100%\n")
+}
+
+func TestStatusFileEmptyInput(t *testing.T) {
+	statusFile := path.Join(t.TempDir(), "status")
+
+	pager := createLinesPager(t, 20, 5)
+	pager.StatusFile = statusFile
+
+	pager.redraw("")
+	awaitStatusFile(t, statusFile, "line 0/0, 100%\n")
+}
+
+// Stopping should write the last update, and then make the writer exit
+func TestStatusFileStop(t *testing.T) {
+	statusFile := path.Join(t.TempDir(), "status")
+
+	writer := newStatusFileWriter(statusFile)
+	writer.update("first\n")
+	writer.stop()
+	<-writer.done
+
+	contents, err := os.ReadFile(statusFile)
+	assert.NilError(t, err)
+	assert.Equal(t, "first\n", string(contents))
+
+	// Updates after stopping should be ignored rather than panic
+	writer.update("second\n")
+	writer.stop()
+}
+
+// Rewriting the status file shouldn't leave any temporary files behind
+func TestStatusFileNoLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	statusFile := path.Join(dir, "status")
+
+	writer := newStatusFileWriter(statusFile)
+	writer.write("first\n")
+	writer.write("second\n")
+	writer.stop()
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "status", entries[0].Name())
+}
+
+func TestNoStatusFile(t *testing.T) {
+	pager := createLinesPager(t, 20, 6, numberedLines("line ", 1, 20)...)
+
+	pager.redraw("")
+	assert.Assert(t, pager.statusFileWriter == nil)
+}
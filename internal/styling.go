@@ -14,8 +14,15 @@ import (
 // From LESS_TERMCAP_so, overrides statusbarStyle from the Chroma style if set
 var standoutStyle *twin.Style
 
+// From Pager.SearchHitStyle, overrides standoutStyle for search hits if set
+var customSearchHitStyle *twin.Style
+
 var lineNumbersStyle = twin.StyleDefault.WithAttr(twin.AttrDim)
 
+// Tab and trailing space markers when Pager.ShowWhitespace is set. The
+// background of the marked cell is kept.
+var whitespaceStyle = twin.StyleDefault.WithAttr(twin.AttrDim)
+
 // Status bar and EOF marker style
 var statusbarStyle = twin.StyleDefault.WithAttr(twin.AttrReverse)
 
@@ -26,6 +33,13 @@ var searchHitStyle = twin.StyleDefault.WithAttr(twin.AttrReverse)
 // This can be nil
 var searchHitLineBackground *twin.Color
 
+// Background for the current line when Pager.HighlightCurrentLine is set. If
+// this is nil, the current line is shown in reverse video instead.
+var currentLineBackground *twin.Color
+
+// True if the screen renders attributes only, see styleWithoutColors()
+var withoutColors bool
+
 func setStyle(updateMe *twin.Style, envVarName string, fallback *twin.Style) {
 	envValue := os.Getenv(envVarName)
 	if envValue == "" {
@@ -195,16 +209,59 @@ func styleUI(terminalBackground *twin.Color, chromaStyle *chroma.Style, chromaFo
 	configureHighlighting(terminalBackground, configureSearchHitLineBackground)
 }
 
+// With NO_COLOR, all colors are stripped when rendering. Make sure search hits
+// and highlighted lines are still visible using attributes only.
+func styleWithoutColors() {
+	withoutColors = true
+
+	if searchHitStyle.WithoutColors() == twin.StyleDefault {
+		// Nothing left to see, use the default
+		searchHitStyle = twin.StyleDefault.WithAttr(twin.AttrReverse)
+	}
+	if statusbarStyle.WithoutColors() == twin.StyleDefault {
+		statusbarStyle = twin.StyleDefault.WithAttr(twin.AttrReverse)
+	}
+
+	// These are background colors only, and would be invisible
+	searchHitLineBackground = nil
+	currentLineBackground = nil
+}
+
+// Our best guess at what the background of plain text looks like. Can be
+// twin.ColorDefault if we don't know.
+func plainBackground(terminalBackground *twin.Color) twin.Color {
+	if terminalBackground != nil {
+		return *terminalBackground
+	}
+
+	if plainTextStyle.HasAttr(twin.AttrReverse) {
+		return plainTextStyle.Foreground()
+	}
+
+	return plainTextStyle.Background()
+}
+
 // Expects to be called from the end of styleUI(), since at that
 // point we should have all data we need to set up highlighting.
 func configureHighlighting(terminalBackground *twin.Color, configureSearchHitLineBackground bool) {
-	if standoutStyle != nil {
+	if customSearchHitStyle != nil {
+		searchHitStyle = *customSearchHitStyle
+		log.Trace("Search hit style set from user configuration: ", searchHitStyle)
+	} else if standoutStyle != nil {
 		searchHitStyle = *standoutStyle
 		log.Trace("Search hit style set from standout style: ", searchHitStyle)
 	} else {
 		log.Trace("Search hit style set to default: ", searchHitStyle)
 	}
 
+	plainBg := plainBackground(terminalBackground)
+	if plainBg != twin.ColorDefault {
+		// Just a hint of a different color, this should be subtle
+		mixed := plainBg.Mix(getOppositeColor(plainBg), 0.1)
+		currentLineBackground = &mixed
+		log.Trace("Current line background set to mixed color: ", *currentLineBackground)
+	}
+
 	//
 	// Everything below this point relates to figuring out which background
 	// color we should use for lines with search hits.
@@ -215,15 +272,6 @@ func configureHighlighting(terminalBackground *twin.Color, configureSearchHitLin
 		return
 	}
 
-	var plainBg twin.Color
-	if terminalBackground != nil {
-		plainBg = *terminalBackground
-	} else if plainTextStyle.HasAttr(twin.AttrReverse) {
-		plainBg = plainTextStyle.Foreground()
-	} else {
-		plainBg = plainTextStyle.Background()
-	}
-
 	hitBg := searchHitStyle.Background()
 	hitFg := searchHitStyle.Foreground()
 	if searchHitStyle.HasAttr(twin.AttrReverse) {
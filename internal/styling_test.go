@@ -43,3 +43,30 @@ func TestConfigureHighlighting_No24BitColors(t *testing.T) {
 	searchHitStyle = twin.StyleDefault.WithForeground(twin.NewColor16(3))
 	configureHighlighting(nil, true)
 }
+
+func TestStyleWithoutColors(t *testing.T) {
+	defer func(hitStyle twin.Style, statusStyle twin.Style, hitLineBackground *twin.Color, lineBackground *twin.Color) {
+		searchHitStyle = hitStyle
+		statusbarStyle = statusStyle
+		searchHitLineBackground = hitLineBackground
+		currentLineBackground = lineBackground
+		withoutColors = false
+	}(searchHitStyle, statusbarStyle, searchHitLineBackground, currentLineBackground)
+
+	// Background only search hits would be invisible without colors
+	blue := twin.NewColor16(4)
+	searchHitStyle = twin.StyleDefault.WithBackground(blue)
+	statusbarStyle = twin.StyleDefault.WithAttr(twin.AttrBold).WithForeground(blue)
+	searchHitLineBackground = &blue
+	currentLineBackground = &blue
+
+	styleWithoutColors()
+
+	assert.Equal(t, searchHitStyle, twin.StyleDefault.WithAttr(twin.AttrReverse))
+	assert.Equal(t, statusbarStyle.WithoutColors(), twin.StyleDefault.WithAttr(twin.AttrBold))
+	assert.Assert(t, searchHitLineBackground == nil)
+	assert.Assert(t, currentLineBackground == nil)
+
+	// Sticky highlights must be visible too
+	assert.Assert(t, stickyHighlightStyle(blue).WithoutColors() != twin.StyleDefault)
+}
@@ -5,10 +5,11 @@ package textstyles
 
 import (
 	"fmt"
-	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/rivo/uniseg"
 	"github.com/walles/moor/v2/internal/linemetadata"
 	"github.com/walles/moor/v2/twin"
 )
@@ -19,10 +20,31 @@ type UnprintableStyleT int
 const (
 	UnprintableStyleHighlight UnprintableStyleT = iota
 	UnprintableStyleWhitespace
+
+	// Like "cat -v": Control characters as ^C, invalid UTF-8 bytes as \xNN
+	UnprintableStyleCaret
+
+	// Control characters as Unicode control pictures, like ␇ for BEL
+	UnprintableStyleSymbols
 )
 
 var UnprintableStyle UnprintableStyleT
 
+// How do we render carriage returns inside of lines?
+type CarriageReturnStyleT int
+
+const (
+	// Like a terminal would: Go back to the start of the line and overwrite
+	// what's there. This makes a progress bar line like "10%\r50%\r100%" show
+	// up as "100%".
+	CarriageReturnStyleOverwrite CarriageReturnStyleT = iota
+
+	// Render carriage returns like any other unprintable character
+	CarriageReturnStyleRaw
+)
+
+var CarriageReturnStyle CarriageReturnStyleT
+
 // These three styles will be configured from styling.go
 var ManPageBold = twin.StyleDefault.WithAttr(twin.AttrBold)
 var ManPageUnderline = twin.StyleDefault.WithAttr(twin.AttrUnderline)
@@ -33,6 +55,128 @@ var TabSize = 8
 
 const BACKSPACE = '\b'
 
+// Invalid UTF-8 bytes are decoded into lone surrogates in the range
+// 0xDC80-0xDCFF, so that we can tell them apart from any other rune. Lone
+// surrogates can't come out of valid UTF-8, so there is no ambiguity.
+const invalidByteBase = 0xDC00
+
+// Like []rune(s), but invalid UTF-8 bytes are decoded using invalidByteBase
+// rather than into utf8.RuneError.
+func decodeRunes(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); {
+		runeValue, size := utf8.DecodeRuneInString(s[i:])
+		if runeValue == utf8.RuneError && size == 1 {
+			runeValue = invalidByteBase + rune(s[i])
+		}
+		runes = append(runes, runeValue)
+		i += size
+	}
+
+	return runes
+}
+
+// When ranging over a string, invalid UTF-8 bytes come out as utf8.RuneError.
+// This decodes those using invalidByteBase instead, like decodeRunes() does.
+func decodeInvalidByte(s string, index int, runeValue rune) rune {
+	if runeValue != utf8.RuneError {
+		return runeValue
+	}
+
+	if _, size := utf8.DecodeRuneInString(s[index:]); size == 1 {
+		return invalidByteBase + rune(s[index])
+	}
+
+	// An actual U+FFFD in the input
+	return runeValue
+}
+
+func isInvalidByte(runeValue rune) bool {
+	return runeValue >= invalidByteBase+0x80 && runeValue <= invalidByteBase+0xFF
+}
+
+// If this rune should be rendered using caret notation, return that notation.
+// Tabs are not included, those are expanded into spaces.
+func caretNotation(runeValue rune) (string, bool) {
+	if isInvalidByte(runeValue) {
+		return fmt.Sprintf("\\x%02X", runeValue-invalidByteBase), true
+	}
+
+	if runeValue == '\x09' {
+		return "", false
+	}
+
+	if runeValue < 0x20 {
+		return "^" + string(runeValue+0x40), true
+	}
+
+	if runeValue == 0x7f {
+		return "^?", true
+	}
+
+	return "", false
+}
+
+// If this rune should be rendered as a Unicode control picture, return that
+// picture. Tabs are not included, those are expanded into spaces.
+func controlPicture(runeValue rune) (rune, bool) {
+	if runeValue == '\x09' {
+		return 0, false
+	}
+
+	if runeValue < 0x20 {
+		return 0x2400 + runeValue, true
+	}
+
+	if runeValue == 0x7f {
+		return '␡', true
+	}
+
+	return 0, false
+}
+
+// Remove ANSI escape codes from s. Unlike StripFormatting(), this leaves
+// everything else alone, including tabs, backspaces and other control
+// characters.
+func StripEscapeCodes(s string) string {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return s
+	}
+
+	stripped := strings.Builder{}
+	stripped.Grow(len(s))
+	styledStringsFromString(twin.StyleDefault, s, nil, func(str string, _ twin.Style) {
+		stripped.WriteString(str)
+	})
+
+	return stripped.String()
+}
+
+// Show escape codes and other control characters in s using caret notation,
+// like "cat -v" does. Invalid UTF-8 bytes are shown as \xNN. Tabs are kept.
+func ShowEscapes(s string) string {
+	if isPlain(s) {
+		return s
+	}
+
+	shown := strings.Builder{}
+	shown.Grow(len(s))
+	for _, runeValue := range decodeRunes(s) {
+		if notation, ok := caretNotation(runeValue); ok {
+			shown.WriteString(notation)
+			continue
+		}
+		shown.WriteRune(runeValue)
+	}
+
+	return shown.String()
+}
+
+// A tab at this screen column should be expanded into this many spaces
+func spacesToNextTabStop(column int) int {
+	return TabSize - column%TabSize
+}
+
 type StyledRunesWithTrailer struct {
 	StyledRunes       []CellWithMetadata
 	Trailer           twin.Style
@@ -45,7 +189,8 @@ func isPlain(s string) bool {
 		if byteAtIndex < 32 {
 			return false
 		}
-		if byteAtIndex > 127 {
+		if byteAtIndex >= 127 {
+			// DEL is unprintable, and anything above is non-ASCII
 			return false
 		}
 	}
@@ -59,48 +204,75 @@ func StripFormatting(s string, lineIndex linemetadata.Index) string {
 		return s
 	}
 
-	stripped := strings.Builder{}
-	stripped.Grow(len(s)) // This makes BenchmarkStripFormatting 6% faster
-	runeCount := 0
+	// Only lines with carriage returns need to keep track of where clusters
+	// start, see plainText.carriageReturn()
+	stripped := plainText{lastCluster: -1}
+	if CarriageReturnStyle == CarriageReturnStyleOverwrite && strings.IndexByte(s, '\r') >= 0 {
+		stripped.overwrite = &overwrittenText{}
+	}
+	stripped.text.Grow(len(s)) // This makes BenchmarkStripFormatting 6% faster
 
 	styledStringsFromString(twin.StyleDefault, s, &lineIndex, func(str string, style twin.Style) {
-		for _, runeValue := range runesFromStyledString(_StyledString{String: str, Style: style}) {
+		runes := runesFromStyledString(_StyledString{String: str, Style: style})
+		for index, runeValue := range runes {
+			if runeValue >= ' ' && runeValue < 0x7f {
+				// Printable ASCII, nothing special about it
+				stripped.write(runeValue)
+				continue
+			}
+
+			runeValue = decodeInvalidByte(runes, index, runeValue)
+			if UnprintableStyle == UnprintableStyleCaret {
+				if notation, ok := caretNotation(runeValue); ok {
+					stripped.writeString(notation)
+					continue
+				}
+			}
+			if UnprintableStyle == UnprintableStyleSymbols {
+				if picture, ok := controlPicture(runeValue); ok {
+					stripped.write(picture)
+					continue
+				}
+			}
+			if runeValue == '\r' && CarriageReturnStyle == CarriageReturnStyleOverwrite {
+				stripped.carriageReturn()
+				continue
+			}
+			if isInvalidByte(runeValue) {
+				runeValue = '�'
+			}
+
 			switch runeValue {
 
 			case '\x09': // TAB
-				for {
-					stripped.WriteRune(' ')
-					runeCount++
-
-					if runeCount%TabSize == 0 {
-						// We arrived at the next tab stop
-						break
-					}
+				for range spacesToNextTabStop(stripped.column()) {
+					stripped.write(' ')
 				}
 
 			case '�': // Go's broken-UTF8 marker
 				switch UnprintableStyle {
 				case UnprintableStyleHighlight:
-					stripped.WriteRune('?')
+					stripped.write('?')
 				case UnprintableStyleWhitespace:
-					stripped.WriteRune(' ')
+					stripped.write(' ')
+				case UnprintableStyleCaret, UnprintableStyleSymbols:
+					stripped.write('�')
 				default:
 					panic(fmt.Errorf("Unsupported unprintable-style: %#v", UnprintableStyle))
 				}
-				runeCount++
 
 			case BACKSPACE:
-				stripped.WriteRune('<')
-				runeCount++
+				stripped.write('<')
 
 			default:
+				if stripped.combine(runeValue) {
+					continue
+				}
 				if !twin.Printable(runeValue) {
-					stripped.WriteRune('?')
-					runeCount++
+					stripped.write('?')
 					continue
 				}
-				stripped.WriteRune(runeValue)
-				runeCount++
+				stripped.write(runeValue)
 			}
 		}
 	})
@@ -108,6 +280,112 @@ func StripFormatting(s string, lineIndex linemetadata.Index) string {
 	return stripped.String()
 }
 
+// Plain text where writing can restart from the beginning after a carriage
+// return, overwriting what's already there. Overwriting is done by grapheme
+// cluster, each cluster corresponds to one cell in StyledRunesFromString(), so
+// that plain text and cells stay in sync.
+type plainText struct {
+	text strings.Builder
+
+	// Byte offset of the last cluster in text, or -1 if there is none
+	lastCluster int
+
+	// Screen column after the text we have measured so far, see column()
+	measuredColumn int
+	measuredBytes  int
+
+	// Only set for lines with carriage returns. Without it, carriageReturn()
+	// won't be called.
+	overwrite *overwrittenText
+}
+
+// What plainText needs to know to overwrite clusters after carriage returns
+type overwrittenText struct {
+	// Byte offsets of the clusters in plainText.text
+	clusterStarts []int
+
+	// What was written before the last carriage return, with its cluster start
+	// offsets. Shows wherever plainText.text is shorter.
+	below       string
+	belowStarts []int
+}
+
+func (p *plainText) write(runeValue rune) {
+	p.lastCluster = p.text.Len()
+	if p.overwrite != nil {
+		p.overwrite.clusterStarts = append(p.overwrite.clusterStarts, p.lastCluster)
+	}
+	p.text.WriteRune(runeValue)
+}
+
+// Add runeValue to the last cluster if it belongs there. Returns false if it
+// should be written as a new cluster instead.
+func (p *plainText) combine(runeValue rune) bool {
+	if p.lastCluster < 0 || !ContinuesGraphemeCluster(p.text.String()[p.lastCluster:], runeValue) {
+		return false
+	}
+
+	if p.measuredBytes > p.lastCluster {
+		// The last cluster's width may change, measure it again
+		p.measuredColumn -= uniseg.StringWidth(p.text.String()[p.lastCluster:p.measuredBytes])
+		p.measuredBytes = p.lastCluster
+	}
+
+	p.text.WriteRune(runeValue)
+	return true
+}
+
+func (p *plainText) writeString(s string) {
+	for _, runeValue := range s {
+		p.write(runeValue)
+	}
+}
+
+func (p *plainText) carriageReturn() {
+	p.overwrite.below, p.overwrite.belowStarts = p.overwritten()
+
+	p.text.Reset()
+	p.overwrite.clusterStarts = nil
+	p.lastCluster = -1
+	p.measuredColumn = 0
+	p.measuredBytes = 0
+}
+
+// Screen column of the cursor
+func (p *plainText) column() int {
+	text := p.text.String()
+	p.measuredColumn += uniseg.StringWidth(text[p.measuredBytes:])
+	p.measuredBytes = len(text)
+	return p.measuredColumn
+}
+
+// The text written since the last carriage return on top of what was there
+// before it, with cluster start offsets
+func (p *plainText) overwritten() (string, []int) {
+	text := p.text.String()
+	o := p.overwrite
+	if len(o.belowStarts) <= len(o.clusterStarts) {
+		return text, o.clusterStarts
+	}
+
+	tail := o.belowStarts[len(o.clusterStarts)]
+	starts := o.clusterStarts
+	for _, start := range o.belowStarts[len(o.clusterStarts):] {
+		starts = append(starts, start-tail+len(text))
+	}
+
+	return text + o.below[tail:], starts
+}
+
+func (p *plainText) String() string {
+	if p.overwrite == nil {
+		return p.text.String()
+	}
+
+	text, _ := p.overwritten()
+	return text
+}
+
 // Turn a (formatted) string into a series of screen cells
 //
 // The prefix will be prepended to the string before parsing. The lineIndex is
@@ -120,58 +398,124 @@ func StyledRunesFromString(plainTextStyle twin.Style, s string, lineIndex *linem
 
 	cells := make([]CellWithMetadata, 0, len(s))
 
+	// Screen column after the cells we have measured so far. Wide runes take
+	// up two columns, so this isn't the same as the number of cells.
+	column := 0
+	measuredCells := 0
+
+	// Where the next cell goes, this moves back to the start of the line on
+	// carriage returns. See CarriageReturnStyle.
+	cursor := 0
+	putCell := func(cell CellWithMetadata) {
+		if cursor < len(cells) {
+			cells[cursor] = cell
+		} else {
+			cells = append(cells, cell)
+		}
+		cursor++
+	}
+
 	// Specs: https://en.wikipedia.org/wiki/ANSI_escape_code#3-bit_and_4-bit
 	styleUnprintable := twin.StyleDefault.WithBackground(twin.NewColor16(1)).WithForeground(twin.NewColor16(7))
 
 	trailer := styledStringsFromString(plainTextStyle, s, lineIndex, func(str string, style twin.Style) {
 		for _, token := range tokensFromStyledString(_StyledString{String: str, Style: style}) {
+			if UnprintableStyle == UnprintableStyleCaret {
+				if notation, ok := caretNotation(token.Rune); ok {
+					for _, runeValue := range notation {
+						putCell(CellWithMetadata{
+							Rune:  runeValue,
+							Style: styleUnprintable,
+						})
+					}
+					continue
+				}
+			}
+			if UnprintableStyle == UnprintableStyleSymbols {
+				if picture, ok := controlPicture(token.Rune); ok {
+					putCell(CellWithMetadata{
+						Rune:  picture,
+						Style: styleUnprintable,
+					})
+					continue
+				}
+			}
+			if token.Rune == '\r' && CarriageReturnStyle == CarriageReturnStyleOverwrite {
+				cursor = 0
+				column = 0
+				measuredCells = 0
+				continue
+			}
+			if isInvalidByte(token.Rune) {
+				token.Rune = '�'
+			}
+
 			switch token.Rune {
 
 			case '\x09': // TAB
-				for {
-					cells = append(cells, CellWithMetadata{
-						Rune:  ' ',
-						Style: style,
-					})
+				for ; measuredCells < cursor; measuredCells++ {
+					column += cells[measuredCells].Width()
+				}
 
-					if (len(cells))%TabSize == 0 {
-						// We arrived at the next tab stop
-						break
-					}
+				for i := range spacesToNextTabStop(column) {
+					putCell(CellWithMetadata{
+						Rune:      ' ',
+						Style:     style,
+						StartsTab: i == 0,
+						IsTab:     true,
+					})
 				}
 
 			case '�': // Go's broken-UTF8 marker
 				switch UnprintableStyle {
 				case UnprintableStyleHighlight:
-					cells = append(cells, CellWithMetadata{
+					putCell(CellWithMetadata{
 						Rune:  '?',
 						Style: styleUnprintable,
 					})
 				case UnprintableStyleWhitespace:
-					cells = append(cells, CellWithMetadata{
+					putCell(CellWithMetadata{
 						Rune:  '?',
 						Style: twin.StyleDefault,
 					})
+				case UnprintableStyleCaret, UnprintableStyleSymbols:
+					putCell(CellWithMetadata{
+						Rune:  '�',
+						Style: twin.StyleDefault,
+					})
 				default:
 					panic(fmt.Errorf("Unsupported unprintable-style: %#v", UnprintableStyle))
 				}
 
 			case BACKSPACE:
-				cells = append(cells, CellWithMetadata{
+				putCell(CellWithMetadata{
 					Rune:  '<',
 					Style: styleUnprintable,
 				})
 
 			default:
+				if cursor > 0 {
+					previous := &cells[cursor-1]
+					if previous.continuedBy(token.Rune) {
+						if measuredCells == cursor {
+							// The previous cell's width may change, measure it again
+							measuredCells--
+							column -= previous.Width()
+						}
+						previous.combine(token.Rune)
+						continue
+					}
+				}
+
 				if !twin.Printable(token.Rune) {
 					switch UnprintableStyle {
-					case UnprintableStyleHighlight:
-						cells = append(cells, CellWithMetadata{
+					case UnprintableStyleHighlight, UnprintableStyleCaret, UnprintableStyleSymbols:
+						putCell(CellWithMetadata{
 							Rune:  '?',
 							Style: styleUnprintable,
 						})
 					case UnprintableStyleWhitespace:
-						cells = append(cells, CellWithMetadata{
+						putCell(CellWithMetadata{
 							Rune:  ' ',
 							Style: twin.StyleDefault,
 						})
@@ -180,7 +524,7 @@ func StyledRunesFromString(plainTextStyle twin.Style, s string, lineIndex *linem
 					}
 					continue
 				}
-				cells = append(cells, CellWithMetadata{
+				putCell(CellWithMetadata{
 					Rune:  token.Rune,
 					Style: token.Style,
 				})
@@ -324,7 +668,7 @@ func consumeBullet(runes []rune, index int) (int, *twin.StyledRune) {
 }
 
 func runesFromStyledString(styledString _StyledString) string {
-	hasBackspace := slices.Contains([]byte(styledString.String), BACKSPACE)
+	hasBackspace := strings.IndexByte(styledString.String, BACKSPACE) >= 0
 
 	if !hasBackspace {
 		// Shortcut when there's no backspace based formatting to worry about
@@ -336,6 +680,11 @@ func runesFromStyledString(styledString _StyledString) string {
 	returnMe := strings.Builder{}
 	returnMe.Grow(len(cells))
 	for _, cell := range cells {
+		if isInvalidByte(cell.Rune) {
+			// Put the invalid byte back, decodeInvalidByte() will find it
+			returnMe.WriteByte(byte(cell.Rune - invalidByteBase))
+			continue
+		}
 		returnMe.WriteRune(cell.Rune)
 	}
 
@@ -343,28 +692,23 @@ func runesFromStyledString(styledString _StyledString) string {
 }
 
 func tokensFromStyledString(styledString _StyledString) []twin.StyledRune {
-	runes := []rune(styledString.String)
+	hasBackspace := strings.IndexByte(styledString.String, BACKSPACE) >= 0
 
-	hasBackspace := false
-	for _, runeValue := range runes {
-		if runeValue == BACKSPACE {
-			hasBackspace = true
-			break
-		}
-	}
-
-	tokens := make([]twin.StyledRune, 0, len(runes))
 	if !hasBackspace {
 		// Shortcut when there's no backspace based formatting to worry about
-		for _, runeValue := range runes {
+		tokens := make([]twin.StyledRune, 0, utf8.RuneCountInString(styledString.String))
+		for index, runeValue := range styledString.String {
 			tokens = append(tokens, twin.StyledRune{
-				Rune:  runeValue,
+				Rune:  decodeInvalidByte(styledString.String, index, runeValue),
 				Style: styledString.Style,
 			})
 		}
 		return tokens
 	}
 
+	runes := decodeRunes(styledString.String)
+	tokens := make([]twin.StyledRune, 0, len(runes))
+
 	// Special handling for man page formatted lines. If this is updated you
 	// must update HasManPageFormatting() as well.
 	for index := 0; index < len(runes); index++ {
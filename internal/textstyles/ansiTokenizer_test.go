@@ -7,7 +7,6 @@ import (
 	"path"
 	"strings"
 	"testing"
-	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp"
 	log "github.com/sirupsen/logrus"
@@ -81,22 +80,22 @@ func TestTokenize(t *testing.T) {
 
 				tokens := StyledRunesFromString(twin.StyleDefault, line, lineIndex).StyledRunes
 				plainString := StripFormatting(line, *lineIndex)
-				if len(tokens) != utf8.RuneCountInString(plainString) {
+				plainStringChars := graphemeClusters(plainString)
+				if len(tokens) != len(plainStringChars) {
 					t.Errorf("%s:%s: len(tokens)=%d, len(plainString)=%d for: <%s>",
 						fileName, lineIndex.Format(),
-						len(tokens), utf8.RuneCountInString(plainString), line)
+						len(tokens), len(plainStringChars), line)
 					continue
 				}
 
 				// Tokens and plain have the same lengths, compare contents
-				plainStringChars := []rune(plainString)
 				for index, plainChar := range plainStringChars {
 					cellChar := tokens[index]
-					if cellChar.Rune == plainChar {
+					if string(cellChar.Rune)+cellChar.Combining() == plainChar {
 						continue
 					}
 
-					if cellChar.Rune == '•' && plainChar == 'o' {
+					if cellChar.Rune == '•' && plainChar == "o" {
 						// Pretty bullets on man pages
 						continue
 					}
@@ -108,9 +107,9 @@ func TestTokenize(t *testing.T) {
 					if !twin.Printable(cellChar.Rune) {
 						cellCharString = fmt.Sprint(int(cellChar.Rune))
 					}
-					plainCharString := string(plainChar)
-					if !twin.Printable(plainChar) {
-						plainCharString = fmt.Sprint(int(plainChar))
+					plainCharString := plainChar
+					if !twin.Printable([]rune(plainChar)[0]) {
+						plainCharString = fmt.Sprint([]rune(plainChar))
 					}
 					t.Errorf("%s:%s, 0-based column %d: cell char <%s> != plain char <%s>:\nPlain: %s\nCells: %s\n       %s",
 						fileName, lineIndex.Format(), index,
@@ -327,6 +326,33 @@ func TestHyperlink_incomplete(t *testing.T) {
 	}
 }
 
+// Sixel graphics should end up in a cell of their own, untouched
+func TestSixelPassthrough(t *testing.T) {
+	sixel := "\x1bP0;1;0q\"1;1;2;2#0;2;100;0;0#0~~$-~~\x1b\\"
+
+	tokens := StyledRunesFromString(twin.StyleDefault, "a"+sixel+"b", nil).StyledRunes
+
+	assert.DeepEqual(t, tokens, []CellWithMetadata{
+		{Rune: 'a', Style: twin.StyleDefault},
+		{Rune: '\uFFFC', Style: twin.StyleDefault.WithPassthrough(&sixel)},
+		{Rune: 'b', Style: twin.StyleDefault},
+	},
+		cmp.Comparer(func(a, b CellWithMetadata) bool { return a.Equal(b) }))
+
+	assert.Equal(t, *tokens[1].ToStyledRune().Style.Passthrough(), sixel)
+
+	assert.Equal(t, StripFormatting("a"+sixel+"b", linemetadata.Index{}), "a\uFFFCb")
+}
+
+// Non-sixel DCS sequences should be shown, not swallowed
+func TestUnhandledDcs(t *testing.T) {
+	tokens := StyledRunesFromString(twin.StyleDefault, "\x1bP$qm\x1b\\", nil).StyledRunes
+
+	assert.Equal(t, len(tokens), len("?P$qm?\\"))
+	assert.Equal(t, tokens[1].Rune, 'P')
+	assert.Equal(t, tokens[1].Style.Passthrough() == nil, true)
+}
+
 func TestRawUpdateStyleResetDoesNotAffectHyperlink(t *testing.T) {
 	url := "file:///Users/johan/src/riff/src/refiner.rs"
 	styleWithLink := twin.StyleDefault.WithHyperlink(&url)
@@ -360,3 +386,295 @@ func BenchmarkStripFormatting(b *testing.B) {
 		}
 	}
 }
+
+// Split s into one string per screen cell, see ContinuesGraphemeCluster()
+func graphemeClusters(s string) []string {
+	clusters := make([]string, 0, len(s))
+	clusterStart := 0
+	for index, runeValue := range s {
+		if ContinuesGraphemeCluster(s[clusterStart:index], runeValue) {
+			continue
+		}
+		if index > 0 {
+			clusters = append(clusters, s[clusterStart:index])
+		}
+		clusterStart = index
+	}
+	if len(s) > 0 {
+		clusters = append(clusters, s[clusterStart:])
+	}
+	return clusters
+}
+
+func cellsToString(cells []CellWithMetadata) string {
+	result := ""
+	for _, cell := range cells {
+		result += string(cell.Rune) + cell.Combining()
+	}
+	return result
+}
+
+func TestTabExpansion(t *testing.T) {
+	defer func() { TabSize = 8 }()
+
+	for _, tabSize := range []int{4, 8} {
+		TabSize = tabSize
+		padding := strings.Repeat(" ", tabSize-2)
+
+		cells := StyledRunesFromString(twin.StyleDefault, "ab\tc\td", nil).StyledRunes
+		assert.Equal(t, "ab"+padding+"c"+strings.Repeat(" ", tabSize-1)+"d", cellsToString(cells))
+		assert.Equal(t, 'c', cells[tabSize].Rune, "Tab size %d", tabSize)
+		assert.Equal(t, 'd', cells[2*tabSize].Rune, "Tab size %d", tabSize)
+
+		// Plain text must line up with the cells, search highlighting
+		// depends on that
+		assert.Equal(t, cellsToString(cells), StripFormatting("ab\tc\td", linemetadata.Index{}))
+	}
+}
+
+// Tab cells are marked, for showing tabs when the user asks for that
+func TestTabExpansionMarksTabs(t *testing.T) {
+	cells := StyledRunesFromString(twin.StyleDefault, "a\tb", nil).StyledRunes
+	assert.Equal(t, 9, len(cells))
+
+	assert.Assert(t, !cells[0].IsTab)
+	assert.Assert(t, cells[1].IsTab && cells[1].StartsTab)
+	for _, cell := range cells[2:8] {
+		assert.Assert(t, cell.IsTab && !cell.StartsTab)
+	}
+	assert.Assert(t, !cells[8].IsTab)
+}
+
+// Wide runes take up two screen columns, tab stops should take that into
+// account.
+func TestTabExpansionAfterWideRune(t *testing.T) {
+	defer func() { TabSize = 8 }()
+	TabSize = 4
+
+	cells := StyledRunesFromString(twin.StyleDefault, "午\tx", nil).StyledRunes
+	assert.Equal(t, "午  x", cellsToString(cells))
+
+	assert.Equal(t, "午  x", StripFormatting("午\tx", linemetadata.Index{}))
+}
+
+func TestInvalidUtf8(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+	UnprintableStyle = UnprintableStyleHighlight
+
+	input := string([]byte{'a', 0xE9, 'b'})
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "a?b", cellsToString(cells))
+	assert.Equal(t, "a?b", StripFormatting(input, linemetadata.Index{}))
+}
+
+func TestInvalidUtf8Caret(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+	UnprintableStyle = UnprintableStyleCaret
+
+	input := string([]byte{'a', 0xE9, 'b', 0x03, 'c', 0x7f})
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, `a\xE9b^Cc^?`, cellsToString(cells))
+
+	// Plain text must line up with the cells, search highlighting depends on
+	// that
+	assert.Equal(t, `a\xE9b^Cc^?`, StripFormatting(input, linemetadata.Index{}))
+}
+
+// DEL is plain ASCII, but not printable. Plain text must line up with the
+// cells anyway, search highlighting depends on that.
+func TestDel(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+
+	UnprintableStyle = UnprintableStyleHighlight
+	cells := StyledRunesFromString(twin.StyleDefault, "\x7fbar", nil).StyledRunes
+	assert.Equal(t, "?bar", cellsToString(cells))
+	assert.Equal(t, "?bar", StripFormatting("\x7fbar", linemetadata.Index{}))
+
+	UnprintableStyle = UnprintableStyleCaret
+	cells = StyledRunesFromString(twin.StyleDefault, "\x7fbar", nil).StyledRunes
+	assert.Equal(t, "^?bar", cellsToString(cells))
+	assert.Equal(t, "^?bar", StripFormatting("\x7fbar", linemetadata.Index{}))
+}
+
+// Invalid bytes must survive being passed through the ANSI escape code parser
+func TestInvalidUtf8CaretWithFormatting(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+	UnprintableStyle = UnprintableStyleCaret
+
+	input := "\x1b[1m" + string([]byte{0xFF}) + "\x1b[0mx"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, `\xFFx`, cellsToString(cells))
+	assert.Equal(t, `\xFFx`, StripFormatting(input, linemetadata.Index{}))
+}
+
+// Caret notation takes up more than one column, tab stops should take that
+// into account.
+func TestCaretNotationTabStops(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+	UnprintableStyle = UnprintableStyleCaret
+
+	input := "\x01\tx"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "^A      x", cellsToString(cells))
+	assert.Equal(t, "^A      x", StripFormatting(input, linemetadata.Index{}))
+}
+
+func TestControlPictures(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+	UnprintableStyle = UnprintableStyleSymbols
+
+	cells := StyledRunesFromString(twin.StyleDefault, "ding\x07dong", nil).StyledRunes
+	assert.Equal(t, "ding␇dong", cellsToString(cells))
+	assert.Assert(t, cells[4].Style != twin.StyleDefault, "Control pictures should stand out")
+	assert.Equal(t, twin.StyleDefault, cells[5].Style)
+
+	// Plain text must line up with the cells, search highlighting depends on
+	// that
+	assert.Equal(t, "ding␇dong", StripFormatting("ding\x07dong", linemetadata.Index{}))
+
+	input := string([]byte{0x00, 'a', 0xE9, 0x7f, '\t', 'b'})
+	cells = StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "␀a�␡    b", cellsToString(cells))
+	assert.Equal(t, "␀a�␡    b", StripFormatting(input, linemetadata.Index{}))
+}
+
+// Progress bars overwrite themselves using carriage returns, show what a
+// terminal would end up showing
+func TestCarriageReturnOverwrite(t *testing.T) {
+	cells := StyledRunesFromString(twin.StyleDefault, "10%\r50%\r100%", nil).StyledRunes
+	assert.Equal(t, "100%", cellsToString(cells))
+	assert.Equal(t, "100%", StripFormatting("10%\r50%\r100%", linemetadata.Index{}))
+
+	// Shorter text only overwrites the start of the line
+	cells = StyledRunesFromString(twin.StyleDefault, "abcdef\rXY", nil).StyledRunes
+	assert.Equal(t, "XYcdef", cellsToString(cells))
+	assert.Equal(t, "XYcdef", StripFormatting("abcdef\rXY", linemetadata.Index{}))
+
+	// Each carriage return overwrites what all previous ones left behind
+	cells = StyledRunesFromString(twin.StyleDefault, "abcdef\rXYZ\réQ", nil).StyledRunes
+	assert.Equal(t, "éQZdef", cellsToString(cells))
+	assert.Equal(t, "éQZdef", StripFormatting("abcdef\rXYZ\réQ", linemetadata.Index{}))
+}
+
+// Overwriting must keep styles and tab stops right, and plain text must line up
+// with the cells
+func TestCarriageReturnOverwriteFormatted(t *testing.T) {
+	input := "loading\r\x1b[1mdone\x1b[0m\tx"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "done    x", cellsToString(cells))
+	assert.Equal(t, twin.StyleDefault.WithAttr(twin.AttrBold), cells[0].Style)
+	assert.Equal(t, twin.StyleDefault, cells[8].Style)
+
+	assert.Equal(t, "done    x", StripFormatting(input, linemetadata.Index{}))
+}
+
+func TestCarriageReturnRaw(t *testing.T) {
+	defer func() { CarriageReturnStyle = CarriageReturnStyleOverwrite }()
+	CarriageReturnStyle = CarriageReturnStyleRaw
+
+	cells := StyledRunesFromString(twin.StyleDefault, "10%\r100%", nil).StyledRunes
+	assert.Equal(t, "10%?100%", cellsToString(cells))
+	assert.Equal(t, "10%?100%", StripFormatting("10%\r100%", linemetadata.Index{}))
+}
+
+// "cat -v" shows carriage returns as ^M, and so do we
+func TestCarriageReturnCaret(t *testing.T) {
+	defer func() { UnprintableStyle = UnprintableStyleHighlight }()
+	UnprintableStyle = UnprintableStyleCaret
+
+	cells := StyledRunesFromString(twin.StyleDefault, "10%\r100%", nil).StyledRunes
+	assert.Equal(t, "10%^M100%", cellsToString(cells))
+	assert.Equal(t, "10%^M100%", StripFormatting("10%\r100%", linemetadata.Index{}))
+}
+
+func TestShowEscapes(t *testing.T) {
+	assert.Equal(t, "plain", ShowEscapes("plain"))
+	assert.Equal(t, "^[[31mred^[[m", ShowEscapes("\x1b[31mred\x1b[m"))
+	assert.Equal(t, "a^Ha\tb", ShowEscapes("a\ba\tb"))
+	assert.Equal(t, `\xFFx`, ShowEscapes("\xffx"))
+	assert.Equal(t, "åäö", ShowEscapes("åäö"))
+}
+
+// Combining accents go into the same cell as the letter they modify
+func TestCombiningAccent(t *testing.T) {
+	input := "e\u0301x"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, 2, len(cells))
+	assert.Equal(t, 'e', cells[0].Rune)
+	assert.Equal(t, "\u0301", cells[0].Combining())
+	assert.Equal(t, 1, cells[0].Width())
+	assert.Equal(t, 'x', cells[1].Rune)
+
+	assert.Equal(t, input, StripFormatting(input, linemetadata.Index{}))
+}
+
+// A combining accent takes no screen space, so it must not push tab stops
+// forward
+func TestCombiningAccentTabStops(t *testing.T) {
+	input := "e\u0301\tx"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "e\u0301       x", cellsToString(cells))
+	assert.Equal(t, 9, len(cells))
+
+	assert.Equal(t, "e\u0301       x", StripFormatting(input, linemetadata.Index{}))
+}
+
+func TestWideCharTabStops(t *testing.T) {
+	input := "上午\tx"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "上午    x", cellsToString(cells))
+
+	assert.Equal(t, "上午    x", StripFormatting(input, linemetadata.Index{}))
+}
+
+// Emoji modifiers and zero width joiners glue several runes into one emoji
+func TestEmojiCluster(t *testing.T) {
+	family := "👨\u200d👩\u200d👧"
+	thumbsUp := "👍🏽"
+
+	cells := StyledRunesFromString(twin.StyleDefault, family+thumbsUp+"x", nil).StyledRunes
+	assert.Equal(t, 3, len(cells))
+	assert.Equal(t, family, string(cells[0].Rune)+cells[0].Combining())
+	assert.Equal(t, thumbsUp, string(cells[1].Rune)+cells[1].Combining())
+	assert.Equal(t, 2, cells[1].Width())
+}
+
+// Pairs of regional indicators make flags, but a third one starts a new flag
+func TestFlagCluster(t *testing.T) {
+	sweden := "🇸🇪"
+
+	cells := StyledRunesFromString(twin.StyleDefault, sweden+sweden, nil).StyledRunes
+	assert.Equal(t, 2, len(cells))
+	assert.Equal(t, sweden, string(cells[0].Rune)+cells[0].Combining())
+	assert.Equal(t, sweden, string(cells[1].Rune)+cells[1].Combining())
+}
+
+// A combining accent after a carriage return modifies the overwritten cell
+func TestCombiningAccentAfterCarriageReturn(t *testing.T) {
+	input := "abc\rx\u0301"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "x\u0301bc", cellsToString(cells))
+	assert.Equal(t, "x\u0301bc", StripFormatting(input, linemetadata.Index{}))
+}
+
+// Terminal title sequences must not leave any visible cells behind, nor move
+// the colored text around
+func TestTitleLeavesNoCells(t *testing.T) {
+	input := "\x1b]0;Building...\x07\x1b[31mred\x1b[m plain"
+
+	cells := StyledRunesFromString(twin.StyleDefault, input, nil).StyledRunes
+	assert.Equal(t, "red plain", cellsToString(cells))
+	assert.Equal(t, twin.NewColor16(1), cells[0].Style.Foreground())
+	assert.Equal(t, twin.ColorDefault, cells[3].Style.Foreground())
+
+	assert.Equal(t, "red plain", StripFormatting(input, linemetadata.Index{}))
+}
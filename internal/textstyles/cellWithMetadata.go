@@ -2,24 +2,36 @@ package textstyles
 
 import (
 	"unicode"
+	"unicode/utf8"
 
+	"github.com/rivo/uniseg"
 	"github.com/walles/moor/v2/twin"
 )
 
 // Like twin.StyledRune, but with additional metadata
+//
+// The flags go right after Rune so that they all fit in one word. We make lots
+// of these, so keeping them small makes a difference.
 type CellWithMetadata struct {
-	Rune  rune
-	Style twin.Style
-
-	cachedWidth *int
+	Rune rune
 
 	StartsSearchHit bool // True if this cell is the start of a search hit
 	IsSearchHit     bool // True if this cell is part of a search hit
+
+	StartsTab bool // True if this cell is the first space of an expanded tab
+	IsTab     bool // True if this cell is part of an expanded tab
+
+	Style twin.Style
+
+	// See twin.StyledRune.Combining()
+	combining *string
+
+	cachedWidth *int
 }
 
 // Required for some tests to pass
 func (r CellWithMetadata) Equal(b CellWithMetadata) bool {
-	if r.Rune != b.Rune {
+	if r.Rune != b.Rune || r.Combining() != b.Combining() {
 		return false
 	}
 
@@ -35,14 +47,35 @@ func (r CellWithMetadata) Equal(b CellWithMetadata) bool {
 		return false
 	}
 
+	if r.StartsTab != b.StartsTab {
+		return false
+	}
+
+	if r.IsTab != b.IsTab {
+		return false
+	}
+
 	return true
 }
 
 func (r CellWithMetadata) ToStyledRune() twin.StyledRune {
-	return twin.NewStyledRune(r.Rune, r.Style)
+	return twin.NewStyledRune(r.Rune, r.Style).WithCombining(r.Combining())
+}
+
+// See twin.StyledRune.Combining()
+func (r CellWithMetadata) Combining() string {
+	if r.combining == nil {
+		return ""
+	}
+	return *r.combining
 }
 
 func (r *CellWithMetadata) Width() int {
+	if r.combining == nil && r.Rune >= ' ' && r.Rune < 0x7f {
+		// Printable ASCII, no need to ask uniseg or cache anything
+		return 1
+	}
+
 	if r.cachedWidth != nil {
 		return *r.cachedWidth
 	}
@@ -53,6 +86,84 @@ func (r *CellWithMetadata) Width() int {
 	return w
 }
 
+// True if runeValue would become part of this cell's grapheme cluster, see
+// ContinuesGraphemeCluster()
+func (r *CellWithMetadata) continuedBy(runeValue rune) bool {
+	last := r.Rune
+	if r.combining != nil {
+		last, _ = utf8.DecodeLastRuneInString(*r.combining)
+	}
+	if !mayContinueGraphemeCluster(last, runeValue) {
+		return false
+	}
+
+	return ContinuesGraphemeCluster(string(r.Rune)+r.Combining(), runeValue)
+}
+
+// Add a rune to this cell's grapheme cluster, see ContinuesGraphemeCluster()
+func (r *CellWithMetadata) combine(runeValue rune) {
+	combining := r.Combining() + string(runeValue)
+	r.combining = &combining
+	r.cachedWidth = nil
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// Cheap check for whether runeValue could continue a grapheme cluster ending
+// with previous. Asking uniseg is expensive, so we only do that if this
+// returns true.
+func mayContinueGraphemeCluster(previous rune, runeValue rune) bool {
+	if runeValue < 0x300 {
+		// 0x300 is where the combining diacritical marks start. Before that,
+		// only some rare prefix characters can join with what comes next.
+		// Checking for those isn't worth the performance hit.
+		return false
+	}
+
+	if previous == zeroWidthJoiner || runeValue == zeroWidthJoiner {
+		// Emoji sequences like 👨‍👩‍👧
+		return true
+	}
+
+	if runeValue >= 0x1F3FB && runeValue <= 0x1F3FF {
+		// Emoji skin tone modifiers
+		return true
+	}
+
+	if runeValue >= 0x1F1E6 && runeValue <= 0x1F1FF {
+		// Regional indicators, pairs of these make flags
+		return previous >= 0x1F1E6 && previous <= 0x1F1FF
+	}
+
+	if runeValue >= 0xE0020 && runeValue <= 0xE007F {
+		// Tags, used for flags like 🏴󠁧󠁢󠁳󠁣󠁴󠁿
+		return true
+	}
+
+	// Combining marks, including variation selectors
+	return unicode.Is(unicode.M, runeValue)
+}
+
+// True if runeValue would become part of cluster rather than starting a new
+// one. Combining accents, emoji skin tone modifiers and zero width joiners do
+// that for example.
+//
+// Clusters take up one screen cell each, so all column math depends on this.
+func ContinuesGraphemeCluster(cluster string, runeValue rune) bool {
+	if cluster == "" || runeValue < 0x300 {
+		// See mayContinueGraphemeCluster(), this check is cheaper than
+		// decoding the last rune of the cluster
+		return false
+	}
+
+	previous, _ := utf8.DecodeLastRuneInString(cluster)
+	if !mayContinueGraphemeCluster(previous, runeValue) {
+		return false
+	}
+
+	return uniseg.GraphemeClusterCount(cluster+string(runeValue)) == 1
+}
+
 type CellWithMetadataSlice []CellWithMetadata
 
 func (runes CellWithMetadataSlice) Equal(other CellWithMetadataSlice) bool {
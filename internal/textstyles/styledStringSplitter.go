@@ -2,6 +2,7 @@ package textstyles
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"unicode/utf8"
 
@@ -105,6 +106,10 @@ func (s *styledStringSplitter) run() {
 				char = s.lastChar()
 				continue
 			}
+		} else if char == utf8.RuneError && s.nextByteIndex-s.previousByteIndex == 1 {
+			// Invalid UTF-8, pass the raw byte on so that it can be rendered
+			// properly later
+			s.inProgressString.WriteByte(s.input[s.previousByteIndex])
 		} else {
 			s.handleRune(char)
 		}
@@ -134,6 +139,11 @@ func (s *styledStringSplitter) handleEscape() error {
 		return s.consumeG0Charset()
 	}
 
+	if char == 'P' {
+		// Got the start of a DCS sequence
+		return s.consumeDcs()
+	}
+
 	return fmt.Errorf("Unhandled Fe sequence ESC%c", char)
 }
 
@@ -245,6 +255,66 @@ func (s *styledStringSplitter) consumeOsc() error {
 	}
 }
 
+// Consume a DCS sequence up until it ends. Sixel graphics are the only DCS
+// sequences we support, and those get passed through to the terminal as a
+// single cell.
+func (s *styledStringSplitter) consumeDcs() error {
+	// Points to the ESC in "ESCP"
+	startIndex := s.previousByteIndex - 1
+
+	// Sixel sequences start with some optional numeric parameters followed by
+	// a 'q': https://vt100.net/docs/vt3xx-gp/chapter14.html
+	for {
+		char := s.nextChar()
+		if char == -1 {
+			return fmt.Errorf("Line ended in the middle of a DCS sequence")
+		}
+
+		if char == 'q' {
+			break
+		}
+
+		if (char >= '0' && char <= '9') || char == ';' {
+			continue
+		}
+
+		return fmt.Errorf("Unhandled DCS type %q", char)
+	}
+
+	for {
+		char := s.nextChar()
+		if char == -1 {
+			return fmt.Errorf("Line ended in the middle of a sixel sequence")
+		}
+
+		if char != esc {
+			continue
+		}
+
+		afterEsc := s.nextChar()
+		if afterEsc == -1 {
+			return fmt.Errorf("Line ended while ending a sixel sequence")
+		}
+		if afterEsc != '\\' {
+			return fmt.Errorf("Expected sixel sequence to end with ESC \\ but got ESC %q", afterEsc)
+		}
+
+		sixel := s.input[startIndex:s.nextByteIndex]
+		s.handlePassthrough(sixel)
+		return nil
+	}
+}
+
+// Put sequence in a cell of its own, for twin to pass on to the terminal.
+// U+FFFC is the OBJECT REPLACEMENT CHARACTER, which is what will show up in
+// the plain text version of the line.
+func (s *styledStringSplitter) handlePassthrough(sequence string) {
+	style := s.inProgressStyle
+	s.startNewPart(style.WithPassthrough(&sequence))
+	s.handleRune('\uFFFC')
+	s.startNewPart(style)
+}
+
 // Expects an OSC sequence as argument. The terminator is not included, what we
 // get here is just the payload.
 func (s *styledStringSplitter) handleOsc(sequence string) error {
@@ -255,6 +325,14 @@ func (s *styledStringSplitter) handleOsc(sequence string) error {
 		return nil
 	}
 
+	if oscNumber, _, found := strings.Cut(sequence, ";"); found && slices.Contains([]string{"0", "1", "2", "7"}, oscNumber) {
+		// Window / icon titles and the current directory. These are for the
+		// terminal, but we're a pager, so just ignore them.
+		//
+		// Ref: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+		return nil
+	}
+
 	if strings.HasSuffix(sequence, "?") {
 		// OSC query, we don't intend to answer those, just ignore them.
 		//
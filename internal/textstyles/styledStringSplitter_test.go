@@ -69,6 +69,24 @@ func TestIgnoreQueries(t *testing.T) {
 	assert.Equal(t, twin.StyleDefault, styledStrings[0].Style)
 }
 
+// Window titles are for terminals, not for pagers, ignore them
+func TestIgnoreTitles(t *testing.T) {
+	styledStrings, trailer := collectStyledStrings("\x1b]0;make: building\x07hello")
+	assert.Equal(t, twin.StyleDefault, trailer)
+	assert.Equal(t, 1, len(styledStrings))
+	assert.Equal(t, "hello", styledStrings[0].String)
+
+	// Icon name + window title, ending with ST rather than BEL
+	styledStrings, _ = collectStyledStrings("hel\x1b]2;vim README.md\x1b\\lo")
+	assert.Equal(t, 1, len(styledStrings))
+	assert.Equal(t, "hello", styledStrings[0].String)
+
+	// Current directory notification
+	styledStrings, _ = collectStyledStrings("\x1b]7;file://host/home/johan\x07hello")
+	assert.Equal(t, 1, len(styledStrings))
+	assert.Equal(t, "hello", styledStrings[0].String)
+}
+
 // Unsure why colon separated colors exist, but the fact is that a number of
 // things emit colon separated SGR codes. And numerous terminals accept them
 // (search page for "delimiter"): https://github.com/kovidgoyal/kitty/issues/7
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/twin"
+)
+
+// User configured UI colors, overriding the ones we get from the Chroma style
+// and LESS_TERMCAP_xx. Nil colors are left alone.
+//
+// Load using LoadTheme().
+type Theme struct {
+	StatusBarForeground *twin.Color
+	StatusBarBackground *twin.Color
+
+	SearchHitForeground *twin.Color
+	SearchHitBackground *twin.Color
+
+	// Replaces the reverse video or subtle background otherwise used for
+	// Pager.HighlightCurrentLine
+	CurrentLineBackground *twin.Color
+
+	LineNumbersForeground *twin.Color
+	LineNumbersBackground *twin.Color
+}
+
+// Theme file names for each Theme field
+func (t *Theme) colorsByName() map[string]**twin.Color {
+	return map[string]**twin.Color{
+		"statusbar-fg":    &t.StatusBarForeground,
+		"statusbar-bg":    &t.StatusBarBackground,
+		"search-hit-fg":   &t.SearchHitForeground,
+		"search-hit-bg":   &t.SearchHitBackground,
+		"current-line-bg": &t.CurrentLineBackground,
+		"linenumbers-fg":  &t.LineNumbersForeground,
+		"linenumbers-bg":  &t.LineNumbersBackground,
+	}
+}
+
+// Parse a text with one "name color" pair per line, like "statusbar-bg 214".
+// Empty lines and lines starting with # are ignored. See twin.ParseColor() for
+// what colors can look like.
+func ParseTheme(text string) (Theme, error) {
+	theme := Theme{}
+	colors := theme.colorsByName()
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return Theme{}, fmt.Errorf("Line %d: Expected \"name color\", got <%s>", lineNumber, line)
+		}
+
+		updateMe, found := colors[fields[0]]
+		if !found {
+			names := []string{}
+			for name := range colors {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return Theme{}, fmt.Errorf("Line %d: Unknown name <%s>, expected one of: %s", lineNumber, fields[0], strings.Join(names, ", "))
+		}
+
+		color, err := twin.ParseColor(fields[1])
+		if err != nil {
+			return Theme{}, fmt.Errorf("Line %d: %w", lineNumber, err)
+		}
+		*updateMe = &color
+	}
+
+	return theme, scanner.Err()
+}
+
+// Load a theme from a file. An empty file name means the moor/theme file in the
+// XDG config directory, and no theme if that doesn't exist.
+func LoadTheme(fileName string) (Theme, error) {
+	if fileName == "" {
+		xdgPath, err := xdg.SearchConfigFile("moor/theme")
+		if err != nil {
+			// No theme file, not a problem
+			log.Debug("No theme file found, using default colors: ", err)
+			return Theme{}, nil
+		}
+		fileName = xdgPath
+	}
+
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	theme, err := ParseTheme(string(contents))
+	if err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	log.Debug("Theme loaded from ", fileName)
+	return theme, nil
+}
+
+// The search hit style from this theme, or nil if the theme doesn't say
+// anything about search hits. Like with Pager.SearchHitStyle, setting only a
+// background keeps the colors of the hit text.
+func (t Theme) searchHitStyle() *twin.Style {
+	if t.SearchHitForeground == nil && t.SearchHitBackground == nil {
+		return nil
+	}
+
+	style := twin.StyleDefault
+	if t.SearchHitForeground != nil {
+		style = style.WithForeground(*t.SearchHitForeground)
+	}
+	if t.SearchHitBackground != nil {
+		style = style.WithBackground(*t.SearchHitBackground)
+	}
+	return &style
+}
+
+// Replace one or both colors of style. With reverse video, the colors swap
+// places on screen, so we swap them here as well.
+func withThemeColors(style twin.Style, fg *twin.Color, bg *twin.Color) twin.Style {
+	if style.HasAttr(twin.AttrReverse) {
+		fg, bg = bg, fg
+	}
+
+	if fg != nil {
+		style = style.WithForeground(*fg)
+	}
+	if bg != nil {
+		style = style.WithBackground(*bg)
+	}
+	return style
+}
+
+// Apply the theme's colors on top of what styleUI() came up with. Search hit
+// colors are handled through customSearchHitStyle, since other colors are
+// derived from those in configureHighlighting().
+func (t Theme) apply() {
+	statusbarStyle = withThemeColors(statusbarStyle, t.StatusBarForeground, t.StatusBarBackground)
+	lineNumbersStyle = withThemeColors(lineNumbersStyle, t.LineNumbersForeground, t.LineNumbersBackground)
+
+	if t.CurrentLineBackground != nil {
+		currentLineBackground = t.CurrentLineBackground
+	}
+}
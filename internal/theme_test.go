@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseTheme(t *testing.T) {
+	theme, err := ParseTheme("# Comment\n\nstatusbar-fg red\nstatusbar-bg #336699\nlinenumbers-fg 244\n")
+	assert.NilError(t, err)
+
+	assert.Equal(t, twin.NewColor16(1), *theme.StatusBarForeground)
+	assert.Equal(t, twin.NewColorHex(0x336699), *theme.StatusBarBackground)
+	assert.Equal(t, twin.NewColor256(244), *theme.LineNumbersForeground)
+	assert.Assert(t, theme.SearchHitBackground == nil)
+	assert.Assert(t, theme.CurrentLineBackground == nil)
+}
+
+func TestParseThemeUnknownName(t *testing.T) {
+	_, err := ParseTheme("statusbar-fg red\nstatusbar-color blue")
+	assert.ErrorContains(t, err, "Line 2: Unknown name <statusbar-color>")
+}
+
+func TestParseThemeBadColor(t *testing.T) {
+	_, err := ParseTheme("search-hit-bg #12345")
+	assert.ErrorContains(t, err, "Line 1: Expected #rrggbb hex color, got <#12345>")
+}
+
+func TestParseThemeBadLine(t *testing.T) {
+	_, err := ParseTheme("statusbar-fg")
+	assert.ErrorContains(t, err, "Line 1: Expected \"name color\"")
+}
+
+func TestLoadTheme(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "theme")
+	assert.NilError(t, os.WriteFile(fileName, []byte("current-line-bg 236\n"), 0o600))
+
+	theme, err := LoadTheme(fileName)
+	assert.NilError(t, err)
+	assert.Equal(t, twin.NewColor256(236), *theme.CurrentLineBackground)
+
+	assert.NilError(t, os.WriteFile(fileName, []byte("current-line-bg nope\n"), 0o600))
+	_, err = LoadTheme(fileName)
+	assert.ErrorContains(t, err, fileName+": Line 1: Unknown color <nope>")
+}
+
+func startPagingWithTheme(t *testing.T, theme Theme) *twin.FakeScreen {
+	status, lineNumbers, hit, lineBackground := statusbarStyle, lineNumbersStyle, searchHitStyle, currentLineBackground
+	t.Cleanup(func() {
+		statusbarStyle = status
+		lineNumbersStyle = lineNumbers
+		searchHitStyle = hit
+		currentLineBackground = lineBackground
+		customSearchHitStyle = nil
+	})
+
+	reader := reader.NewFromTextForTesting("", "one\ntwo")
+	assert.NilError(t, reader.Wait())
+
+	screen := twin.NewFakeScreen(20, 5)
+	pager := NewPager(reader)
+	pager.Theme = theme
+
+	// Tell our Pager to quit immediately
+	pager.Quit()
+
+	pager.StartPaging(screen, styles.Get("native"), &formatters.TTY16m)
+	pager.redraw("")
+
+	return screen
+}
+
+func TestThemeStatusBarColors(t *testing.T) {
+	red := twin.NewColor16(1)
+	blue := twin.NewColorHex(0x336699)
+	screen := startPagingWithTheme(t, Theme{StatusBarForeground: &red, StatusBarBackground: &blue})
+
+	assert.Assert(t, strings.HasPrefix(rowToString(screen.GetRow(4)), "2 lines"), rowToString(screen.GetRow(4)))
+	statusBarCell := screen.GetRow(4)[0]
+
+	// The default status bar is in reverse video, so the colors swap places
+	style := statusBarCell.Style
+	assert.Assert(t, style.HasAttr(twin.AttrReverse))
+	assert.Equal(t, blue, style.Foreground())
+	assert.Equal(t, red, style.Background())
+}
+
+func TestThemeLineNumberColors(t *testing.T) {
+	gray := twin.NewColor256(244)
+	screen := startPagingWithTheme(t, Theme{LineNumbersForeground: &gray})
+
+	assert.Equal(t, "  1 one", rowToString(screen.GetRow(0)))
+	assert.Equal(t, gray, screen.GetRow(0)[2].Style.Foreground())
+}
+
+func TestThemeSearchHitColors(t *testing.T) {
+	yellow := twin.NewColor16(3)
+	startPagingWithTheme(t, Theme{SearchHitBackground: &yellow})
+
+	assert.Equal(t, twin.StyleDefault.WithBackground(yellow), searchHitStyle)
+}
+
+func TestThemeWithoutColors(t *testing.T) {
+	assert.Assert(t, Theme{}.searchHitStyle() == nil)
+
+	// Nothing configured, nothing changed
+	style := twin.StyleDefault.WithAttr(twin.AttrReverse)
+	assert.Equal(t, style, withThemeColors(style, nil, nil))
+}
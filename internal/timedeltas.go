@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/internal/textstyles"
+)
+
+// Timestamp layouts tried when Pager.TimestampFormats is empty, in order. More
+// specific layouts go first, so that time zones aren't left over as extra text.
+var defaultTimestampFormats = []string{
+	"2006-01-02T15:04:05Z07:00", // ISO 8601 / RFC 3339
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"Jan _2 15:04:05", // syslog
+	"15:04:05",
+}
+
+// Width of the time deltas gutter, "+59m59s" plus a space
+const timeDeltasWidth = 8
+
+// Don't look further back than this for a previous timestamp, so that lines
+// far below the last timestamp don't make rendering slow
+const timeDeltasLookback = 100
+
+// Parse a timestamp at the start of line, ignoring any opening bracket before
+// it and any closing bracket or colon after it. Layouts are like for
+// time.Parse(), and fractional seconds are accepted even if the layout doesn't
+// mention them.
+func parseTimestamp(line string, layouts []string) (time.Time, bool) {
+	line = strings.TrimPrefix(line, "[")
+
+	// Timestamps are at the start of the line, don't split up all of it
+	fields := strings.Fields(line[:min(len(line), 100)])
+
+	for _, layout := range layouts {
+		layoutFields := strings.Fields(layout)
+		if len(fields) < len(layoutFields) {
+			continue
+		}
+
+		// Joining the fields means runs of whitespace don't matter. Syslog
+		// pads single digit days with an extra space for example.
+		candidate := strings.Join(fields[:len(layoutFields)], " ")
+		candidate = strings.TrimRight(candidate, "]:")
+		timestamp, err := time.Parse(strings.Join(layoutFields, " "), candidate)
+		if err == nil {
+			return timestamp, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Something like "+1.2s" or "+350ms". Negative deltas get a "-" instead of a
+// "+".
+func formatTimeDelta(delta time.Duration) string {
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+
+	switch {
+	case delta < time.Second:
+		return fmt.Sprintf("%s%dms", sign, delta.Milliseconds())
+	case delta < 10*time.Second:
+		return fmt.Sprintf("%s%.1fs", sign, delta.Seconds())
+	case delta < time.Minute:
+		return fmt.Sprintf("%s%ds", sign, int(delta.Seconds()))
+	case delta < time.Hour:
+		return fmt.Sprintf("%s%dm%02ds", sign, int(delta.Minutes()), int(delta.Seconds())%60)
+	case delta < 24*time.Hour:
+		return fmt.Sprintf("%s%dh%02dm", sign, int(delta.Hours()), int(delta.Minutes())%60)
+	}
+
+	return fmt.Sprintf("%s%dd%02dh", sign, int(delta.Hours())/24, int(delta.Hours())%24)
+}
+
+func (p *Pager) timestampFormats() []string {
+	if len(p.TimestampFormats) > 0 {
+		return p.TimestampFormats
+	}
+	return defaultTimestampFormats
+}
+
+// The time since the closest earlier line with a timestamp, or "" if this line
+// has no timestamp or there is no such earlier line.
+func (p *Pager) timeDeltaText(line reader.NumberedLine) string {
+	timestamp, found := parseTimestamp(line.Plain(), p.timestampFormats())
+	if !found {
+		return ""
+	}
+
+	r := p.Reader()
+	for index := line.Index.Index() - 1; index >= max(0, line.Index.Index()-timeDeltasLookback); index-- {
+		previous := r.GetLine(linemetadata.IndexFromZeroBased(index))
+		if previous == nil {
+			return ""
+		}
+
+		previousTimestamp, found := parseTimestamp(previous.Plain(), p.timestampFormats())
+		if found {
+			return formatTimeDelta(timestamp.Sub(previousTimestamp))
+		}
+	}
+
+	return ""
+}
+
+// Zero if the time deltas gutter is hidden
+func (p *Pager) timeDeltasWidth() int {
+	if !p.ShowTimeDeltas {
+		return 0
+	}
+	return timeDeltasWidth
+}
+
+// Right aligned delta followed by a space, or all spaces for an empty delta
+func createTimeDeltaPrefix(delta string, width int) []textstyles.CellWithMetadata {
+	prefix := make([]textstyles.CellWithMetadata, 0, width)
+	for _, char := range fmt.Sprintf("%*s ", width-1, delta) {
+		if len(prefix) >= width {
+			break
+		}
+		prefix = append(prefix, textstyles.CellWithMetadata{Rune: char, Style: lineNumbersStyle})
+	}
+	return prefix
+}
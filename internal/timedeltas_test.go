@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+var timestampedLog = []string{
+	"2025-03-01T12:00:00.000Z starting",
+	"2025-03-01T12:00:00.350Z config loaded",
+	"    at some.stack.Trace(Trace.java:42)",
+	"2025-03-01T12:00:01.550Z connected",
+	"2025-03-01T12:00:45.000Z slow query done",
+	"2025-03-01T12:03:15.000Z idle",
+}
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Date(2025, 3, 1, 12, 0, 0, 350_000_000, time.UTC)
+
+	for _, line := range []string{
+		"2025-03-01T12:00:00.350Z config loaded",
+		"2025-03-01T13:00:00.350+01:00 config loaded",
+		"2025-03-01 12:00:00,350 INFO config loaded",
+		"[2025-03-01 12:00:00.350] config loaded",
+	} {
+		timestamp, found := parseTimestamp(line, defaultTimestampFormats)
+		assert.Assert(t, found, line)
+		assert.Assert(t, timestamp.Equal(expected), "%s: %s", line, timestamp)
+	}
+
+	// Syslog, with the day padded by an extra space
+	timestamp, found := parseTimestamp("Mar  1 12:00:00 myhost sshd[42]: hello", defaultTimestampFormats)
+	assert.Assert(t, found)
+	assert.Equal(t, time.March, timestamp.Month())
+	assert.Equal(t, 1, timestamp.Day())
+
+	_, found = parseTimestamp("    at some.stack.Trace(Trace.java:42)", defaultTimestampFormats)
+	assert.Assert(t, !found)
+	_, found = parseTimestamp("", defaultTimestampFormats)
+	assert.Assert(t, !found)
+}
+
+func TestParseTimestampCustomFormat(t *testing.T) {
+	timestamp, found := parseTimestamp("01/03/2025 12:00:05 hello", []string{"02/01/2006 15:04:05"})
+	assert.Assert(t, found)
+	assert.Assert(t, timestamp.Equal(time.Date(2025, 3, 1, 12, 0, 5, 0, time.UTC)))
+
+	// Defaults don't apply with a custom format
+	_, found = parseTimestamp("2025-03-01T12:00:00Z hello", []string{"02/01/2006 15:04:05"})
+	assert.Assert(t, !found)
+}
+
+func TestFormatTimeDelta(t *testing.T) {
+	assert.Equal(t, "+0ms", formatTimeDelta(0))
+	assert.Equal(t, "+350ms", formatTimeDelta(350*time.Millisecond))
+	assert.Equal(t, "+1.2s", formatTimeDelta(1200*time.Millisecond))
+	assert.Equal(t, "+43s", formatTimeDelta(43450*time.Millisecond))
+	assert.Equal(t, "+2m30s", formatTimeDelta(150*time.Second))
+	assert.Equal(t, "+1h05m", formatTimeDelta(65*time.Minute))
+	assert.Equal(t, "+2d03h", formatTimeDelta(51*time.Hour))
+	assert.Equal(t, "-1.5s", formatTimeDelta(-1500*time.Millisecond))
+}
+
+func TestTimeDeltas(t *testing.T) {
+	pager := createLinesPager(t, 50, 8, timestampedLog...)
+	pager.ShowTimeDeltas = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "        2025-03-01T12:00:00.000Z starting", rows[0])
+	assert.Equal(t, " +350ms 2025-03-01T12:00:00.350Z config loaded", rows[1])
+	assert.Equal(t, "            at some.stack.Trace(Trace.java:42)", rows[2])
+
+	// Compared to the line before the stack trace line
+	assert.Equal(t, "  +1.2s 2025-03-01T12:00:01.550Z connected", rows[3])
+
+	assert.Equal(t, "   +43s 2025-03-01T12:00:45.000Z slow query done", rows[4])
+	assert.Equal(t, " +2m30s 2025-03-01T12:03:15.000Z idle", rows[5])
+}
+
+// The previous timestamp may be above the screen
+func TestTimeDeltasScrolled(t *testing.T) {
+	pager := createLinesPager(t, 50, 3, timestampedLog...)
+	pager.ShowTimeDeltas = true
+	pager.scrollPosition = NewScrollPositionFromIndex(pager.lineIndex().NonWrappingAdd(3), "TestTimeDeltasScrolled")
+
+	assert.Equal(t, "  +1.2s 2025-03-01T12:00:01.550Z connected", screenRows(pager)[0])
+}
+
+func TestTimeDeltasWithLineNumbers(t *testing.T) {
+	pager := createLinesPager(t, 50, 8, timestampedLog...)
+	pager.ShowTimeDeltas = true
+	pager.showLineNumbers = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "          1 2025-03-01T12:00:00.000Z starting", rows[0])
+	assert.Equal(t, " +350ms   2 2025-03-01T12:00:00.350Z config loaded", rows[1])
+}
+
+func TestTimeDeltasWrapped(t *testing.T) {
+	pager := createLinesPager(t, 34, 8, timestampedLog[:2]...)
+	pager.ShowTimeDeltas = true
+	pager.WrapLongLines = true
+
+	rows := screenRows(pager)
+	assert.Equal(t, "        2025-03-01T12:00:00.000Z", rows[0])
+	assert.Equal(t, "        starting", rows[1])
+	assert.Equal(t, " +350ms 2025-03-01T12:00:00.350Z", rows[2])
+	assert.Equal(t, "        config loaded", rows[3])
+}
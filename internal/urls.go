@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Bare http:// and https:// URLs. Trailing punctuation is left out, since that
+// is more likely to end a sentence than a URL. Parentheses are only included
+// in pairs, so that "(see https://example.com)" works.
+var urlPattern = regexp.MustCompile(`https?://(?:[^\s<>"'()]|\([^\s<>"'()]*\))*(?:[^\s<>"'().,;:!?\]}]|\([^\s<>"'()]*\))`)
+
+// Start and end byte offsets of all URLs in a line of plain text
+func findUrls(plain string) [][2]int {
+	urls := [][2]int{}
+	for _, match := range urlPattern.FindAllStringIndex(plain, -1) {
+		urls = append(urls, [2]int{match[0], match[1]})
+	}
+	return urls
+}
+
+// All different URLs on screen, from the top
+func (p *Pager) visibleUrls() []string {
+	urls := []string{}
+	seen := map[string]bool{}
+	for _, line := range p.renderLines().inputLines {
+		for _, url := range urlPattern.FindAllString(line.Plain(), -1) {
+			if seen[url] {
+				continue
+			}
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+func urlStyle() twin.Style {
+	return plainTextStyle.WithAttr(twin.AttrUnderline)
+}
+
+// URLs are underlined with HighlightUrls, and the one the user is about to
+// open stands out. Nil if there is nothing to highlight.
+//
+// This is called for every line we render, so it must be cheap.
+func (p *Pager) urlHighlights() []reader.PatternHighlight {
+	if openUrl, ok := p.mode.(PagerModeOpenUrl); ok {
+		return openUrl.highlights
+	}
+
+	if !p.HighlightUrls {
+		return nil
+	}
+
+	if p.urlUnderlines == nil {
+		p.urlUnderlines = []reader.PatternHighlight{{
+			Pattern: urlPattern,
+			Style:   urlStyle(),
+		}}
+	}
+	return p.urlUnderlines
+}
+
+// How to open a URL in the default browser on this platform
+func openUrlCommand(url string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"open", url}
+	case "windows":
+		return []string{"rundll32", "url.dll,FileProtocolHandler", url}
+	}
+	return []string{"xdg-open", url}
+}
+
+// Opens a URL in the browser without waiting for it. A var rather than a func
+// so that tests can replace it.
+var urlOpener = func(url string) error {
+	commandWithArgs := openUrlCommand(url)
+	command := exec.Command(commandWithArgs[0], commandWithArgs[1:]...)
+	err := command.Start()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		// Don't leave zombies around
+		err := command.Wait()
+		if err != nil {
+			log.Info("Opening URL failed: ", commandWithArgs, ": ", err)
+		}
+	}()
+	return nil
+}
+
+// Open a URL in the browser, and tell the user how it went
+func (p *Pager) openUrl(url string) {
+	if os.Getenv("LESSSECURE") == "1" {
+		p.setMessage("Not opening URL since LESSSECURE=1 is set in the environment")
+		return
+	}
+
+	log.Info("Opening URL: ", url)
+	err := urlOpener(url)
+	if err != nil {
+		log.Warn("Failed to open URL ", url, ": ", err)
+		p.setMessage("Failed to open " + url + ": " + err.Error())
+		return
+	}
+
+	p.setMessage("Opened " + url)
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/v2/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestFindUrls(t *testing.T) {
+	line := "GET https://example.com/a?b=c from http://localhost:8080/ ok"
+	urls := findUrls(line)
+	assert.DeepEqual(t, [][2]int{{4, 29}, {35, 57}}, urls)
+	assert.Equal(t, "https://example.com/a?b=c", line[urls[0][0]:urls[0][1]])
+	assert.Equal(t, "http://localhost:8080/", line[urls[1][0]:urls[1][1]])
+
+	assert.DeepEqual(t, [][2]int{}, findUrls("no links here, not even ftp://example.com"))
+}
+
+func TestFindUrlsPunctuation(t *testing.T) {
+	assertUrl := func(line string, expected string) {
+		t.Helper()
+		urls := findUrls(line)
+		assert.Equal(t, 1, len(urls), line)
+		assert.Equal(t, expected, line[urls[0][0]:urls[0][1]], line)
+	}
+
+	// Sentence punctuation is not part of the URL
+	assertUrl("See https://example.com.", "https://example.com")
+	assertUrl("(see https://example.com)", "https://example.com")
+	assertUrl("<https://example.com>", "https://example.com")
+	assertUrl(`href="https://example.com/x"`, "https://example.com/x")
+
+	// Pairs of parentheses are
+	assertUrl("https://en.wikipedia.org/wiki/Moor_(pager), nice",
+		"https://en.wikipedia.org/wiki/Moor_(pager)")
+}
+
+func TestOpenUrl(t *testing.T) {
+	opened := []string{}
+	defer func(original func(string) error) { urlOpener = original }(urlOpener)
+	urlOpener = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	pager := createLinesPager(t, 40, 5,
+		"one https://a.example and http://b.example",
+		"no urls",
+		"again https://a.example, then https://c.example")
+
+	pager.mode.onRune('o')
+	assert.Equal(t, "OpenUrl", modeName(pager))
+	assert.Equal(t, "https://a.example", pager.mode.(PagerModeOpenUrl).urls[0])
+
+	// Two on the first line, then the repeated one is skipped
+	pager.mode.onRune('o')
+	pager.mode.onRune('o')
+	assert.Equal(t, 2, pager.mode.(PagerModeOpenUrl).index)
+
+	pager.mode.onKey(twin.KeyEnter)
+	assert.DeepEqual(t, []string{"https://c.example"}, opened)
+	assert.Equal(t, "Info", modeName(pager), "Should say what was opened")
+
+	// Cycling wraps around, and anything else cancels
+	pager.mode = PagerModeViewing{pager: pager}
+	pager.mode.onRune('o')
+	for range 3 {
+		pager.mode.onRune('o')
+	}
+	assert.Equal(t, 0, pager.mode.(PagerModeOpenUrl).index)
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, 1, len(opened))
+}
+
+func TestOpenUrlNoUrls(t *testing.T) {
+	pager := createLinesPager(t, 40, 5, "nothing to see here")
+
+	pager.mode.onRune('o')
+	assert.Equal(t, "Info", modeName(pager))
+}
+
+func TestOpenUrlLessSecure(t *testing.T) {
+	t.Setenv("LESSSECURE", "1")
+	defer func(original func(string) error) { urlOpener = original }(urlOpener)
+	urlOpener = func(url string) error {
+		t.Fatal("Should not open anything with LESSSECURE=1")
+		return nil
+	}
+
+	pager := createLinesPager(t, 40, 5, "https://example.com")
+	pager.mode.onRune('o')
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, "Info", modeName(pager))
+}
+
+func TestHighlightUrls(t *testing.T) {
+	pager := createLinesPager(t, 40, 5, "see https://example.com now")
+	assert.Equal(t, "", underlinedCells(pager, 0), "URLs should not be underlined by default")
+
+	pager.HighlightUrls = true
+	assert.Equal(t, "    https://example.com", underlinedCells(pager, 0))
+}
+
+// Rendering must not compile any regexps, and nothing to highlight should
+// mean no highlights at all
+func TestUrlHighlightsCached(t *testing.T) {
+	pager := createLinesPager(t, 40, 5, "https://a.example https://b.example")
+	assert.Assert(t, pager.urlHighlights() == nil)
+
+	pager.HighlightUrls = true
+	assert.Assert(t, &pager.urlHighlights()[0] == &pager.urlHighlights()[0])
+
+	pager.mode.onRune('o')
+	first := pager.urlHighlights()[0].Pattern
+	assert.Assert(t, first == pager.urlHighlights()[0].Pattern)
+
+	pager.mode.onRune('o')
+	assert.Equal(t, "https://b\\.example", pager.urlHighlights()[0].Pattern.String())
+}
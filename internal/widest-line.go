@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"math"
+
+	"github.com/walles/moor/v2/internal/linemetadata"
+)
+
+// The first of the widest lines in the input, by display width. Returns nil if
+// there are no lines.
+func (p *Pager) findWidestLine() *linemetadata.Index {
+	var widest *linemetadata.Index
+	widestWidth := -1
+	for _, line := range p.Reader().GetLines(linemetadata.Index{}, math.MaxInt).Lines {
+		width := line.DisplayWidth()
+		if width > widestWidth {
+			index := line.Index
+			widest = &index
+			widestWidth = width
+		}
+	}
+
+	return widest
+}
+
+// Put the widest line in the input at the top of the screen, for finding out
+// how wide things get before scrolling sideways
+func (p *Pager) scrollToWidestLine() {
+	widest := p.findWidestLine()
+	if widest == nil {
+		p.setMessage("No lines")
+		return
+	}
+
+	current := p.lineIndex()
+	if current != nil && *widest == *current {
+		// Already there
+		return
+	}
+
+	p.rememberPosition()
+	p.scrollPosition = NewScrollPositionFromIndex(*widest, "scrollToWidestLine")
+	if current == nil || widest.Index() > current.Index() {
+		p.handleScrolledDown()
+	} else {
+		p.handleScrolledUp()
+	}
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestScrollToWidestLine(t *testing.T) {
+	pager := createLinesPager(t, 20, 3,
+		"short",
+		"a bit longer",
+		"the longest line by far",
+		"medium length",
+		"also the longest line!!",
+		"end")
+
+	pager.mode.onRune('W')
+	assert.Equal(t, 2, pager.lineIndex().Index(), "Should land on the first of the widest lines")
+	assert.Equal(t, "the longest line by>", screenRows(pager)[0])
+
+	// Already there, nothing should happen
+	pager.mode.onRune('W')
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
+
+// Tabs and wide characters count by the screen columns they take up
+func TestScrollToWidestLineDisplayWidth(t *testing.T) {
+	pager := createLinesPager(t, 20, 3,
+		"123456789012",
+		"a\tb",
+		"日本語日本語日本",
+		"end")
+
+	pager.scrollToWidestLine()
+	assert.Equal(t, 2, pager.lineIndex().Index())
+}
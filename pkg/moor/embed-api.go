@@ -143,8 +143,12 @@ func dumpToStdoutAndClose(reader io.Reader) error {
 }
 
 func getColorFormatter() chroma.Formatter {
-	if os.Getenv("COLORTERM") != "truecolor" && strings.Contains(os.Getenv("TERM"), "256") {
-		// Covers "xterm-256color" as used by the macOS Terminal
+	switch twin.DetectColorCount() {
+	case twin.ColorCount8:
+		return formatters.TTY8
+	case twin.ColorCount16:
+		return formatters.TTY16
+	case twin.ColorCount256:
 		return formatters.TTY256
 	}
 	return formatters.TTY16m
@@ -0,0 +1,133 @@
+package moor
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/walles/moor/v2/internal"
+	internalReader "github.com/walles/moor/v2/internal/reader"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Options for rendering input to strings rather than paging it.
+type RenderOptions struct {
+	// Size of the imaginary screen to render to, both must be positive
+	Width  int
+	Height int
+
+	// The default is to auto format JSON input. Set this to true to disable
+	// auto formatting.
+	NoAutoFormat bool
+
+	// The default is to truncate long lines. Set this to true to wrap them
+	// instead.
+	WrapLongLines bool
+
+	// The default is to show line numbers. Set this to true to disable line
+	// numbers.
+	NoLineNumbers bool
+
+	// If set, hits for this pattern are highlighted, just like when searching
+	// while paging.
+	SearchPattern *regexp.Regexp
+}
+
+// Render the first screenful of the stream the way the pager would show it,
+// without needing a terminal. There is one string per screen row, formatted
+// using ANSI escape codes. No status bar is rendered.
+func RenderFromStream(reader io.Reader, options RenderOptions) ([]string, error) {
+	logs := startLogCollection()
+	defer collectLogs(logs)
+
+	renderReader, err := newRenderReader(reader, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return rowsToStrings(internal.RenderRows(renderReader, options.toInternal())), nil
+}
+
+// Like RenderFromStream(), but for a string
+func RenderFromString(text string, options RenderOptions) ([]string, error) {
+	return RenderFromStream(strings.NewReader(text), options)
+}
+
+// Create a reader for rendering, and wait for it to finish reading
+func newRenderReader(reader io.Reader, options RenderOptions) (*internalReader.ReaderImpl, error) {
+	if options.Width < 1 || options.Height < 1 {
+		return nil, fmt.Errorf("Render size must be positive, got %dx%d", options.Width, options.Height)
+	}
+
+	renderReader, err := internalReader.NewFromStream(
+		"",
+		reader,
+		formatters.TTY16m,
+		internalReader.ReaderOptions{
+			ShouldFormat: !options.NoAutoFormat,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// There's no terminal to ask for its background color, so this gives us
+	// the default theme
+	style := internal.GetStyleForScreen(twin.NewFakeScreen(options.Width, options.Height))
+	renderReader.SetStyleForHighlighting(style)
+
+	err = renderReader.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return renderReader, nil
+}
+
+func (options RenderOptions) toInternal() internal.RenderOptions {
+	return internal.RenderOptions{
+		Width:           options.Width,
+		Height:          options.Height,
+		WrapLongLines:   options.WrapLongLines,
+		ShowLineNumbers: !options.NoLineNumbers,
+		SearchPattern:   options.SearchPattern,
+	}
+}
+
+func rowsToStrings(rows [][]twin.StyledRune) []string {
+	rendered := make([]string, 0, len(rows))
+	for _, row := range rows {
+		rendered = append(rendered, rowToString(row))
+	}
+	return rendered
+}
+
+// Turn a row of cells into a string with ANSI escape codes. Trailing
+// unstyled whitespace is dropped.
+func rowToString(row []twin.StyledRune) string {
+	for len(row) > 0 {
+		last := row[len(row)-1]
+		if last.Rune != ' ' || last.Style != twin.StyleDefault {
+			break
+		}
+		row = row[:len(row)-1]
+	}
+
+	var builder strings.Builder
+	lastStyle := twin.StyleDefault
+	for _, cell := range row {
+		if cell.Style != lastStyle {
+			builder.WriteString(cell.Style.RenderUpdateFrom(lastStyle, twin.ColorCount24bit))
+			lastStyle = cell.Style
+		}
+		builder.WriteRune(cell.Rune)
+		builder.WriteString(cell.Combining())
+	}
+
+	if lastStyle != twin.StyleDefault {
+		builder.WriteString(twin.StyleDefault.RenderUpdateFrom(lastStyle, twin.ColorCount24bit))
+	}
+
+	return builder.String()
+}
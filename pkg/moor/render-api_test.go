@@ -0,0 +1,61 @@
+package moor
+
+// NOTE: No imports from internal allowed here!! Externals cannot do that, so if
+// we have to that means the whole external API is broken.
+import (
+	"regexp"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRenderFromString(t *testing.T) {
+	rows, err := RenderFromString("first\nsecond\nthird\n", RenderOptions{
+		Width:         10,
+		Height:        2,
+		NoLineNumbers: true,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"first", "second"}, rows)
+}
+
+func TestRenderFromStringWrapped(t *testing.T) {
+	rows, err := RenderFromString("hello world", RenderOptions{
+		Width:         6,
+		Height:        5,
+		NoLineNumbers: true,
+		WrapLongLines: true,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"hello", "world"}, rows)
+}
+
+func TestRenderFromStringSearchHit(t *testing.T) {
+	rows, err := RenderFromString("hello world", RenderOptions{
+		Width:         20,
+		Height:        1,
+		NoLineNumbers: true,
+		SearchPattern: regexp.MustCompile("wor"),
+	})
+	assert.NilError(t, err)
+
+	// Search hits are shown in reverse video by default
+	assert.DeepEqual(t, []string{"hello \x1b[7mwor\x1b[mld"}, rows)
+}
+
+// Combining accents go into the same cell as what they combine with, but
+// should still be part of the output
+func TestRenderFromStringCombining(t *testing.T) {
+	rows, err := RenderFromString("e\u0301x", RenderOptions{
+		Width:         10,
+		Height:        1,
+		NoLineNumbers: true,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"e\u0301x"}, rows)
+}
+
+func TestRenderFromStringBadSize(t *testing.T) {
+	_, err := RenderFromString("hello", RenderOptions{Width: 0, Height: 5})
+	assert.ErrorContains(t, err, "size")
+}
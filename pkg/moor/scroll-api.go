@@ -0,0 +1,77 @@
+package moor
+
+import (
+	"io"
+	"strings"
+
+	"github.com/walles/moor/v2/internal"
+)
+
+// Scroller scrolls through input the way the pager would, but without needing
+// a terminal. Useful for testing how some input looks when paged, or for
+// building tools on top of moor's line wrapping.
+//
+// Create using NewScrollerFromStream() or NewScrollerFromString().
+type Scroller struct {
+	scroller *internal.Scroller
+}
+
+// Read all of the stream and set up a Scroller showing the start of it. The
+// options' Width and Height are the initial screen size.
+func NewScrollerFromStream(reader io.Reader, options RenderOptions) (*Scroller, error) {
+	logs := startLogCollection()
+	defer collectLogs(logs)
+
+	scrollerReader, err := newRenderReader(reader, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scroller{
+		scroller: internal.NewScroller(scrollerReader, options.toInternal()),
+	}, nil
+}
+
+// Like NewScrollerFromStream(), but for a string
+func NewScrollerFromString(text string, options RenderOptions) (*Scroller, error) {
+	return NewScrollerFromStream(strings.NewReader(text), options)
+}
+
+// Change the size of the imaginary screen, both must be positive. The top line
+// stays the same if possible.
+func (s *Scroller) Resize(width int, height int) {
+	s.scroller.Resize(width, height)
+}
+
+// Scroll this many screen lines down, negative means up. With wrapping
+// enabled, one input line can be multiple screen lines.
+//
+// Scrolling stops at the start and at the end of the input.
+func (s *Scroller) Scroll(lines int) {
+	s.scroller.Scroll(lines)
+}
+
+func (s *Scroller) ScrollToStart() {
+	s.scroller.ScrollToStart()
+}
+
+// Scroll so that the last line of input is at the bottom of the screen
+func (s *Scroller) ScrollToEnd() {
+	s.scroller.ScrollToEnd()
+}
+
+// The one based line number of the input line at the top of the screen, or 0
+// if there is no input
+func (s *Scroller) TopLineNumber() int {
+	index := s.scroller.TopLine()
+	if index == nil {
+		return 0
+	}
+	return index.Index() + 1
+}
+
+// What's on screen, one string per screen row, formatted like
+// RenderFromStream() does it
+func (s *Scroller) VisibleLines() []string {
+	return rowsToStrings(s.scroller.Rows())
+}
@@ -0,0 +1,52 @@
+package moor
+
+// NOTE: No imports from internal allowed here!! Externals cannot do that, so if
+// we have to that means the whole external API is broken.
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestScroller(t *testing.T) {
+	scroller, err := NewScrollerFromString("a\nb\nc\nd\n", RenderOptions{
+		Width:         10,
+		Height:        2,
+		NoLineNumbers: true,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, scroller.TopLineNumber())
+	assert.DeepEqual(t, []string{"a", "b"}, scroller.VisibleLines())
+
+	scroller.Scroll(1)
+	assert.Equal(t, 2, scroller.TopLineNumber())
+	assert.DeepEqual(t, []string{"b", "c"}, scroller.VisibleLines())
+
+	scroller.Scroll(10)
+	assert.Equal(t, 3, scroller.TopLineNumber())
+	assert.DeepEqual(t, []string{"c", "d"}, scroller.VisibleLines())
+
+	scroller.Scroll(-10)
+	assert.Equal(t, 1, scroller.TopLineNumber())
+}
+
+func TestScrollerWrapped(t *testing.T) {
+	scroller, err := NewScrollerFromString("hello big world", RenderOptions{
+		Width:         6,
+		Height:        1,
+		NoLineNumbers: true,
+		WrapLongLines: true,
+	})
+	assert.NilError(t, err)
+
+	scroller.ScrollToEnd()
+	assert.DeepEqual(t, []string{"world"}, scroller.VisibleLines())
+
+	scroller.Resize(20, 1)
+	assert.DeepEqual(t, []string{"hello big world"}, scroller.VisibleLines())
+}
+
+func TestScrollerBadSize(t *testing.T) {
+	_, err := NewScrollerFromString("hello", RenderOptions{Width: 5, Height: 0})
+	assert.ErrorContains(t, err, "size")
+}
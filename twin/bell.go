@@ -0,0 +1,31 @@
+package twin
+
+// Implemented by screens that can ring the terminal bell. Check for it with a
+// type assertion.
+type Beller interface {
+	Bell()
+}
+
+// Implemented by screens that can invert themselves, as a visual bell. Check
+// for it with a type assertion.
+type Flasher interface {
+	// Invert the screen, or pass false to make it normal again. Flashing is up
+	// to the caller, since we don't want to block while the screen is
+	// inverted.
+	Flash(inverted bool)
+}
+
+func (screen *UnixScreen) Bell() {
+	screen.write("\a")
+}
+
+// Invert the screen using DECSCNM. Terminals that don't support that will just
+// not flash.
+func (screen *UnixScreen) Flash(inverted bool) {
+	screen.inverted = inverted
+	if inverted {
+		screen.write("\x1b[?5h")
+	} else {
+		screen.write("\x1b[?5l")
+	}
+}
@@ -0,0 +1,49 @@
+package twin
+
+import (
+	"encoding/base64"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Implemented by screens that can put text on the system clipboard. Check for
+// it with a type assertion.
+type Clipboarder interface {
+	// Put some text on the system clipboard. Returns false if the terminal
+	// is known not to support this, in which case you need to find some other
+	// way.
+	CopyToClipboard(text string) bool
+}
+
+// Encode text for putting on the system clipboard.
+//
+// Ref: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func osc52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+}
+
+// Some terminals silently ignore OSC 52, we need to know so that we can try
+// something else for those.
+func terminalSupportsOsc52() bool {
+	if os.Getenv("TERM_PROGRAM") == "Apple_Terminal" {
+		return false
+	}
+
+	if os.Getenv("VTE_VERSION") != "" {
+		// Gnome Terminal and friends
+		return false
+	}
+
+	return true
+}
+
+func (screen *UnixScreen) CopyToClipboard(text string) bool {
+	if !terminalSupportsOsc52() {
+		log.Debug("Terminal is not known to support OSC 52, not copying through it")
+		return false
+	}
+
+	screen.write(osc52(text))
+	return true
+}
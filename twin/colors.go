@@ -3,6 +3,8 @@ package twin
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 
 	"github.com/alecthomas/chroma/v2"
 )
@@ -33,6 +35,10 @@ const (
 	ColorCount24bit
 )
 
+// As a terminal color count, this means no colors at all, only attributes like
+// bold and reverse. See https://no-color.org/.
+const ColorCountNone = ColorCountDefault
+
 type colorType uint8
 
 const (
@@ -86,6 +92,45 @@ func NewColorHex(rgb uint32) Color {
 	return newColor(ColorCount24bit, rgb)
 }
 
+// Color names accepted by ParseColor(), in NewColor16() order
+var colorNames = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright-black", "bright-red", "bright-green", "bright-yellow",
+	"bright-blue", "bright-magenta", "bright-cyan", "bright-white",
+}
+
+// Parse a color like "red", "bright-blue", "#ff8800", "214" (a 256 color
+// index) or "default".
+func ParseColor(colorString string) (Color, error) {
+	if colorString == "default" {
+		return ColorDefault, nil
+	}
+
+	for i, name := range colorNames {
+		if colorString == name {
+			return NewColor16(i), nil
+		}
+	}
+
+	if strings.HasPrefix(colorString, "#") {
+		hex := colorString[1:]
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return ColorDefault, fmt.Errorf("Expected #rrggbb hex color, got <%s>", colorString)
+		}
+		return NewColorHex(uint32(rgb)), nil
+	}
+
+	index, err := strconv.ParseUint(colorString, 10, 8)
+	if err == nil {
+		return NewColor256(uint8(index)), nil
+	}
+
+	return ColorDefault, fmt.Errorf(
+		"Unknown color <%s>, expected #rrggbb, 0-255, default or one of: %s",
+		colorString, strings.Join(colorNames, ", "))
+}
+
 func (color Color) ColorCount() ColorCount {
 	return ColorCount(color >> 24)
 }
@@ -114,7 +159,7 @@ func (color Color) ansiString(cType colorType, terminalColorCount ColorCount) st
 		return fmt.Sprint("\x1b[", typeMarker, "9m")
 	}
 
-	color = color.downsampleTo(terminalColorCount)
+	color = color.Downsample(terminalColorCount)
 
 	// We never create any ColorCount8 colors, but we store them as
 	// ColorCount16. So this if() statement will cover both.
@@ -191,9 +236,20 @@ func (color Color) to24Bit() Color {
 	panic(fmt.Errorf("unhandled color type %d", color.ColorCount()))
 }
 
-func (color Color) downsampleTo(terminalColorCount ColorCount) Color {
-	if color.ColorCount() == ColorCountDefault || terminalColorCount == ColorCountDefault {
-		panic(fmt.Errorf("downsampling to or from default color not supported, %s -> %#v", color.String(), terminalColorCount))
+// Convert this color into the closest one available on a terminal supporting
+// the given number of colors. Colors the terminal can already show, including
+// the default color, are returned unchanged.
+//
+// Closeness is measured using Distance(), which tries to match how humans
+// perceive colors.
+func (color Color) Downsample(terminalColorCount ColorCount) Color {
+	if terminalColorCount == ColorCountDefault {
+		panic(fmt.Errorf("downsampling to default color not supported, %s -> %#v", color.String(), terminalColorCount))
+	}
+
+	if color.ColorCount() == ColorCountDefault {
+		// Every terminal can show the default color
+		return color
 	}
 
 	if color.ColorCount() <= terminalColorCount {
@@ -9,14 +9,14 @@ import (
 
 func TestDownsample24BitsTo16Colors(t *testing.T) {
 	assert.Equal(t,
-		NewColor24Bit(255, 255, 255).downsampleTo(ColorCount16),
+		NewColor24Bit(255, 255, 255).Downsample(ColorCount16),
 		NewColor16(15),
 	)
 }
 
 func TestDownsample24BitsTo256Colors(t *testing.T) {
 	assert.Equal(t,
-		NewColor24Bit(255, 255, 255).downsampleTo(ColorCount256),
+		NewColor24Bit(255, 255, 255).Downsample(ColorCount256),
 
 		// From https://jonasjacek.github.io/colors/
 		NewColor256(231),
@@ -25,11 +25,46 @@ func TestDownsample24BitsTo256Colors(t *testing.T) {
 
 func TestRealWorldDownsampling(t *testing.T) {
 	assert.Equal(t,
-		NewColor24Bit(0xd0, 0xd0, 0xd0).downsampleTo(ColorCount256),
+		NewColor24Bit(0xd0, 0xd0, 0xd0).Downsample(ColorCount256),
 		NewColor256(252), // From https://jonasjacek.github.io/colors/
 	)
 }
 
+// Expected values from https://jonasjacek.github.io/colors/
+func TestDownsampleKnownColorsTo256Colors(t *testing.T) {
+	for _, testCase := range []struct {
+		rgb      Color
+		expected uint8
+	}{
+		{NewColor24Bit(0xff, 0x00, 0x00), 196},
+		{NewColor24Bit(0x00, 0xff, 0x00), 46},
+		{NewColor24Bit(0x00, 0x00, 0xff), 21},
+		{NewColor24Bit(0xff, 0x87, 0x00), 208},
+		{NewColor24Bit(0x80, 0x80, 0x80), 244},
+		{NewColor24Bit(0x00, 0x00, 0x00), 16},
+
+		// Not exactly in the palette
+		{NewColor24Bit(0xfe, 0x01, 0x02), 196},
+		{NewColor24Bit(0x81, 0x7f, 0x80), 244},
+	} {
+		assert.Equal(t, testCase.rgb.Downsample(ColorCount256), NewColor256(testCase.expected), testCase.rgb.String())
+	}
+}
+
+func TestDownsample256ColorsTo16Colors(t *testing.T) {
+	// With the VGA palette, 0xff0000 is closer to red (0xaa0000) than to bright
+	// red (0xff5555)
+	assert.Equal(t, NewColor256(196).Downsample(ColorCount16), NewColor16(1))
+	assert.Equal(t, NewColor256(231).Downsample(ColorCount16), NewColor16(15))
+	assert.Equal(t, NewColor256(16).Downsample(ColorCount16), NewColor16(0))
+}
+
+func TestDownsampleNotNeeded(t *testing.T) {
+	assert.Equal(t, NewColor16(3).Downsample(ColorCount256), NewColor16(3))
+	assert.Equal(t, NewColor256(200).Downsample(ColorCount24bit), NewColor256(200))
+	assert.Equal(t, ColorDefault.Downsample(ColorCount8), ColorDefault)
+}
+
 func TestAnsiStringWithDownSampling(t *testing.T) {
 	actual := NewColor24Bit(0xd0, 0xd0, 0xd0).ansiString(colorTypeForeground, ColorCount256)
 	actual = strings.ReplaceAll(actual, "\x1b", "ESC")
@@ -63,3 +98,39 @@ func TestDistance(t *testing.T) {
 		1.0,
 	)
 }
+
+func TestParseColor(t *testing.T) {
+	color, err := ParseColor("red")
+	assert.NilError(t, err)
+	assert.Equal(t, NewColor16(1), color)
+
+	color, err = ParseColor("bright-white")
+	assert.NilError(t, err)
+	assert.Equal(t, NewColor16(15), color)
+
+	color, err = ParseColor("#ff8800")
+	assert.NilError(t, err)
+	assert.Equal(t, NewColor24Bit(0xff, 0x88, 0x00), color)
+
+	color, err = ParseColor("214")
+	assert.NilError(t, err)
+	assert.Equal(t, NewColor256(214), color)
+
+	color, err = ParseColor("default")
+	assert.NilError(t, err)
+	assert.Equal(t, ColorDefault, color)
+}
+
+func TestParseColorInvalid(t *testing.T) {
+	_, err := ParseColor("reddish")
+	assert.ErrorContains(t, err, "Unknown color <reddish>")
+
+	_, err = ParseColor("256")
+	assert.ErrorContains(t, err, "Unknown color <256>")
+
+	_, err = ParseColor("#f80")
+	assert.ErrorContains(t, err, "Expected #rrggbb hex color, got <#f80>")
+
+	_, err = ParseColor("#gg0000")
+	assert.ErrorContains(t, err, "Expected #rrggbb hex color, got <#gg0000>")
+}
@@ -19,10 +19,17 @@ const (
 	MouseWheelDown
 	MouseWheelLeft
 	MouseWheelRight
+	MouseButtonPrimary
+	MouseButtonMiddle
+	MouseButtonSecondary
 )
 
 type EventMouse struct {
 	buttons MouseButtonMask
+
+	// Zero based screen position of the mouse pointer
+	x int
+	y int
 }
 
 // After you get this, query Screen.Size() to get the new size
@@ -49,3 +56,13 @@ func (eventKeyCode *EventKeyCode) KeyCode() KeyCode {
 func (eventMouse *EventMouse) Buttons() MouseButtonMask {
 	return eventMouse.buttons
 }
+
+// Zero based screen position of the mouse pointer
+func (eventMouse *EventMouse) Position() (x int, y int) {
+	return eventMouse.x, eventMouse.y
+}
+
+// For testing mouse event handling without a terminal
+func NewEventMouse(buttons MouseButtonMask, x int, y int) EventMouse {
+	return EventMouse{buttons: buttons, x: x, y: y}
+}
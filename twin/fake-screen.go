@@ -7,6 +7,12 @@ type FakeScreen struct {
 	width  int
 	height int
 	cells  [][]StyledRune
+
+	clipboard string
+
+	bellCount  int
+	flashCount int
+	inverted   bool
 }
 
 func NewFakeScreen(width int, height int) *FakeScreen {
@@ -88,6 +94,10 @@ func (screen *FakeScreen) TerminalBackground() *Color {
 	return nil
 }
 
+func (screen *FakeScreen) ColorCount() ColorCount {
+	return ColorCount24bit
+}
+
 func (screen *FakeScreen) ShowCursorAt(_ int, _ int) {
 	// This method intentionally left blank
 }
@@ -97,6 +107,42 @@ func (screen *FakeScreen) Events() chan Event {
 	return nil
 }
 
+func (screen *FakeScreen) CopyToClipboard(text string) bool {
+	screen.clipboard = text
+	return true
+}
+
+// Whatever was last sent to CopyToClipboard()
+func (screen *FakeScreen) GetClipboard() string {
+	return screen.clipboard
+}
+
+func (screen *FakeScreen) Bell() {
+	screen.bellCount++
+}
+
+func (screen *FakeScreen) Flash(inverted bool) {
+	if inverted {
+		screen.flashCount++
+	}
+	screen.inverted = inverted
+}
+
+// How many times Bell() has been called
+func (screen *FakeScreen) GetBellCount() int {
+	return screen.bellCount
+}
+
+// How many times Flash() has inverted the screen
+func (screen *FakeScreen) GetFlashCount() int {
+	return screen.flashCount
+}
+
+// True if the last Flash() call inverted the screen
+func (screen *FakeScreen) IsInverted() bool {
+	return screen.inverted
+}
+
 func (screen *FakeScreen) GetRow(row int) []StyledRune {
 	return withoutHiddenRunes(screen.cells[row])
 }
@@ -134,25 +134,11 @@ func (screen *UnixScreen) setupSigwinchNotification() {
 }
 
 func (screen *UnixScreen) setupTtyInTtyOut() error {
-	// Dup stdout so we can close stdin in Close() without closing stdout.
-	// Before this dupping, we crashed on using --quit-if-one-screen.
-	//
-	// Ref:https://github.com/walles/moor/issues/214
-	stdoutDupFd, err := syscall.Dup(int(os.Stdout.Fd()))
+	var err error
+	screen.ttyIn, err = openTtyIn(os.Stdout, isTerminal)
 	if err != nil {
 		return err
 	}
-	stdoutDup := os.NewFile(uintptr(stdoutDupFd), "moor-stdout-dup")
-
-	// os.Stdout is a stream that goes to our terminal window.
-	//
-	// So if we read from there, we'll get input from the terminal window.
-	//
-	// If we just read from os.Stdin that would fail when getting data piped
-	// into ourselves from some other command.
-	//
-	// Tested on macOS and Linux, works like a charm!
-	screen.ttyIn = stdoutDup // <- YES, WE SHOULD ASSIGN STDOUT TO TTYIN
 
 	// Set input stream to raw mode
 	screen.oldTerminalState, err = term.MakeRaw(int(screen.ttyIn.Fd()))
@@ -177,6 +163,42 @@ func (screen *UnixScreen) setupTtyInTtyOut() error {
 	return nil
 }
 
+// Find somewhere to read keyboard input from. Stdin won't do, since that is
+// where our input comes from when we're being piped into.
+//
+// isTerminal is a parameter so that tests can pretend to have or not have a
+// terminal.
+func openTtyIn(stdout *os.File, isTerminal func(*os.File) bool) (*os.File, error) {
+	if !isTerminal(stdout) {
+		// moor prints its input rather than paging it in this case, so this
+		// should never happen
+		return nil, fmt.Errorf("no terminal to read keyboard input from, stdout is not a terminal")
+	}
+
+	// Dup stdout so we can close stdin in Close() without closing stdout.
+	// Before this dupping, we crashed on using --quit-if-one-screen.
+	//
+	// Ref:https://github.com/walles/moor/issues/214
+	stdoutDupFd, err := syscall.Dup(int(stdout.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	// os.Stdout is a stream that goes to our terminal window.
+	//
+	// So if we read from there, we'll get input from the terminal window.
+	//
+	// If we just read from os.Stdin that would fail when getting data piped
+	// into ourselves from some other command.
+	//
+	// Tested on macOS and Linux, works like a charm!
+	return os.NewFile(uintptr(stdoutDupFd), "moor-stdout-dup"), nil // <- YES, WE SHOULD USE STDOUT FOR TTYIN
+}
+
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
 func (screen *UnixScreen) restoreTtyInTtyOut() error {
 	return term.Restore(int(screen.ttyIn.Fd()), screen.oldTerminalState)
 }
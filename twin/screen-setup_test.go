@@ -68,3 +68,31 @@ func TestInterruptableReader_blockedOnReadImmediate(t *testing.T) {
 	assert.Equal(t, err, io.EOF)
 	assert.Equal(t, n, 0)
 }
+
+func TestOpenTtyIn_stdoutIsTerminal(t *testing.T) {
+	// A pipe standing in for stdout
+	pipeReader, stdout, err := os.Pipe()
+	assert.NilError(t, err)
+	defer func() {
+		_ = pipeReader.Close()
+		_ = stdout.Close()
+	}()
+
+	ttyIn, err := openTtyIn(stdout, func(file *os.File) bool { return true })
+	assert.NilError(t, err)
+	defer func() { _ = ttyIn.Close() }()
+	assert.Equal(t, ttyIn.Name(), "moor-stdout-dup")
+}
+
+func TestOpenTtyIn_noTerminal(t *testing.T) {
+	// A pipe standing in for stdout
+	pipeReader, stdout, err := os.Pipe()
+	assert.NilError(t, err)
+	defer func() {
+		_ = pipeReader.Close()
+		_ = stdout.Close()
+	}()
+
+	_, err = openTtyIn(stdout, func(file *os.File) bool { return false })
+	assert.ErrorContains(t, err, "no terminal to read keyboard input from")
+}
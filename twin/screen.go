@@ -73,6 +73,13 @@ type Screen interface {
 	Events() chan Event
 }
 
+// Implemented by screens that know how many colors they render using. Check
+// for it with a type assertion.
+type ColorCounter interface {
+	// ColorCountNone means we render attributes only
+	ColorCount() ColorCount
+}
+
 type interruptableReader interface {
 	Read(p []byte) (n int, err error)
 
@@ -113,17 +120,33 @@ type UnixScreen struct {
 	oldTtyOutMode uint32 //nolint Windows only
 
 	terminalColorCount ColorCount
+
+	// True if Flash() has inverted the screen
+	inverted bool
 }
 
-// Example event: "\x1b[<65;127;41M"
+// Example event: "\x1b[<64;127;41M"
 //
 // Where:
 //   - "\x1b[<" says this is a mouse event
-//   - "65" says this is Wheel Up. "64" would be Wheel Down.
+//   - "64" says this is Wheel Up. "65" would be Wheel Down.
 //   - "127" is the column number on screen, "1" is the first column.
 //   - "41" is the row number on screen, "1" is the first row.
-//   - "M" marks the end of the mouse event.
-var mouseEventRegex = regexp.MustCompile("^\x1b\\[<([0-9]+);([0-9]+);([0-9]+)M")
+//   - "M" marks the end of the mouse event. "m" would mean a button release.
+//
+// Ref: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-Extended-coordinates
+var mouseEventRegex = regexp.MustCompile("^\x1b\\[<([0-9]+);([0-9]+);([0-9]+)([Mm])")
+
+// SGR mouse button codes, with the modifier bits masked out
+var mouseCodeToButtons = map[int]MouseButtonMask{
+	0:  MouseButtonPrimary,
+	1:  MouseButtonMiddle,
+	2:  MouseButtonSecondary,
+	64: MouseWheelUp,
+	65: MouseWheelDown,
+	66: MouseWheelLeft,
+	67: MouseWheelRight,
+}
 
 // NewScreen() requires Close() to be called after you are done with your new
 // screen, most likely somewhere in your shutdown code.
@@ -132,12 +155,44 @@ func NewScreen() (Screen, error) {
 }
 
 func NewScreenWithMouseMode(mouseMode MouseMode) (Screen, error) {
-	terminalColorCount := ColorCount24bit
-	if os.Getenv("COLORTERM") != "truecolor" && strings.Contains(os.Getenv("TERM"), "256") {
+	return NewScreenWithMouseModeAndColorCount(mouseMode, DetectColorCount())
+}
+
+// Guess how many colors the terminal supports based on $COLORTERM and $TERM.
+//
+// If $NO_COLOR is set we return ColorCountNone, unless $FORCE_COLOR is also
+// set. Ref: https://no-color.org/ and https://force-color.org/
+//
+// If we can't tell, we assume 24 bit colors are supported. Most modern
+// terminals do, and many of them don't say so.
+func DetectColorCount() ColorCount {
+	if os.Getenv("NO_COLOR") != "" && os.Getenv("FORCE_COLOR") == "" {
+		return ColorCountNone
+	}
+
+	colorTerm := os.Getenv("COLORTERM")
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return ColorCount24bit
+	}
+
+	term := os.Getenv("TERM")
+	if strings.HasSuffix(term, "-direct") {
+		// Like "xterm-direct"
+		return ColorCount24bit
+	}
+	if strings.Contains(term, "256") {
 		// Covers "xterm-256color" as used by the macOS Terminal
-		terminalColorCount = ColorCount256
+		return ColorCount256
+	}
+	if term == "linux" || strings.HasSuffix(term, "-16color") {
+		// "linux" is the Linux console
+		return ColorCount16
+	}
+	if strings.HasSuffix(term, "-8color") {
+		return ColorCount8
 	}
-	return NewScreenWithMouseModeAndColorCount(mouseMode, terminalColorCount)
+
+	return ColorCount24bit
 }
 
 func NewScreenWithMouseModeAndColorCount(mouseMode MouseMode, terminalColorCount ColorCount) (Screen, error) {
@@ -222,6 +277,9 @@ func (screen *UnixScreen) Close() {
 	// Tell our main loop to exit
 	screen.ttyInReader.Interrupt()
 
+	if screen.inverted {
+		screen.Flash(false)
+	}
 	screen.hideCursor(false)
 	screen.enableMouseTracking(false)
 	screen.setAlternateScreenMode(false)
@@ -523,8 +581,8 @@ func (screen *UnixScreen) mainLoop() {
 			event, encodedKeyCodeSequences = consumeEncodedEvent(encodedKeyCodeSequences)
 
 			if event == nil {
-				// No event, go wait for more
-				break
+				// Nothing to post, go for the remainder (if any)
+				continue
 			}
 
 			// Post the event
@@ -554,6 +612,39 @@ func humanizeLowASCII(withLowAsciis string) string {
 	return humanized
 }
 
+// Turn a mouseEventRegex match into an event. Returns nil for events we don't
+// care about, like button releases and mouse movements.
+func decodeMouseEvent(mouseMatch []string) *Event {
+	if mouseMatch[4] == "m" {
+		// Button released
+		return nil
+	}
+
+	code, err := strconv.Atoi(mouseMatch[1])
+	if err != nil {
+		log.Debug("Unparsable mouse event code: ", mouseMatch[1])
+		return nil
+	}
+
+	// Ignore the Shift, Meta and Control modifier bits
+	code &^= 4 | 8 | 16
+
+	buttons, found := mouseCodeToButtons[code]
+	if !found {
+		// Mouse movements have the 32 bit set and end up here
+		log.Trace("Unhandled mouse event code: ", code)
+		return nil
+	}
+
+	// SGR coordinates are one based, ours are zero based. The regexp only
+	// accepts digits, so these conversions can only fail on overflow.
+	x, _ := strconv.Atoi(mouseMatch[2])
+	y, _ := strconv.Atoi(mouseMatch[3])
+
+	var event Event = EventMouse{buttons: buttons, x: x - 1, y: y - 1}
+	return &event
+}
+
 // Consume initial key code from the sequence of encoded keycodes.
 //
 // Returns a (possibly nil) event that should be posted, and the remainder of
@@ -571,20 +662,7 @@ func consumeEncodedEvent(encodedEventSequences string) (*Event, string) {
 
 	mouseMatch := mouseEventRegex.FindStringSubmatch(encodedEventSequences)
 	if mouseMatch != nil {
-		if mouseMatch[1] == "64" {
-			var event Event = EventMouse{buttons: MouseWheelUp}
-			return &event, strings.TrimPrefix(encodedEventSequences, mouseMatch[0])
-		}
-		if mouseMatch[1] == "65" {
-			var event Event = EventMouse{buttons: MouseWheelDown}
-			return &event, strings.TrimPrefix(encodedEventSequences, mouseMatch[0])
-		}
-
-		log.Debug(
-			"Unhandled multi character mouse escape sequence(s): {",
-			humanizeLowASCII(encodedEventSequences),
-			"}")
-		return nil, ""
+		return decodeMouseEvent(mouseMatch), strings.TrimPrefix(encodedEventSequences, mouseMatch[0])
 	}
 
 	// No escape sequence prefix matched
@@ -667,6 +745,10 @@ func (screen *UnixScreen) Size() (width int, height int) {
 	return screen.widthAccessFromSizeOnly, screen.heightAccessFromSizeOnly
 }
 
+func (screen *UnixScreen) ColorCount() ColorCount {
+	return screen.terminalColorCount
+}
+
 // The first time you call this, there may be a delay of up to 50ms while we
 // wait for the terminal to respond to our background color query. After that,
 // it will be instant.
@@ -878,6 +960,7 @@ func renderLine(row []StyledRune, width int, terminalColorCount ColorCount) (str
 	for _, cell := range row {
 		style := cell.Style
 		runeToWrite := cell.Rune
+		combining := cell.Combining()
 		if !Printable(runeToWrite) {
 			// Highlight unprintable runes
 			style = Style{
@@ -886,14 +969,30 @@ func renderLine(row []StyledRune, width int, terminalColorCount ColorCount) (str
 				attrs: AttrBold,
 			}
 			runeToWrite = '?'
+			combining = ""
 		}
 
+		passthrough := style.passthrough
+		style = style.WithPassthrough(nil)
+
 		if style != lastStyle {
 			builder.WriteString(style.RenderUpdateFrom(lastStyle, terminalColorCount))
 			lastStyle = style
 		}
 
+		if passthrough != nil {
+			// Draw the graphics with the cursor where the rune would have gone,
+			// then put the cursor back and step past the cell. Otherwise
+			// the terminal moves the cursor below the graphics and the rest of
+			// the screen ends up in the wrong place.
+			builder.WriteString("\x1b7")
+			builder.WriteString(*passthrough)
+			builder.WriteString("\x1b8\x1b[C")
+			continue
+		}
+
 		builder.WriteRune(runeToWrite)
+		builder.WriteString(combining)
 	}
 
 	lastStyleMinusHyperlink := lastStyle.WithHyperlink(nil)
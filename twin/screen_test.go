@@ -32,8 +32,8 @@ func TestConsumeEncodedEvent(t *testing.T) {
 	// Implicitly test having a remaining rune at the end
 	assertEncode(t, "\x1b[Ax", EventKeyCode{keyCode: KeyUp}, "x")
 
-	assertEncode(t, "\x1b[<64;127;41M", EventMouse{buttons: MouseWheelUp}, "")
-	assertEncode(t, "\x1b[<65;127;41M", EventMouse{buttons: MouseWheelDown}, "")
+	assertEncode(t, "\x1b[<64;127;41M", EventMouse{buttons: MouseWheelUp, x: 126, y: 40}, "")
+	assertEncode(t, "\x1b[<65;127;41M", EventMouse{buttons: MouseWheelDown, x: 126, y: 40}, "")
 
 	// This happens when users paste.
 	//
@@ -41,6 +41,32 @@ func TestConsumeEncodedEvent(t *testing.T) {
 	assertEncode(t, "1234", EventRune{rune: '1'}, "234")
 }
 
+func TestConsumeEncodedMouseEvent(t *testing.T) {
+	assertEncode(t, "\x1b[<66;1;1M", EventMouse{buttons: MouseWheelLeft, x: 0, y: 0}, "")
+	assertEncode(t, "\x1b[<67;1;1M", EventMouse{buttons: MouseWheelRight, x: 0, y: 0}, "")
+	assertEncode(t, "\x1b[<0;5;7M", EventMouse{buttons: MouseButtonPrimary, x: 4, y: 6}, "")
+	assertEncode(t, "\x1b[<1;5;7M", EventMouse{buttons: MouseButtonMiddle, x: 4, y: 6}, "")
+	assertEncode(t, "\x1b[<2;5;7M", EventMouse{buttons: MouseButtonSecondary, x: 4, y: 6}, "")
+
+	// Shift + Wheel Down
+	assertEncode(t, "\x1b[<69;5;7M", EventMouse{buttons: MouseWheelDown, x: 4, y: 6}, "")
+
+	// Two events in one read
+	assertEncode(t, "\x1b[<64;1;1M\x1b[<64;1;1M", EventMouse{buttons: MouseWheelUp, x: 0, y: 0}, "\x1b[<64;1;1M")
+}
+
+func TestConsumeIgnoredMouseEvents(t *testing.T) {
+	// Button release should be consumed without an event
+	event, remainder := consumeEncodedEvent("\x1b[<0;5;7mx")
+	assert.Assert(t, event == nil)
+	assert.Equal(t, remainder, "x")
+
+	// Same for mouse movement with the primary button held
+	event, remainder = consumeEncodedEvent("\x1b[<32;5;7Mx")
+	assert.Assert(t, event == nil)
+	assert.Equal(t, remainder, "x")
+}
+
 func TestConsumeEncodedEventWithUnsupportedEscapeCode(t *testing.T) {
 	event, remainder := consumeEncodedEvent("\x1bXXXXX")
 	assert.Assert(t, event == nil)
@@ -77,6 +103,40 @@ func TestRenderLine(t *testing.T) {
 		strings.ReplaceAll(reset+reversed+"<"+dim+notReversed+"f"+reset+clearToEol, "\x1b", "ESC"))
 }
 
+func TestRenderLineSixel(t *testing.T) {
+	sixel := "\x1bPq#0;2;100;0;0#0~~\x1b\\"
+	row := []StyledRune{
+		{Rune: 'a', Style: StyleDefault},
+		{Rune: '\uFFFC', Style: StyleDefault.WithPassthrough(&sixel)},
+		{Rune: 'b', Style: StyleDefault},
+	}
+
+	rendered, count := renderLine(row, 33, ColorCount16)
+	assert.Equal(t, count, 3)
+
+	// The sixel should be written as-is, with the cursor restored afterwards
+	reset := "\x1b[m"
+	clearToEol := "\x1b[K"
+	assert.Equal(t,
+		strings.ReplaceAll(rendered, "\x1b", "ESC"),
+		strings.ReplaceAll(reset+"a\x1b7"+sixel+"\x1b8\x1b[Cb"+clearToEol, "\x1b", "ESC"))
+}
+
+// Combining accents are written right after the rune they modify, and don't
+// count as cells of their own
+func TestRenderLineCombining(t *testing.T) {
+	row := []StyledRune{
+		NewStyledRune('e', StyleDefault).WithCombining("\u0301"),
+		{Rune: 'x', Style: StyleDefault},
+	}
+
+	rendered, count := renderLine(row, 33, ColorCount16)
+	assert.Equal(t, count, 2)
+	assert.Equal(t,
+		strings.ReplaceAll(rendered, "\x1b", "ESC"),
+		strings.ReplaceAll("\x1b[me\u0301x\x1b[K", "\x1b", "ESC"))
+}
+
 func TestRenderLineEmpty(t *testing.T) {
 	row := []StyledRune{}
 
@@ -384,3 +444,76 @@ func TestInterruptableReader_justRead(t *testing.T) {
 	assert.Equal(t, buffer[0], byte(42))
 	assert.Equal(t, len(buffer), 7)
 }
+
+func TestOsc52(t *testing.T) {
+	assert.Equal(t, osc52("hej"), "\x1b]52;c;aGVq\x07")
+	assert.Equal(t, osc52("a\nb"), "\x1b]52;c;YQpi\x07")
+
+	// File paths, for copying the current file name
+	assert.Equal(t, osc52("/tmp/a.txt"), "\x1b]52;c;L3RtcC9hLnR4dA==\x07")
+	assert.Equal(t, osc52("/tmp/åäö"), "\x1b]52;c;L3RtcC/DpcOkw7Y=\x07")
+}
+
+func TestDetectColorCount(t *testing.T) {
+	for _, testCase := range []struct {
+		colorTerm string
+		term      string
+		expected  ColorCount
+	}{
+		{"truecolor", "xterm-256color", ColorCount24bit},
+		{"24bit", "xterm-256color", ColorCount24bit},
+		{"", "xterm-direct", ColorCount24bit},
+		{"", "xterm-256color", ColorCount256},
+		{"", "screen-256color", ColorCount256},
+		{"", "linux", ColorCount16},
+		{"", "xterm-16color", ColorCount16},
+		{"", "xterm-8color", ColorCount8},
+		{"", "xterm", ColorCount24bit},
+		{"", "", ColorCount24bit},
+	} {
+		t.Setenv("COLORTERM", testCase.colorTerm)
+		t.Setenv("TERM", testCase.term)
+		assert.Equal(t, DetectColorCount(), testCase.expected, "COLORTERM=%q TERM=%q", testCase.colorTerm, testCase.term)
+	}
+}
+
+// With NO_COLOR, only attributes should make it to the terminal
+func TestRenderLineNoColors(t *testing.T) {
+	row := []StyledRune{
+		{
+			Rune:  'a',
+			Style: StyleDefault.WithForeground(NewColor16(1)).WithBackground(NewColor24Bit(0, 0, 255)),
+		},
+		{
+			Rune:  'b',
+			Style: StyleDefault.WithForeground(NewColor256(100)).WithAttr(AttrBold),
+		},
+		{
+			Rune:  ' ',
+			Style: StyleDefault.WithBackground(NewColor16(4)),
+		},
+	}
+
+	rendered, count := renderLine(row, 33, ColorCountNone)
+	assert.Equal(t, count, 2)
+	reset := "\x1b[m"
+	bold := "\x1b[1m"
+	clearToEol := "\x1b[K"
+	assert.Equal(t,
+		strings.ReplaceAll(rendered, "\x1b", "ESC"),
+		strings.ReplaceAll(reset+"a"+bold+"b"+reset+clearToEol, "\x1b", "ESC"))
+}
+
+func TestDetectColorCountNoColor(t *testing.T) {
+	t.Setenv("COLORTERM", "truecolor")
+	t.Setenv("FORCE_COLOR", "")
+
+	t.Setenv("NO_COLOR", "")
+	assert.Equal(t, DetectColorCount(), ColorCount24bit)
+
+	t.Setenv("NO_COLOR", "1")
+	assert.Equal(t, DetectColorCount(), ColorCountNone)
+
+	t.Setenv("FORCE_COLOR", "1")
+	assert.Equal(t, DetectColorCount(), ColorCount24bit)
+}
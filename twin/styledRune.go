@@ -13,6 +13,10 @@ import (
 type StyledRune struct {
 	Rune  rune
 	Style Style
+
+	// See Combining(). Most cells don't have any, so this is a pointer to keep
+	// cells small.
+	combining *string
 }
 
 func NewStyledRune(char rune, style Style) StyledRune {
@@ -22,18 +26,40 @@ func NewStyledRune(char rune, style Style) StyledRune {
 	}
 }
 
+// Any runes following Rune in the same grapheme cluster, like combining
+// accents or emoji skin tone modifiers. These don't get cells of their own,
+// they are drawn together with Rune.
+func (styledRune StyledRune) Combining() string {
+	if styledRune.combining == nil {
+		return ""
+	}
+	return *styledRune.combining
+}
+
+// A copy of this rune with combining runes set, see Combining()
+func (styledRune StyledRune) WithCombining(combining string) StyledRune {
+	if combining == "" {
+		styledRune.combining = nil
+	} else {
+		styledRune.combining = &combining
+	}
+	return styledRune
+}
+
 func (styledRune StyledRune) String() string {
-	return fmt.Sprint("rune='", string(styledRune.Rune), "' ", styledRune.Style)
+	return fmt.Sprint("rune='", string(styledRune.Rune)+styledRune.Combining(), "' ", styledRune.Style)
 }
 
 // How many screen cells will this rune cover? Most runes cover one, but some
 // like '午' will cover two.
 func (styledRune StyledRune) Width() int {
-	return uniseg.StringWidth(string(styledRune.Rune))
+	return uniseg.StringWidth(string(styledRune.Rune) + styledRune.Combining())
 }
 
 func (styledRune StyledRune) Equal(other StyledRune) bool {
-	return styledRune.Rune == other.Rune && styledRune.Style.Equal(other.Style)
+	return styledRune.Rune == other.Rune &&
+		styledRune.Combining() == other.Combining() &&
+		styledRune.Style.Equal(other.Style)
 }
 
 // Returns a slice of cells with trailing whitespace cells removed
@@ -33,6 +33,13 @@ type Style struct {
 	// * https://gist.github.com/egmontkob/eb114294efbcd5adb1944c9f3cb5feda
 	// * https://github.com/walles/moor/issues/131
 	hyperlinkURL *string
+
+	// Escape sequence to write to the terminal as-is instead of the rune
+	// with this style. Used for sixel graphics, which we can't render
+	// ourselves.
+	//
+	// Ref: https://vt100.net/docs/vt3xx-gp/chapter14.html
+	passthrough *string
 }
 
 var StyleDefault Style
@@ -51,15 +58,23 @@ func (style Style) Equal(other Style) bool {
 		return false
 	}
 
+	if !equalStringPointers(style.passthrough, other.passthrough) {
+		return false
+	}
+
 	// Now only the hyperlink is left to compare
-	if style.hyperlinkURL == nil && other.hyperlinkURL == nil {
+	return equalStringPointers(style.hyperlinkURL, other.hyperlinkURL)
+}
+
+func equalStringPointers(a *string, b *string) bool {
+	if a == nil && b == nil {
 		return true
 	}
-	if style.hyperlinkURL != nil && other.hyperlinkURL != nil {
-		return *style.hyperlinkURL == *other.hyperlinkURL
+	if a != nil && b != nil {
+		return *a == *b
 	}
 
-	// Hyperlinks have different nil-ness
+	// Different nil-ness
 	return false
 }
 
@@ -109,6 +124,7 @@ func (style Style) WithAttr(attr AttrMask) Style {
 		underlineColor: style.underlineColor,
 		attrs:          style.attrs | attr,
 		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    style.passthrough,
 	}
 
 	// Bold and dim are mutually exclusive
@@ -139,6 +155,7 @@ func (style Style) WithHyperlink(hyperlinkURL *string) Style {
 		underlineColor: style.underlineColor,
 		attrs:          style.attrs,
 		hyperlinkURL:   hyperlinkURL,
+		passthrough:    style.passthrough,
 	}
 }
 
@@ -151,6 +168,24 @@ func (style Style) HyperlinkURL() *string {
 	return style.hyperlinkURL
 }
 
+// Call with nil to go back to rendering the rune
+func (style Style) WithPassthrough(passthrough *string) Style {
+	return Style{
+		fg:             style.fg,
+		bg:             style.bg,
+		underlineColor: style.underlineColor,
+		attrs:          style.attrs,
+		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    passthrough,
+	}
+}
+
+// Passthrough returns the escape sequence to write instead of the rune, or nil
+// if the rune should be written.
+func (style Style) Passthrough() *string {
+	return style.passthrough
+}
+
 func (style Style) Foreground() Color {
 	return style.fg
 }
@@ -166,6 +201,7 @@ func (style Style) WithoutAttr(attr AttrMask) Style {
 		underlineColor: style.underlineColor,
 		attrs:          style.attrs & ^attr,
 		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    style.passthrough,
 	}
 }
 
@@ -180,6 +216,7 @@ func (style Style) WithBackground(color Color) Style {
 		underlineColor: style.underlineColor,
 		attrs:          style.attrs,
 		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    style.passthrough,
 	}
 }
 
@@ -190,6 +227,7 @@ func (style Style) WithForeground(color Color) Style {
 		underlineColor: style.underlineColor,
 		attrs:          style.attrs,
 		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    style.passthrough,
 	}
 }
 
@@ -200,14 +238,34 @@ func (style Style) WithUnderlineColor(color Color) Style {
 		underlineColor: color,
 		attrs:          style.attrs,
 		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    style.passthrough,
+	}
+}
+
+// Remove all colors, keeping attributes and any hyperlink
+func (style Style) WithoutColors() Style {
+	return Style{
+		fg:             ColorDefault,
+		bg:             ColorDefault,
+		underlineColor: ColorDefault,
+		attrs:          style.attrs,
+		hyperlinkURL:   style.hyperlinkURL,
+		passthrough:    style.passthrough,
 	}
 }
 
 // Emit an ANSI escape sequence switching from a previous style to the current
 // one.
 //
+// With a terminalColorCount of ColorCountNone, only attributes are emitted.
+//
 //revive:disable-next-line:receiver-naming
 func (style Style) RenderUpdateFrom(previous Style, terminalColorCount ColorCount) string {
+	if terminalColorCount == ColorCountNone {
+		style = style.WithoutColors()
+		previous = previous.WithoutColors()
+	}
+
 	if style == previous {
 		// Shortcut for the common case
 		return ""